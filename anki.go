@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// writeAnki writes items as a tab-separated file for Anki's "Import File" dialog.
+// The front of each card is the puzzle and the back is the solution worked step by step.
+func writeAnki(w io.Writer, items []solvedPuzzle, p printer) error {
+	var b strings.Builder
+	b.WriteString("#separator:tab\n")
+	b.WriteString("#html:true\n")
+	b.WriteString("#columns:Front\tBack\n")
+	for _, item := range items {
+		front := fmt.Sprintf("Make <b>%d</b> from %s", item.Puzzle.Target, html.EscapeString(strings.ReplaceAll(formatDigits(item.Puzzle.Digits), ",", ", ")))
+
+		lines := []string{fmt.Sprintf("<b>%s</b>", html.EscapeString(p.format(item.Solution)))}
+		for _, s := range item.Solution.steps() {
+			lines = append(lines, html.EscapeString(s.format(p.symbols)))
+		}
+		back := strings.Join(lines, "<br>")
+
+		fmt.Fprintf(&b, "%s\t%s\n", front, back)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
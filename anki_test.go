@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_writeAnki(t *testing.T) {
+	items := []solvedPuzzle{
+		{
+			Puzzle:   puzzle{Digits: []int{5, 7, 9, 10, 15, 25}, Target: 93},
+			Solution: makeAdd(makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25)), makeConstant(5)).fuse(),
+		},
+		{
+			Puzzle:   puzzle{Digits: []int{3, 4, 100}, Target: 96},
+			Solution: makeAdd(makeConstant(100), makeNegate(makeConstant(4))),
+		},
+	}
+
+	var b strings.Builder
+	if err := writeAnki(&b, items, newPrinter(notationInfix, true)); err != nil {
+		t.Fatalf("writeAnki() failed unexpectedly: %v", err)
+	}
+
+	want := "#separator:tab\n" +
+		"#html:true\n" +
+		"#columns:Front\tBack\n" +
+		"Make <b>93</b> from 5, 7, 9, 10, 15, 25\t<b>9×7 + 25 + 5</b><br>9 × 7 = 63<br>63 + 25 = 88<br>88 + 5 = 93\n" +
+		"Make <b>96</b> from 3, 4, 100\t<b>100 − 4</b><br>100 − 4 = 96\n"
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("writeAnki() mismatch (-want +got):\n%s", diff)
+	}
+}
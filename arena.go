@@ -0,0 +1,100 @@
+package main
+
+// arenaBlockSize is how many nodes, or operand pointers, an exprArena allocates at once.
+const arenaBlockSize = 1024
+
+// exprArena allocates a search's expression nodes in blocks rather than one at a time, and reuses
+// the nodes of candidate solutions which are thrown away, cutting the garbage collector's work.
+// It isn't safe for concurrent use, so each goroutine of a search has its own.
+// A nil arena allocates everything on the heap.
+type exprArena struct {
+	nodes    []expression
+	operands []*expression
+	// free holds released nodes, and freeOperands released operand lists by their length.
+	free         []*expression
+	freeOperands [maxDigitCount + 1][][]*expression
+}
+
+// node returns a node holding a copy of e.
+func (a *exprArena) node(e expression) *expression {
+	if a == nil {
+		return &e
+	}
+	var n *expression
+	if k := len(a.free); k > 0 {
+		n = a.free[k-1]
+		a.free = a.free[:k-1]
+	} else {
+		if len(a.nodes) == 0 {
+			a.nodes = make([]expression, arenaBlockSize)
+		}
+		n = &a.nodes[0]
+		a.nodes = a.nodes[1:]
+	}
+	*n = e
+	return n
+}
+
+// children returns a list for n operands.
+func (a *exprArena) children(n int) []*expression {
+	if a == nil {
+		return make([]*expression, n)
+	}
+	if n < len(a.freeOperands) {
+		if free := a.freeOperands[n]; len(free) > 0 {
+			c := free[len(free)-1]
+			a.freeOperands[n] = free[:len(free)-1]
+			return c
+		}
+	}
+	if len(a.operands) < n {
+		a.operands = make([]*expression, max(arenaBlockSize, n))
+	}
+	// Cap the list so appending to it can't overwrite the next one.
+	c := a.operands[:n:n]
+	a.operands = a.operands[n:]
+	return c
+}
+
+// release hands back the nodes made for a candidate which was thrown away: its operand list and
+// the nodes it was built from, and the list made by fusing it, if that's different.
+// The operands' own children may be shared with other solutions, so they're left alone.
+func (a *exprArena) release(built, fused expression) {
+	if a == nil || len(built.Children) == 0 {
+		return
+	}
+	if n := len(fused.Children); n > 0 && &fused.Children[0] != &built.Children[0] && n < len(a.freeOperands) {
+		a.freeOperands[n] = append(a.freeOperands[n], fused.Children)
+	}
+	a.free = append(a.free, built.Children...)
+	if n := len(built.Children); n < len(a.freeOperands) {
+		a.freeOperands[n] = append(a.freeOperands[n], built.Children)
+	}
+}
+
+func (a *exprArena) makeNegate(x expression) expression {
+	c := a.children(1)
+	c[0] = a.node(x)
+	return expression{Val: -x.Val, Op: opNegate, Children: c}
+}
+
+func (a *exprArena) makeBinary(op operation, val int, x, y expression) expression {
+	c := a.children(2)
+	c[0], c[1] = a.node(x), a.node(y)
+	return expression{Val: val, Op: op, Children: c}
+}
+
+func (a *exprArena) makeAdd(x, y expression) expression {
+	return a.makeBinary(opAdd, x.Val+y.Val, x, y)
+}
+
+func (a *exprArena) makeMultiply(x, y expression) expression {
+	return a.makeBinary(opMultiply, x.Val*y.Val, x, y)
+}
+
+func (a *exprArena) makeDivide(x, y expression) expression {
+	if y.Val == 0 {
+		panic("denominator is zero")
+	}
+	return a.makeBinary(opDivide, x.Val/y.Val, x, y)
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func Test_exprArena_release(t *testing.T) {
+	a := &exprArena{}
+	x := a.makeMultiply(makeConstant(7), makeConstant(9))
+	built := a.makeAdd(x, makeConstant(5))
+	operands, first, second := &built.Children[0], built.Children[0], built.Children[1]
+	a.release(built, built.fuseWith(a))
+
+	// The released nodes and operand list are the next ones handed out.
+	reused := a.makeAdd(makeConstant(25), makeConstant(10))
+	if reused.Children[0] != second || reused.Children[1] != first {
+		t.Errorf("makeAdd() after release didn't reuse the released nodes")
+	}
+	if &reused.Children[0] != operands {
+		t.Errorf("makeAdd() after release didn't reuse the released operand list")
+	}
+	if got, want := reused.String(), "(25 + 10)"; got != want {
+		t.Errorf("makeAdd() after release = %s, want %s", got, want)
+	}
+	// Nodes which weren't made for the candidate, like x's operands, are left alone.
+	if got, want := x.String(), "(7 * 9)"; got != want {
+		t.Errorf("release() changed an operand to %s, want %s", got, want)
+	}
+}
+
+func Test_exprArena_children(t *testing.T) {
+	a := &exprArena{}
+	first := a.children(2)
+	second := a.children(2)
+	first = append(first, &expression{Val: 1})
+	second[0] = &expression{Val: 2}
+	if first[2].Val != 1 || second[0].Val != 2 {
+		t.Errorf("appending to one operand list changed another")
+	}
+	var nilArena *exprArena
+	if got := len(nilArena.children(3)); got != 3 {
+		t.Errorf("children(3) on a nil arena has length %d, want 3", got)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// atomicFile is written to a temporary file alongside its destination and only renamed
+// into place by Commit, so readers never see a partially written file.
+type atomicFile struct {
+	*os.File
+	path string
+}
+
+func createAtomic(path string) (*atomicFile, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	return &atomicFile{File: f, path: path}, nil
+}
+
+// Commit flushes the file to disk and moves it to its destination.
+func (f *atomicFile) Commit() error {
+	if err := f.Sync(); err != nil {
+		f.Abort()
+		return err
+	}
+	if err := f.Chmod(0o644); err != nil {
+		f.Abort()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), f.path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// Abort discards the file, leaving any existing file at the destination untouched.
+func (f *atomicFile) Abort() {
+	f.Close()
+	os.Remove(f.Name())
+}
+
+// writeFileAtomic is like os.WriteFile but replaces path atomically.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := createAtomic(path)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Abort()
+		return err
+	}
+	return f.Commit()
+}
+
+// openOutput returns where results should be written: stdout, or path if it's set.
+// The returned function must be called once all results have been written.
+func openOutput(path string) (io.Writer, func() error, error) {
+	if path == "" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+	f, err := createAtomic(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Commit, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_atomicFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatalf("WriteFile() failed unexpectedly: %v", err)
+	}
+
+	f, err := createAtomic(path)
+	if err != nil {
+		t.Fatalf("createAtomic() failed unexpectedly: %v", err)
+	}
+	if _, err := f.WriteString("new"); err != nil {
+		t.Fatalf("WriteString() failed unexpectedly: %v", err)
+	}
+
+	// Until Commit, the destination keeps its old contents.
+	if got, _ := os.ReadFile(path); string(got) != "old" {
+		t.Errorf("before Commit() contents = %q, want %q", got, "old")
+	}
+	if err := f.Commit(); err != nil {
+		t.Fatalf("Commit() failed unexpectedly: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "new" {
+		t.Errorf("after Commit() contents = %q, want %q", got, "new")
+	}
+
+	// Aborted writes leave the destination alone and clean up after themselves.
+	f, err = createAtomic(path)
+	if err != nil {
+		t.Fatalf("createAtomic() failed unexpectedly: %v", err)
+	}
+	f.WriteString("aborted")
+	f.Abort()
+	if got, _ := os.ReadFile(path); string(got) != "new" {
+		t.Errorf("after Abort() contents = %q, want %q", got, "new")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() failed unexpectedly: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("ReadDir() = %d entries, want 1 (temporary files left behind)", len(entries))
+	}
+}
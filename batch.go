@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// parsePuzzle parses a puzzle written as "digits:target", e.g. "5,7,9,10,15,25:93".
+// The digits may be separated by commas or spaces.
+func parsePuzzle(s string) (puzzle, error) {
+	digitsStr, targetStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return puzzle{}, fmt.Errorf("want digits:target, got %q", s)
+	}
+
+	fields := strings.FieldsFunc(digitsStr, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	if len(fields) == 0 {
+		return puzzle{}, fmt.Errorf("no digits in %q", s)
+	}
+	digits, err := parseDigits(strings.Join(fields, ","))
+	if err != nil {
+		return puzzle{}, err
+	}
+
+	target, err := strconv.Atoi(strings.TrimSpace(targetStr))
+	if err != nil {
+		return puzzle{}, fmt.Errorf("invalid target %q", strings.TrimSpace(targetStr))
+	}
+	p := puzzle{Digits: digits, Target: target}
+	return p, p.validate()
+}
+
+// puzzleRow is a puzzle read from a batch input, or the error found reading that row.
+type puzzleRow struct {
+	// Row is the 1-based line of a text or CSV input, or the index of a JSON array element.
+	Row    int
+	Puzzle puzzle
+	Err    error
+}
+
+// batchFormat is the format of a batch input.
+type batchFormat int
+
+const (
+	// batchLines is one "digits:target" puzzle per line.
+	batchLines batchFormat = iota
+	// batchCSV is CSV with digits and target columns, and an optional header.
+	batchCSV
+	// batchJSON is a JSON array of {"digits": [...], "target": n} objects.
+	batchJSON
+)
+
+// batchFormatForPath picks the input format from the file extension.
+func batchFormatForPath(path string) batchFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return batchCSV
+	case ".json":
+		return batchJSON
+	}
+	return batchLines
+}
+
+// readPuzzles calls fn with each row read from r.
+// Malformed rows are passed to fn with Err set so the rest of the batch can continue;
+// an error is only returned if the input can't be read any further.
+func readPuzzles(r io.Reader, format batchFormat, fn func(puzzleRow) error) error {
+	switch format {
+	case batchCSV:
+		return readPuzzleCSV(r, fn)
+	case batchJSON:
+		return readPuzzleJSON(r, fn)
+	}
+	return readPuzzleLines(r, fn)
+}
+
+// readPuzzleLines reads one puzzle per line. Blank lines and lines starting with '#' are skipped.
+func readPuzzleLines(r io.Reader, fn func(puzzleRow) error) error {
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		p, err := parsePuzzle(s)
+		if err := fn(puzzleRow{Row: line, Puzzle: p, Err: err}); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+func readPuzzleCSV(r io.Reader, fn func(puzzleRow) error) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			if err := fn(puzzleRow{Row: parseErr.Line, Err: parseErr.Err}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		line, _ := cr.FieldPos(0)
+		if first && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "digits") {
+			continue
+		}
+		row := puzzleRow{Row: line}
+		if len(record) != 2 {
+			row.Err = fmt.Errorf("want 2 columns (digits, target), got %d", len(record))
+		} else {
+			row.Puzzle, row.Err = parsePuzzle(record[0] + ":" + record[1])
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+func readPuzzleJSON(r io.Reader, fn func(puzzleRow) error) error {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return fmt.Errorf("want a JSON array of puzzles: %v", err)
+	}
+	for i, item := range items {
+		var v struct {
+			Digits []int `json:"digits"`
+			Target int   `json:"target"`
+		}
+		row := puzzleRow{Row: i + 1}
+		if err := json.Unmarshal(item, &v); err != nil {
+			row.Err = err
+		} else {
+			row.Puzzle = puzzle{Digits: v.Digits, Target: v.Target}
+			row.Err = row.Puzzle.validate()
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batchResult is the result for one puzzle in a batch, written as a line of JSON.
+type batchResult struct {
+	Row    int    `json:"row"`
+	Error  string `json:"error,omitempty"`
+	Digits []int  `json:"digits,omitempty"`
+	Target int    `json:"target,omitempty"`
+	Solved bool   `json:"solved"`
+	Count  int    `json:"count"`
+	// Generated is how many expressions the search built before removing duplicates, if it's known.
+	Generated int64 `json:"generated,omitempty"`
+	// Shortest, Easiest and Cleverest are the solutions picked by shortest, easiest and cleverest, each with its score.
+	Shortest       string         `json:"shortest,omitempty"`
+	ShortestScore  *solutionScore `json:"shortest_score,omitempty"`
+	Easiest        string         `json:"easiest,omitempty"`
+	EasiestScore   *solutionScore `json:"easiest_score,omitempty"`
+	Cleverest      string         `json:"cleverest,omitempty"`
+	CleverestScore *solutionScore `json:"cleverest_score,omitempty"`
+	// Incomplete reports whether the search timed out, so there may be more solutions.
+	Incomplete bool `json:"incomplete,omitempty"`
+}
+
+func makeBatchResult(row int, pz puzzle, solns []expression, p printer) batchResult {
+	r := batchResult{
+		Row:    row,
+		Digits: pz.Digits,
+		Target: pz.Target,
+		Solved: len(solns) > 0,
+		Count:  len(solns),
+	}
+	if s, err := shortest(solns); err == nil {
+		sc := s.score()
+		r.Shortest, r.ShortestScore = p.format(s), &sc
+	}
+	if s, err := easiest(solns); err == nil {
+		sc := s.score()
+		r.Easiest, r.EasiestScore = p.format(s), &sc
+	}
+	if s, err := cleverest(solns); err == nil {
+		sc := s.score()
+		r.Cleverest, r.CleverestScore = p.format(s), &sc
+	}
+	return r
+}
+
+// writeBatchResult writes r as a single line of text or JSON.
+func writeBatchResult(w io.Writer, r batchResult, asJSON bool) error {
+	if asJSON {
+		return json.NewEncoder(w).Encode(r)
+	}
+
+	if r.Error != "" {
+		_, err := fmt.Fprintf(w, "row %d: error: %s\n", r.Row, r.Error)
+		return err
+	}
+	pz := puzzle{Digits: r.Digits, Target: r.Target}
+	note := ""
+	if r.Incomplete {
+		note = " (timed out, may be incomplete)"
+	}
+	if !r.Solved {
+		_, err := fmt.Fprintf(w, "%s: no solution%s\n", pz, note)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%s: %d solutions%s, shortest %s\n", pz, r.Count, note, r.Shortest)
+	return err
+}
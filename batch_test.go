@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parsePuzzle(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		want    puzzle
+		wantErr bool
+	}{
+		"commas": {
+			input: "5,7,9,10,15,25:93",
+			want:  puzzle{Digits: []int{5, 7, 9, 10, 15, 25}, Target: 93},
+		},
+		"spaces": {
+			input: "5 7 9 : 93",
+			want:  puzzle{Digits: []int{5, 7, 9}, Target: 93},
+		},
+		"no target": {
+			input:   "5,7,9",
+			wantErr: true,
+		},
+		"no digits": {
+			input:   ":93",
+			wantErr: true,
+		},
+		"bad target": {
+			input:   "5,7:x",
+			wantErr: true,
+		},
+		"negative target": {
+			input:   "5,7:-2",
+			wantErr: true,
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got, err := parsePuzzle(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parsePuzzle(%q) = %v, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePuzzle(%q) failed unexpectedly: %v", tt.input, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("parsePuzzle(%q) mismatch (-want +got):\n%s", tt.input, diff)
+			}
+		})
+	}
+}
+
+func Test_solveBatch(t *testing.T) {
+	input := "# digits:target\n5,7,9,10,15,25:93\n\n5,7:3\n"
+	p := newPrinter(notationInfix, false)
+
+	tests := map[string]struct {
+		asJSON bool
+		want   string
+	}{
+		"text": {
+			want: "make 93 from 5,7,9,10,15,25: 55 solutions, shortest 9*7 + 25 + 5\n" +
+				"make 3 from 5,7: no solution\n",
+		},
+		"json": {
+			asJSON: true,
+			want: `{"row":2,"digits":[5,7,9,10,15,25],"target":93,"solved":true,"count":55,"shortest":"9*7 + 25 + 5","shortest_score":{"difficulty":15,"cleverness":-3,"roundness":-2},` +
+				`"easiest":"9*7 + 25 + 5","easiest_score":{"difficulty":15,"cleverness":-3,"roundness":-2},` +
+				`"cleverest":"10*(25*9/15 - 5) - 7","cleverest_score":{"difficulty":32,"cleverness":7,"roundness":5}}` + "\n" +
+				`{"row":4,"digits":[5,7],"target":3,"solved":false,"count":0}` + "\n",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			var b bytes.Buffer
+			err := readPuzzles(strings.NewReader(input), batchLines, func(row puzzleRow) error {
+				if row.Err != nil {
+					t.Fatalf("row %d: unexpected error: %v", row.Row, row.Err)
+				}
+				pz := row.Puzzle
+				return writeBatchResult(&b, makeBatchResult(row.Row, pz, solve(pz.Target, pz.Digits), p), tt.asJSON)
+			})
+			if err != nil {
+				t.Fatalf("readPuzzles() failed unexpectedly: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, b.String()); diff != "" {
+				t.Errorf("batch output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_readPuzzles(t *testing.T) {
+	type result struct {
+		Row    int
+		Puzzle puzzle
+		Err    bool
+	}
+	tests := map[string]struct {
+		format batchFormat
+		input  string
+		want   []result
+	}{
+		"lines": {
+			format: batchLines,
+			input:  "5,7:12\n5,7\n\n1,2:3\n",
+			want: []result{
+				{Row: 1, Puzzle: puzzle{Digits: []int{5, 7}, Target: 12}},
+				{Row: 2, Err: true},
+				{Row: 4, Puzzle: puzzle{Digits: []int{1, 2}, Target: 3}},
+			},
+		},
+		"csv": {
+			format: batchCSV,
+			input:  "digits,target\n\"5,7\",12\n5 7,x\n1 2,3\n9\n",
+			want: []result{
+				{Row: 2, Puzzle: puzzle{Digits: []int{5, 7}, Target: 12}},
+				{Row: 3, Err: true},
+				{Row: 4, Puzzle: puzzle{Digits: []int{1, 2}, Target: 3}},
+				{Row: 5, Err: true},
+			},
+		},
+		"json": {
+			format: batchJSON,
+			input:  `[{"digits": [5, 7], "target": 12}, {"digits": "5,7"}, {"digits": [1, 2], "target": 0}, {"digits": [1, 2], "target": 3}]`,
+			want: []result{
+				{Row: 1, Puzzle: puzzle{Digits: []int{5, 7}, Target: 12}},
+				{Row: 2, Err: true},
+				{Row: 3, Err: true},
+				{Row: 4, Puzzle: puzzle{Digits: []int{1, 2}, Target: 3}},
+			},
+		},
+		"json too many digits": {
+			format: batchJSON,
+			input:  `[{"digits": [` + strings.Repeat("2, ", 64) + `2], "target": 5}, {"digits": [1, 2], "target": 3}]`,
+			want: []result{
+				{Row: 1, Err: true},
+				{Row: 2, Puzzle: puzzle{Digits: []int{1, 2}, Target: 3}},
+			},
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			var got []result
+			err := readPuzzles(strings.NewReader(tt.input), tt.format, func(row puzzleRow) error {
+				r := result{Row: row.Row, Err: row.Err != nil}
+				if row.Err == nil {
+					r.Puzzle = row.Puzzle
+				}
+				got = append(got, r)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("readPuzzles() failed unexpectedly: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("readPuzzles() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_readPuzzles_invalidJSON(t *testing.T) {
+	err := readPuzzles(strings.NewReader(`{"digits": [5, 7]}`), batchJSON, func(puzzleRow) error { return nil })
+	if err == nil {
+		t.Errorf("readPuzzles() succeeded unexpectedly")
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"runtime"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+// benchWorkload is one of the workloads digits bench times.
+type benchWorkload struct {
+	name string
+	// run does the work once, counting the nodes it searches in nodes.
+	run func(ctx context.Context, nodes *atomic.Int64)
+}
+
+// benchSolve returns a workload which finds every solution for target from digits.
+func benchSolve(name string, target int, digits ...int) benchWorkload {
+	return benchWorkload{name, func(ctx context.Context, nodes *atomic.Int64) {
+		solver{ctx: ctx, nodes: nodes}.solve(target, digits)
+	}}
+}
+
+// benchRange returns a workload which finds every solution for each target from..to, sharing sub-searches like digits range.
+// It's run on one goroutine, so its results don't depend on the machine's cores.
+func benchRange(name string, from, to int, digits ...int) benchWorkload {
+	return benchWorkload{name, func(ctx context.Context, nodes *atomic.Int64) {
+		s := solver{ctx: ctx, nodes: nodes, cache: newSearchCache(digits)}
+		for target := from; target <= to; target++ {
+			s.solve(target, digits)
+		}
+	}}
+}
+
+// benchSuite is the standard set of workloads. Their names and work must stay the same from one version
+// to the next, so results can be compared; add new workloads rather than changing these.
+var benchSuite = []benchWorkload{
+	benchSolve("solve/4", 24, 1, 3, 4, 6),
+	benchSolve("solve/5", 371, 2, 3, 7, 25, 50),
+	benchSolve("solve/6", 952, 3, 6, 25, 50, 75, 100),
+	benchSolve("solve/7", 999, 1, 2, 3, 5, 7, 25, 100),
+	benchRange("range/6", 100, 999, 1, 3, 5, 7, 25, 100),
+}
+
+// benchResult is how a workload performed, taking the fastest of its runs.
+type benchResult struct {
+	Name  string        `json:"name"`
+	Runs  int           `json:"runs"`
+	Wall  time.Duration `json:"wall_ns"`
+	Nodes int64         `json:"nodes"`
+	// Allocs and Bytes are the heap allocations of a run.
+	Allocs uint64 `json:"allocs"`
+	Bytes  uint64 `json:"bytes"`
+}
+
+// runBenchmarks runs each workload whose name matches match count times, and returns how they performed.
+// It stops early, returning the workloads finished, if ctx is done.
+func runBenchmarks(ctx context.Context, workloads []benchWorkload, match *regexp.Regexp, count int) []benchResult {
+	var results []benchResult
+	for _, w := range workloads {
+		if match != nil && !match.MatchString(w.name) {
+			continue
+		}
+		r := benchResult{Name: w.name}
+		for i := 0; i < count; i++ {
+			var nodes atomic.Int64
+			var before, after runtime.MemStats
+			// Collect garbage first, so one run's garbage isn't collected during the next.
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			start := time.Now()
+			w.run(ctx, &nodes)
+			wall := time.Since(start)
+			runtime.ReadMemStats(&after)
+			if ctx.Err() != nil {
+				return results
+			}
+			if r.Runs == 0 || wall < r.Wall {
+				r.Wall = wall
+			}
+			r.Runs++
+			r.Nodes = nodes.Load()
+			r.Allocs = after.Mallocs - before.Mallocs
+			r.Bytes = after.TotalAlloc - before.TotalAlloc
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// writeBenchResults writes results as a table, in the order they were run.
+func writeBenchResults(w io.Writer, results []benchResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "workload\truns\twall\tnodes\tallocs\tbytes\t\n")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t%d\t%d\t\n", r.Name, r.Runs, r.Wall.Round(time.Microsecond), r.Nodes, r.Allocs, r.Bytes)
+	}
+	return tw.Flush()
+}
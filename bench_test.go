@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_runBenchmarks(t *testing.T) {
+	workloads := []benchWorkload{
+		benchSolve("solve/3", 7, 2, 5, 3),
+		benchRange("range/3", 1, 10, 2, 5, 3),
+		{"other", func(ctx context.Context, nodes *atomic.Int64) { t.Error("ran a workload which doesn't match") }},
+	}
+	got := runBenchmarks(context.Background(), workloads, regexp.MustCompile("/3$"), 2)
+	var names []string
+	for _, r := range got {
+		names = append(names, r.Name)
+		if r.Runs != 2 || r.Wall <= 0 || r.Nodes == 0 {
+			t.Errorf("runBenchmarks() %s = %+v, want 2 runs which searched some nodes", r.Name, r)
+		}
+	}
+	if diff := cmp.Diff([]string{"solve/3", "range/3"}, names); diff != "" {
+		t.Errorf("runBenchmarks() workloads mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_writeBenchResults(t *testing.T) {
+	results := []benchResult{
+		{Name: "solve/4", Runs: 3, Wall: 1500 * time.Microsecond, Nodes: 76, Allocs: 116, Bytes: 61200},
+		{Name: "range/6", Runs: 3, Wall: 2 * time.Second, Nodes: 91820, Allocs: 306932, Bytes: 86258416},
+	}
+	var b bytes.Buffer
+	if err := writeBenchResults(&b, results); err != nil {
+		t.Fatalf("writeBenchResults() failed unexpectedly: %v", err)
+	}
+	want := strings.Join([]string{
+		"  workload  runs   wall  nodes  allocs     bytes",
+		"   solve/4     3  1.5ms     76     116     61200",
+		"   range/6     3     2s  91820  306932  86258416",
+		"",
+	}, "\n")
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("writeBenchResults() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// errBudgetExhausted is returned by work which gave up because its node budget ran out.
+var errBudgetExhausted = errors.New("node budget exhausted")
+
+// nodeBudget bounds how many sub-problems a search tries, so a search of many digits gives up
+// rather than running on. It's safe for concurrent use, so a parallel search shares one.
+// A nil budget is unlimited.
+type nodeBudget struct {
+	max  int64
+	used atomic.Int64
+}
+
+// newNodeBudget returns a budget of max nodes, or nil for an unlimited one if max isn't positive.
+func newNodeBudget(max int64) *nodeBudget {
+	if max <= 0 {
+		return nil
+	}
+	return &nodeBudget{max: max}
+}
+
+// spend uses up one node.
+func (b *nodeBudget) spend() {
+	if b != nil {
+		b.used.Add(1)
+	}
+}
+
+// exhausted reports whether every node has been used up.
+func (b *nodeBudget) exhausted() bool {
+	return b != nil && b.used.Load() >= b.max
+}
+
+// stopReason returns why work bounded by ctx and budget should stop: ctx's error, errBudgetExhausted,
+// or nil if it can go on.
+func stopReason(ctx context.Context, budget *nodeBudget) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if budget.exhausted() {
+		return errBudgetExhausted
+	}
+	return nil
+}
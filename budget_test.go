@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+)
+
+func Test_solver_budget(t *testing.T) {
+	digits := []int{5, 7, 9, 10, 15, 25}
+	tests := map[string]struct {
+		maxNodes       int64
+		wantIncomplete bool
+	}{
+		"unlimited":  {maxNodes: 0},
+		"enough":     {maxNodes: 1_000_000},
+		"too little": {maxNodes: 10, wantIncomplete: true},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			s := solver{budget: newNodeBudget(tt.maxNodes)}
+			solns, incomplete := s.solveWithin(0, 93, digits)
+			if incomplete != tt.wantIncomplete || s.budget.exhausted() != tt.wantIncomplete {
+				t.Errorf("solveWithin() incomplete = %t, budget exhausted = %t; want %t", incomplete, s.budget.exhausted(), tt.wantIncomplete)
+			}
+			if want := 55; !tt.wantIncomplete && len(solns) != want {
+				t.Errorf("solveWithin() = %d solutions, want %d", len(solns), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// checkpointOptionsVersion starts every checkpoint's Options, and changes with how they're written,
+// so a checkpoint saved by an older version isn't mistaken for one with the same options.
+const checkpointOptionsVersion = "v3"
+
+// rangeOptions describes the flags of a range run which change its results, for its checkpoint's Options.
+// The engine matters, as some find only one solution per target, and so does the node budget, which
+// decides where the searches stop. The timeout doesn't: where it cuts a target short, the target's
+// result is marked incomplete.
+func rangeOptions(engine string, filter solutionFilter, maxResults int, maxNodes int64, sort string) string {
+	return fmt.Sprintf("%s engine=%s %s max_results=%d max_nodes=%d sort=%s",
+		checkpointOptionsVersion, engine, filter.key(), maxResults, maxNodes, sort)
+}
+
+// rangeCheckpoint is the saved state of a range run, so it can be resumed after a crash or interruption.
+type rangeCheckpoint struct {
+	Digits []int `json:"digits"`
+	Min    int   `json:"min"`
+	Max    int   `json:"max"`
+	// Options describes the flags which affect the results, so a run isn't resumed with different ones.
+	Options string `json:"options"`
+	// Shard and Shards say which of the range's shards the results are for, if it was split with --shard.
+	Shard   int                `json:"shard,omitempty"`
+	Shards  int                `json:"shards,omitempty"`
+	Results []checkpointResult `json:"results"`
+}
+
+// checkpointResult is a finished target, with its solutions written as S-expressions.
+type checkpointResult struct {
+	Target     int      `json:"target"`
+	Solutions  []string `json:"solutions"`
+	Incomplete bool     `json:"incomplete,omitempty"`
+	Exhausted  bool     `json:"exhausted,omitempty"`
+}
+
+func makeCheckpointResult(r targetResult) checkpointResult {
+	c := checkpointResult{Target: r.Target, Solutions: []string{}, Incomplete: r.Incomplete, Exhausted: r.Exhausted}
+	for _, s := range r.Solutions {
+		c.Solutions = append(c.Solutions, formatSExpr(s, opStrings))
+	}
+	return c
+}
+
+func (c checkpointResult) targetResult() (targetResult, error) {
+	r := targetResult{Target: c.Target, Incomplete: c.Incomplete, Exhausted: c.Exhausted}
+	for _, s := range c.Solutions {
+		e, err := parseSExpr(s)
+		if err != nil {
+			return targetResult{}, fmt.Errorf("target %d: %v", c.Target, err)
+		}
+		if e.Val != c.Target {
+			return targetResult{}, fmt.Errorf("target %d: %s = %d", c.Target, s, e.Val)
+		}
+		r.Solutions = append(r.Solutions, e)
+	}
+	return r, nil
+}
+
+// matches reports an error if c was saved by a run with different digits, targets or options.
+func (c rangeCheckpoint) matches(want rangeCheckpoint) error {
+	if fmt.Sprint(c.Digits) != fmt.Sprint(want.Digits) {
+		return fmt.Errorf("checkpoint is for digits %v, not %v", c.Digits, want.Digits)
+	}
+	if c.Min != want.Min || c.Max != want.Max {
+		return fmt.Errorf("checkpoint is for targets %d-%d, not %d-%d", c.Min, c.Max, want.Min, want.Max)
+	}
+	if c.Options != want.Options {
+		return fmt.Errorf("checkpoint is for options %q, not %q", c.Options, want.Options)
+	}
+	if c.Shards != want.Shards {
+		return fmt.Errorf("checkpoint is for %d shards, not %d", c.Shards, want.Shards)
+	}
+	return nil
+}
+
+// results returns the finished targets saved in c.
+func (c rangeCheckpoint) results() ([]targetResult, error) {
+	var out []targetResult
+	for _, cr := range c.Results {
+		r, err := cr.targetResult()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func readCheckpoint(path string) (rangeCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rangeCheckpoint{}, err
+	}
+	var c rangeCheckpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return rangeCheckpoint{}, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// writeCheckpoint saves c to path, replacing any previous checkpoint atomically so a crash mid-write can't corrupt it.
+func writeCheckpoint(path string, c rangeCheckpoint) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
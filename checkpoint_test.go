@@ -0,0 +1,58 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_checkpoint(t *testing.T) {
+	digits := []int{5, 7, 9, 10, 15, 25}
+	var results []targetResult
+	for target := 91; target <= 93; target++ {
+		results = append(results, targetResult{Target: target, Solutions: solve(target, digits)})
+	}
+	results[1].Incomplete = true
+
+	c := rangeCheckpoint{Digits: digits, Min: 91, Max: 100, Options: "max_results=0"}
+	for _, r := range results {
+		c.Results = append(c.Results, makeCheckpointResult(r))
+	}
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := writeCheckpoint(path, c); err != nil {
+		t.Fatalf("writeCheckpoint() failed unexpectedly: %v", err)
+	}
+	got, err := readCheckpoint(path)
+	if err != nil {
+		t.Fatalf("readCheckpoint() failed unexpectedly: %v", err)
+	}
+	if err := got.matches(rangeCheckpoint{Digits: digits, Min: 91, Max: 100, Options: "max_results=0"}); err != nil {
+		t.Errorf("matches() failed unexpectedly: %v", err)
+	}
+	if err := got.matches(rangeCheckpoint{Digits: digits, Min: 91, Max: 100, Options: "max_results=5"}); err == nil {
+		t.Errorf("matches() with different options succeeded unexpectedly")
+	}
+
+	gotResults, err := got.results()
+	if err != nil {
+		t.Fatalf("results() failed unexpectedly: %v", err)
+	}
+	if len(gotResults) != len(results) {
+		t.Fatalf("results() got %d results, want %d", len(gotResults), len(results))
+	}
+	for i, r := range gotResults {
+		if r.Target != results[i].Target || r.Incomplete != results[i].Incomplete {
+			t.Errorf("results()[%d] = target %d, incomplete %t; want %d, %t", i, r.Target, r.Incomplete, results[i].Target, results[i].Incomplete)
+		}
+		var gotStrs, wantStrs []string
+		for j := range r.Solutions {
+			gotStrs = append(gotStrs, r.Solutions[j].String())
+			wantStrs = append(wantStrs, results[i].Solutions[j].String())
+		}
+		if diff := cmp.Diff(wantStrs, gotStrs); diff != "" {
+			t.Errorf("results()[%d] solutions mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+// analyses are the analyses digits analyze can run, each with flags of its own.
+var analyses = map[string]func(ctx context.Context, args []string) int{
+	"coverage": runAnalyzeCoverage,
+	"compare":  runAnalyzeCompare,
+	"unique":   runAnalyzeUnique,
+}
+
+func runAnalyze(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		usagef("Want an analysis to run, coverage, compare or unique, e.g. digits analyze coverage --digits=1,3,5,10,25,50")
+	}
+	run, ok := analyses[args[0]]
+	if !ok {
+		usagef("Unknown analysis %q, want coverage, compare or unique", args[0])
+	}
+	return run(ctx, args[1:])
+}
+
+func runAnalyzeCoverage(ctx context.Context, args []string) int {
+	fs := newFlagSet("analyze coverage")
+	digitsStr := fs.String("digits", "", "A comma-separated list of digits; they may also be given as arguments")
+	targetRange := fs.String("target_range", "1,1000", "The targets to analyze (inclusive), e.g. 100,999")
+	engineStr := fs.String("engine", "search", "Which solutions count: search (combining one digit with the rest at each step, like solve) or splits (also joining any two groups of the digits, like (a + b)*(c + d))")
+	timeout := addTimeoutFlag(fs)
+	formatStr := fs.String("format", "json", "How to write the report: json (whether each target is solvable, with summary statistics), csv (a row per target) or fraction (just the fraction of the targets which are solvable)")
+	open := addOutFlag(fs, "report")
+	args = parseArgs(fs, args)
+
+	digits := mustParseDigits(*digitsStr, args)
+	from, to, err := parseTargetRange(*targetRange)
+	if err != nil {
+		usagef("--target_range invalid: %v", err)
+	}
+	engine, err := parseEngine(*engineStr)
+	if err != nil || (engine != engineSearch && engine != engineSplits) {
+		usagef("--engine invalid: want search or splits, got %q", *engineStr)
+	}
+	write, err := parseCoverageFormat(*formatStr)
+	if err != nil {
+		usagef("--format invalid: %v", err)
+	}
+	out, closeOut := open()
+	defer closeOut()
+
+	reachable := reachableValues(digits)
+	if engine == engineSplits {
+		s, err := solver{ctx: ctx}.withEngine(engine, *timeout, digits)
+		if err != nil {
+			usagef("--engine invalid: %v", err)
+		}
+		if s.subsets.incomplete {
+			slog.Error("Stopped before finding every value which can be made")
+			return exitIncomplete
+		}
+		reachable = s.subsets.reachable()
+	}
+	if err := write(analyzeCoverage(digits, reachable, from, to), out); err != nil {
+		fatalf("Failed to write coverage: %v", err)
+	}
+	return exitSolved
+}
+
+func runAnalyzeCompare(ctx context.Context, args []string) int {
+	fs := newFlagSet("analyze compare")
+	aStr := fs.String("a", "", "The first set of digits, comma-separated")
+	bStr := fs.String("b", "", "The second set of digits, comma-separated")
+	targetRange := fs.String("target_range", "100,999", "The targets to compare them over (inclusive), e.g. 100,999")
+	timeout := addTimeoutFlag(fs)
+	formatStr := fs.String("format", "text", "How to write the comparison: text or json")
+	open := addOutFlag(fs, "report")
+	args = parseArgs(fs, args)
+
+	if len(args) > 0 {
+		usagef("Unexpected arguments %q: give the digits with --a and --b", args)
+	}
+	a := mustParseDigits(*aStr, nil)
+	b := mustParseDigits(*bStr, nil)
+	from, to, err := parseTargetRange(*targetRange)
+	if err != nil {
+		usagef("--target_range invalid: %v", err)
+	}
+	write, err := parseCompareFormat(*formatStr)
+	if err != nil {
+		usagef("--format invalid: %v", err)
+	}
+	out, closeOut := open()
+	defer closeOut()
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	countsA, countsB := countSolutions(ctx, a), countSolutions(ctx, b)
+	if countsA.incomplete || countsB.incomplete {
+		slog.Error("Stopped before counting every solution")
+		return exitIncomplete
+	}
+	if err := write(compareDigits(a, b, countsA, countsB, from, to), out); err != nil {
+		fatalf("Failed to write comparison: %v", err)
+	}
+	return exitSolved
+}
+
+func runAnalyzeUnique(ctx context.Context, args []string) int {
+	fs := newFlagSet("analyze unique")
+	digitsStr := fs.String("digits", "", "A comma-separated list of digits; they may also be given as arguments")
+	targetRange := fs.String("target_range", "100,999", "The targets to look through (inclusive), e.g. 100,999")
+	timeout := addTimeoutFlag(fs)
+	jsonFlag := fs.Bool("json", false, "Write the targets and their solutions as JSON lines, rather than text")
+	printerFlags := addPrinterFlags(fs)
+	open := addOutFlag(fs, "report")
+	args = parseArgs(fs, args)
+
+	digits := mustParseDigits(*digitsStr, args)
+	from, to, err := parseTargetRange(*targetRange)
+	if err != nil {
+		usagef("--target_range invalid: %v", err)
+	}
+	p := printerFlags.printer()
+	out, closeOut := open()
+	defer closeOut()
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	c := countSolutions(ctx, digits)
+	if c.incomplete {
+		slog.Error("Stopped before counting every solution")
+		return exitIncomplete
+	}
+	unique := c.uniqueTargets(from, to)
+	w := bufio.NewWriter(out)
+	enc := json.NewEncoder(w)
+	for _, u := range unique {
+		if *jsonFlag {
+			enc.Encode(struct {
+				Target   int    `json:"target"`
+				Solution string `json:"solution"`
+			}{u.Target, p.format(u.Solution)})
+			continue
+		}
+		fmt.Fprintf(w, "%d = %s\n", u.Target, p.format(u.Solution))
+	}
+	if !*jsonFlag {
+		fmt.Fprintf(w, "%d of %d targets have exactly one solution\n", len(unique), to-from+1)
+	}
+	if err := w.Flush(); err != nil {
+		fatalf("Failed to write targets: %v", err)
+	}
+	if len(unique) == 0 {
+		return exitNoSolution
+	}
+	return exitSolved
+}
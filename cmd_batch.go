@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"sync/atomic"
+)
+
+func runBatch(ctx context.Context, args []string) int {
+	fs := newFlagSet("batch")
+	inputPath := fs.String("input", "", "If set, read puzzles from this file rather than stdin: .csv with digits and target columns, .json with an array of {digits, target} objects, or digits:target lines otherwise")
+	jsonFlag := fs.Bool("json", false, "Write one JSON object per puzzle rather than a line of text")
+	output := addOutputFlags(fs)
+	timeout := addTimeoutFlag(fs)
+	xlsxPath := fs.String("xlsx", "", "If set, also write an Excel workbook of the results to this path, with a worksheet for each set of digits")
+	if args := parseArgs(fs, args); len(args) > 0 {
+		usagef("Unexpected arguments %q", args)
+	}
+
+	printer := output.printer()
+	tmpl := output.outputTemplate()
+
+	var in io.Reader = os.Stdin
+	format := batchLines
+	if *inputPath != "" && *inputPath != "-" {
+		f, err := os.Open(*inputPath)
+		if err != nil {
+			usagef("--input invalid: %v", err)
+		}
+		defer f.Close()
+		in = f
+		format = batchFormatForPath(*inputPath)
+	}
+
+	out, closeOut := output.open()
+
+	// Flush after each puzzle so results can be consumed as they're produced.
+	w := bufio.NewWriter(out)
+	var rows, failed, unsolved, incompleteRows int
+	var sheets digitSetGrouper
+	err := readPuzzles(in, format, func(row puzzleRow) error {
+		rows++
+		if row.Err != nil {
+			failed++
+			r := batchResult{Row: row.Row, Error: row.Err.Error(), Digits: row.Puzzle.Digits, Target: row.Puzzle.Target}
+			if err := writeBatchResult(w, r, *jsonFlag); err != nil {
+				return err
+			}
+			return w.Flush()
+		}
+
+		pz := row.Puzzle
+		var generated atomic.Int64
+		solns, incomplete := solver{ctx: ctx, generated: &generated}.solveWithin(*timeout, pz.Target, pz.Digits)
+		if ctx.Err() != nil {
+			// Interrupted: leave out the puzzle which was cut short.
+			return ctx.Err()
+		}
+		if *xlsxPath != "" {
+			sheets.add(pz.Digits, targetResult{Target: pz.Target, Solutions: solns, Incomplete: incomplete})
+		}
+		if tmpl != nil {
+			r := makeSolveResult(pz.Target, pz.Digits, solns, printer)
+			r.Incomplete = incomplete
+			if err := writeTemplate(w, tmpl, r); err != nil {
+				return err
+			}
+			return w.Flush()
+		}
+		if incomplete {
+			incompleteRows++
+		} else if len(solns) == 0 {
+			unsolved++
+		}
+		r := makeBatchResult(row.Row, pz, solns, printer)
+		r.Incomplete = incomplete
+		r.Generated = generated.Load()
+		if err := writeBatchResult(w, r, *jsonFlag); err != nil {
+			return err
+		}
+		return w.Flush()
+	})
+	if err != nil && ctx.Err() == nil {
+		fatalf("Failed to solve batch: %v", err)
+	}
+	closeOut()
+	if *xlsxPath != "" {
+		// Whatever was finished is written, even if the run was interrupted.
+		if err := saveXLSX(*xlsxPath, sheets.sets, printer); err != nil {
+			fatalf("Failed to write workbook: %v", err)
+		}
+	}
+	if ctx.Err() != nil {
+		slog.Warn("Interrupted", "rows_done", rows-1)
+		return exitInterrupted
+	}
+
+	// Report the most serious problem: bad rows, then timeouts, then puzzles without a solution.
+	switch {
+	case failed > 0:
+		slog.Error("Some rows could not be read", "failed", failed, "rows", rows)
+		return exitInvalidInput
+	case incompleteRows > 0:
+		return exitIncomplete
+	case unsolved > 0:
+		return exitNoSolution
+	}
+	return exitSolved
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"runtime"
+)
+
+func runBench(ctx context.Context, args []string) int {
+	fs := newFlagSet("bench")
+	runStr := fs.String("run", "", "If set, only run the workloads whose names match this regular expression, e.g. solve/")
+	count := fs.Int("count", 3, "How many times to run each workload; the fastest run is reported")
+	jsonFlag := fs.Bool("json", false, "Write one JSON object per workload rather than a table")
+	list := fs.Bool("list", false, "List the workloads rather than running them")
+	args = parseArgs(fs, args)
+
+	if len(args) > 0 {
+		usagef("Unexpected arguments: %v", args)
+	}
+	if *count < 1 {
+		usagef("--count must be positive, got %d", *count)
+	}
+	var match *regexp.Regexp
+	if *runStr != "" {
+		var err error
+		if match, err = regexp.Compile(*runStr); err != nil {
+			usagef("--run invalid: %v", err)
+		}
+	}
+	if *list {
+		for _, w := range benchSuite {
+			if match == nil || match.MatchString(w.name) {
+				fmt.Println(w.name)
+			}
+		}
+		return exitSolved
+	}
+
+	// Results are only comparable on the same machine and Go version, so say which they came from.
+	slog.Info("Benchmarking", "go", runtime.Version(), "os", runtime.GOOS, "arch", runtime.GOARCH, "cpus", runtime.NumCPU())
+	results := runBenchmarks(ctx, benchSuite, match, *count)
+	if len(results) == 0 && ctx.Err() == nil {
+		usagef("No workloads match --run=%s", *runStr)
+	}
+	if *jsonFlag {
+		enc := json.NewEncoder(os.Stdout)
+		for _, r := range results {
+			if err := enc.Encode(r); err != nil {
+				fatalf("Failed to write results: %v", err)
+			}
+		}
+	} else if err := writeBenchResults(os.Stdout, results); err != nil {
+		fatalf("Failed to write results: %v", err)
+	}
+	return exitSolved
+}
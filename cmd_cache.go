@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+func runCache(ctx context.Context, args []string) int {
+	fs := newFlagSet("cache")
+	cacheDir := addCacheFlag(fs)
+	args = parseArgs(fs, args)
+
+	if *cacheDir == "" {
+		usagef("--cache_dir must be provided")
+	}
+	if len(args) != 1 {
+		usagef("Want one of stats or purge, e.g. digits cache --cache_dir=~/.cache/digits stats")
+	}
+	cache := mustOpenCache(*cacheDir)
+	switch args[0] {
+	case "stats":
+		st, err := cache.stats()
+		if err != nil {
+			fatalf("Failed to read the cache: %v", err)
+		}
+		fmt.Printf("%d sets of digits, %d targets solved, %d bytes\n", st.Entries, st.Targets, st.Bytes)
+	case "purge":
+		n, err := cache.purge()
+		if err != nil {
+			fatalf("Failed to purge the cache: %v", err)
+		}
+		fmt.Printf("Deleted %d sets of digits\n", n)
+	default:
+		usagef("Unknown cache command %q, want stats or purge", args[0])
+	}
+	return exitSolved
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+func runGenerate(ctx context.Context, args []string) int {
+	fs := newFlagSet("generate")
+	count := fs.Int("count", 1, "Number of puzzles to generate")
+	seed := addSeedFlag(fs)
+	opts := addGeneratorFlags(fs)
+	output := addOutputFlags(fs)
+	worksheetPath := fs.String("worksheet", "", "If set, write the puzzles as a printable PDF worksheet to this path")
+	answerKeyFlag := fs.Bool("answer_key", false, "Add an answer key to the --worksheet")
+	ankiPath := fs.String("anki", "", "If set, write the puzzles as an Anki flashcard deck to this path")
+	if args := parseArgs(fs, args); len(args) > 0 {
+		usagef("Unexpected arguments %q", args)
+	}
+
+	printer := output.printer()
+	tmpl := output.outputTemplate()
+
+	if err := opts.validate(); err != nil {
+		usagef("Invalid options: %v", err)
+	}
+	rng := newRand(*seed)
+
+	items, err := generateSolvedPuzzles(ctx, rng, *opts, *count)
+	if err != nil {
+		fatalf("Failed to generate puzzles: %v", err)
+	}
+
+	if *worksheetPath != "" {
+		var b bytes.Buffer
+		if err := writeWorksheetPDF(&b, items, *answerKeyFlag, printer); err != nil {
+			fatalf("Failed to render worksheet: %v", err)
+		}
+		if err := writeFileAtomic(*worksheetPath, b.Bytes()); err != nil {
+			fatalf("Failed to write worksheet: %v", err)
+		}
+	}
+	if *ankiPath != "" {
+		var b bytes.Buffer
+		if err := writeAnki(&b, items, printer); err != nil {
+			fatalf("Failed to render deck: %v", err)
+		}
+		if err := writeFileAtomic(*ankiPath, b.Bytes()); err != nil {
+			fatalf("Failed to write deck: %v", err)
+		}
+	}
+	if *worksheetPath != "" || *ankiPath != "" {
+		return exitSolved
+	}
+
+	out, closeOut := output.open()
+	defer closeOut()
+	for _, item := range items {
+		if tmpl != nil {
+			r := makeSolveResult(item.Puzzle.Target, item.Puzzle.Digits, []expression{item.Solution}, printer)
+			if err := writeTemplate(out, tmpl, r); err != nil {
+				fatalf("Failed to execute template: %v", err)
+			}
+			continue
+		}
+		fmt.Fprintln(out, item.Puzzle)
+	}
+	return exitSolved
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+func runHint(ctx context.Context, args []string) int {
+	fs := newFlagSet("hint")
+	digitsStr := fs.String("digits", "", "A comma-separated list of digits; they may also be given as arguments")
+	target := fs.Int("target", 0, "The target to give hints for")
+	level := fs.Int("level", 1, "Which hint to give, from 1; each gives away more than the last, and the last is the whole solution")
+	jsonFlag := fs.Bool("json", false, "Write the hint as a JSON object with its level and the number of levels")
+	printerFlags := addPrinterFlags(fs)
+	open := addOutFlag(fs, "hint")
+	args = parseArgs(fs, args)
+
+	digits := mustParseDigits(*digitsStr, args)
+	mustValidateTarget(*target)
+	printer := printerFlags.printer()
+
+	best, err := shortest(solver{ctx: ctx}.solveFewest(*target, digits, 1))
+	if err != nil {
+		if ctx.Err() != nil {
+			return exitInterrupted
+		}
+		slog.Error("No solution found, so there are no hints")
+		return exitNoSolution
+	}
+	h, err := hintAt(best, printer.symbols, *level)
+	if err != nil {
+		usagef("--level invalid: %v", err)
+	}
+
+	out, closeOut := open()
+	if *jsonFlag {
+		if err := json.NewEncoder(out).Encode(h); err != nil {
+			fatalf("Failed to write hint: %v", err)
+		}
+	} else {
+		fmt.Fprintf(out, "Hint %d of %d: %s\n", h.Level, h.Levels, h.Hint)
+	}
+	closeOut()
+	return exitSolved
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+func runMerge(ctx context.Context, args []string) int {
+	fs := newFlagSet("merge")
+	printerFlags := addPrinterFlags(fs)
+	showStr := fs.String("show", "counts", "What to print for each target: counts, shortest (the count and shortest solution) or all (every solution)")
+	open := addOutFlag(fs, "results")
+	mergedPath := fs.String("merged", "", "If set, also save the combined results to this path, in the same format as --checkpoint")
+	args = parseArgs(fs, args)
+
+	if len(args) == 0 {
+		usagef("Want the --checkpoint files of a range run's shards, e.g. digits merge shard-1.json shard-2.json")
+	}
+	show, err := parseRangeShow(*showStr)
+	if err != nil {
+		usagef("--show invalid: %v", err)
+	}
+	printer := printerFlags.printer()
+
+	var shards []rangeCheckpoint
+	for _, path := range args {
+		c, err := readCheckpoint(path)
+		if err != nil {
+			usagef("Invalid shard: %v", err)
+		}
+		shards = append(shards, c)
+	}
+	merged, err := mergeShards(shards)
+	if err != nil {
+		usagef("Can't merge: %v", err)
+	}
+	results, err := merged.results()
+	if err != nil {
+		usagef("Invalid shard: %v", err)
+	}
+	if *mergedPath != "" {
+		if err := writeCheckpoint(*mergedPath, merged); err != nil {
+			fatalf("Failed to write merged results: %v", err)
+		}
+	}
+
+	out, closeOut := open()
+	incomplete := false
+	for _, r := range results {
+		if err := writeRangeResult(out, r, show, printer); err != nil {
+			fatalf("Failed to write result: %v", err)
+		}
+		incomplete = incomplete || r.Incomplete
+	}
+	fmt.Fprint(out, formatRangeSummary(results))
+	closeOut()
+	if incomplete {
+		return exitIncomplete
+	}
+	return exitSolved
+}
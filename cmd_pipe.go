@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"os"
+)
+
+func runPipe(ctx context.Context, args []string) int {
+	fs := newFlagSet("pipe")
+	digitsStr := fs.String("digits", "", "A comma-separated list of digits to solve with; they may also be given as arguments")
+	jsonFlag := fs.Bool("json", false, "Write one JSON object per target rather than a line of text")
+	printerFlags := addPrinterFlags(fs)
+	cacheDir := addCacheFlag(fs)
+	args = parseArgs(fs, args)
+
+	digits := mustParseDigits(*digitsStr, args)
+	printer := printerFlags.printer()
+
+	go func() {
+		// Reading stdin can't be interrupted, so exit straight away on Ctrl-C.
+		<-ctx.Done()
+		exit(exitInterrupted)
+	}()
+
+	cache := mustOpenCache(*cacheDir)
+	err := answerTargets(os.Stdin, os.Stdout, newSolveCache(digits, cache), printer, *jsonFlag)
+	cache.flush()
+	if err != nil {
+		fatalf("Failed to answer targets: %v", err)
+	}
+	return exitSolved
+}
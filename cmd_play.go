@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func runPlay(ctx context.Context, args []string) int {
+	fs := newFlagSet("play")
+	digitsStr := fs.String("digits", "", "A comma-separated list of digits to play with; they may also be given as arguments. If unset, a random puzzle is generated")
+	target := fs.Int("target", 0, "The target to make; required with --digits")
+	seed := addSeedFlag(fs)
+	largeCount := fs.Int("large", -1, "How many large numbers (25, 50, 75, 100) a generated puzzle uses, or -1 for a random number")
+	timeLimit := fs.Duration("time", 0, "If positive, play against a countdown clock of this long, e.g. 30s; when it runs out the closest number is scored")
+	unicode := fs.Bool("unicode", true, "Show operators as ×, ÷ and − rather than ASCII")
+	args = parseArgs(fs, args)
+
+	if *timeLimit < 0 {
+		usagef("--time must not be negative, got %s", *timeLimit)
+	}
+
+	var p puzzle
+	if *digitsStr != "" || len(args) > 0 {
+		if *target == 0 {
+			usagef("--target must be provided when the digits are given")
+		}
+		mustValidateTarget(*target)
+		p = puzzle{Digits: mustParseDigits(*digitsStr, args), Target: *target}
+	} else {
+		opts := defaultGeneratorOptions
+		opts.Large = *largeCount
+		rng := newRand(*seed)
+		g, err := generatePuzzle(ctx, rng, opts, nil)
+		if err != nil {
+			fatalf("Failed to generate a puzzle: %v", err)
+		}
+		p = g.Puzzle
+	}
+
+	m := newPlayModel(newGame(p), newPrinter(notationInfix, *unicode).symbols, *timeLimit)
+	// The terminal is in raw mode, so Ctrl-C arrives as a key press rather than cancelling ctx.
+	if _, err := tea.NewProgram(m, tea.WithContext(ctx)).Run(); err != nil && ctx.Err() == nil {
+		fatalf("Failed to run game: %v", err)
+	}
+	return exitSolved
+}
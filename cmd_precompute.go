@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+)
+
+func runPrecompute(ctx context.Context, args []string) int {
+	fs := newFlagSet("precompute")
+	cacheDir := addCacheFlag(fs)
+	targetRange := fs.String("target_range", "100,999", "The targets to solve for each selection (inclusive)")
+	count := fs.Int("count", 6, "How many numbers each selection has")
+	large := fs.Int("large", -1, "Only precompute the selections with this many large numbers, or -1 for all of them")
+	parallelism := addParallelismFlag(fs)
+	progressFlag := fs.Bool("progress", false, "Show a progress bar on stderr")
+	args = parseArgs(fs, args)
+
+	if len(args) > 0 {
+		usagef("Unexpected arguments: %v", args)
+	}
+	if *cacheDir == "" {
+		usagef("--cache_dir must be provided")
+	}
+	min, max, err := parseTargetRange(*targetRange)
+	if err != nil {
+		usagef("--target_range invalid: %v", err)
+	}
+	if *parallelism < 1 {
+		usagef("--parallelism must be positive, got %d", *parallelism)
+	}
+	opts := generatorOptions{Count: *count, Large: *large, MinTarget: min, MaxTarget: max}
+	if err := opts.validate(); err != nil {
+		usagef("Invalid selections: %v", err)
+	}
+	cache := mustOpenCache(*cacheDir)
+
+	selections := countdownSelections(*count, *large)
+	slog.Info("Precomputing", "selections", len(selections), "targets", max-min+1)
+	var prog *progress
+	done := func() {}
+	if *progressFlag {
+		prog = startProgress(os.Stderr, len(selections)*(max-min+1), progressInterval)
+		done = func() { prog.done.Add(1) }
+	}
+	finished := 0
+	for _, sel := range selections {
+		complete, err := precomputeTable(ctx, cache, sel, min, max, *parallelism, done)
+		if err != nil {
+			fatalf("Failed to precompute %s: %v", formatDigits(sel), err)
+		}
+		if !complete {
+			break
+		}
+		finished++
+	}
+	if prog != nil {
+		prog.finish()
+	}
+	if ctx.Err() != nil {
+		slog.Warn("Interrupted; run again to carry on", "selections_done", finished, "selections", len(selections))
+	}
+	return exitSolved
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+func runRange(ctx context.Context, args []string) int {
+	fs := newFlagSet("range")
+	digitsStr := fs.String("digits", "", "A comma-separated list of digits; they may also be given as arguments")
+	targetRange := fs.String("target_range", "", "The target range to produce solutions for (inclusive), e.g. 100,999")
+	output := addOutputFlags(fs)
+	filter := addFilterFlags(fs)
+	timeout := addTimeoutFlag(fs)
+	maxNodes := addMaxNodesFlag(fs)
+	engineStr := addEngineFlag(fs)
+	countOnly := fs.Bool("count_only", false, "Only count each target's solutions, which is faster as they aren't kept; only --show=counts works with it")
+	countDistinct := fs.Bool("count_distinct", false, "Count each target's distinct solutions, treating those which only reorder or regroup sums and products, or swap copies of a repeated digit, as the same, without finding them; much faster for big ranges, and only --show=counts works with it")
+	parallelism := addParallelismFlag(fs)
+	progressFlag := fs.Bool("progress", false, "Show a progress bar on stderr")
+	showStr := fs.String("show", "counts", "What to print for each target: counts, shortest (the count and shortest solution) or all (every solution)")
+	sortStr := fs.String("sort", "found", "Order to list solutions in with --show=all: found (search order), length, ops, intermediate, lex, easiest, cleverest or human")
+	maxResults := fs.Int("max_results", 0, "If positive, stop searching each target once this many solutions are found")
+	reportPath := fs.String("report", "", "If set, write an HTML report of the run to this path")
+	xlsxPath := fs.String("xlsx", "", "If set, write an Excel workbook of the run to this path")
+	histogramFlag := fs.Bool("histogram", false, "After the run, print a histogram of solution counts")
+	opStatsFlag := fs.Bool("op_stats", false, "After the run, print how often each operation is used across the solutions, and how many targets need division")
+	checkpointPath := fs.String("checkpoint", "", "If set, periodically save the finished targets to this path so the run can be resumed")
+	checkpointInterval := fs.Duration("checkpoint_interval", 30*time.Second, "How often to save the --checkpoint")
+	resume := fs.Bool("resume", false, "Continue the run saved in --checkpoint rather than starting again")
+	shardStr := fs.String("shard", "", "If set to K/N, only solve the Kth of N equal parts of the range, saving them in --checkpoint for digits merge to combine with the others")
+	args = parseArgs(fs, args)
+
+	digits := mustParseDigits(*digitsStr, args)
+	if *targetRange == "" {
+		usagef("--target_range must be provided")
+	}
+	min, max, err := parseTargetRange(*targetRange)
+	if err != nil {
+		usagef("--target_range invalid: %v", err)
+	}
+	from, to := min, max
+	shard, shards := 0, 0
+	if *shardStr != "" {
+		if shard, shards, err = parseShard(*shardStr); err != nil {
+			usagef("--shard invalid: %v", err)
+		}
+		if *checkpointPath == "" {
+			usagef("--shard requires --checkpoint, where the shard's results are saved to merge")
+		}
+		from, to = shardRange(min, max, shard, shards)
+	}
+	if *maxResults < 0 {
+		usagef("--max_results must not be negative, got %d", *maxResults)
+	}
+	if *parallelism < 1 {
+		usagef("--parallelism must be positive, got %d", *parallelism)
+	}
+	if err := filter.validate(); err != nil {
+		usagef("Invalid filter: %v", err)
+	}
+	engine, err := parseEngine(*engineStr)
+	if err != nil {
+		usagef("--engine invalid: %v", err)
+	}
+	s, err := solver{limit: *maxResults, filter: *filter, ctx: ctx}.withEngine(engine, *timeout, digits)
+	if err != nil {
+		usagef("--engine invalid: %v", err)
+	}
+	// The targets share their sub-searches, which are much the same from one target to the next.
+	s.cache = newSearchCache(digits)
+	order, err := parseSolutionOrder(*sortStr)
+	if err != nil {
+		usagef("--sort invalid: %v", err)
+	}
+	show, err := parseRangeShow(*showStr)
+	if err != nil {
+		usagef("--show invalid: %v", err)
+	}
+	if *resume && *checkpointPath == "" {
+		usagef("--resume requires --checkpoint")
+	}
+	countFlag := ""
+	switch {
+	case *countOnly && *countDistinct:
+		usagef("only one of --count_only or --count_distinct may be provided")
+	case *countOnly:
+		countFlag = "count_only"
+	case *countDistinct:
+		countFlag = "count_distinct"
+		if engine != engineSearch || *filter != (solutionFilter{}) || *maxResults > 0 || *maxNodes > 0 {
+			usagef("--count_distinct counts every solution its own way, so can't be used with --engine, filters, --max_results or --max_nodes")
+		}
+	}
+	if countFlag != "" {
+		if show != showCounts {
+			usagef("--%s requires --show=counts", countFlag)
+		}
+		for _, f := range []struct {
+			name string
+			set  bool
+		}{
+			{"template", output.template != ""},
+			{"report", *reportPath != ""},
+			{"xlsx", *xlsxPath != ""},
+			{"checkpoint", *checkpointPath != ""},
+			{"op_stats", *opStatsFlag},
+		} {
+			if f.set {
+				usagef("--%s can't be used with --%s, which needs the solutions", countFlag, f.name)
+			}
+		}
+	}
+	printer := output.printer()
+	tmpl := output.outputTemplate()
+
+	state := rangeCheckpoint{
+		Digits:  digits,
+		Min:     min,
+		Max:     max,
+		Options: rangeOptions(*engineStr, *filter, *maxResults, *maxNodes, *sortStr),
+		Shard:   shard,
+		Shards:  shards,
+	}
+	var resumed []targetResult
+	if *resume {
+		saved, err := readCheckpoint(*checkpointPath)
+		switch {
+		case os.IsNotExist(err):
+			slog.Info("No checkpoint found, starting from the beginning", "path", *checkpointPath)
+		case err != nil:
+			usagef("--checkpoint invalid: %v", err)
+		default:
+			if err := saved.matches(state); err != nil {
+				usagef("Can't resume: %v", err)
+			}
+			if saved.Shard != state.Shard {
+				usagef("Can't resume: checkpoint is for shard %d, not %d", saved.Shard, state.Shard)
+			}
+			if resumed, err = saved.results(); err != nil {
+				usagef("--checkpoint invalid: %v", err)
+			}
+			state.Results = saved.Results
+			slog.Info("Resuming from checkpoint", "path", *checkpointPath, "targets_done", len(resumed))
+		}
+	}
+	saveCheckpoint := func() {
+		if *checkpointPath == "" {
+			return
+		}
+		if err := writeCheckpoint(*checkpointPath, state); err != nil {
+			fatalf("Failed to write checkpoint: %v", err)
+		}
+	}
+
+	out, closeOut := output.open()
+	defer closeOut()
+
+	var prog *progress
+	if *progressFlag {
+		prog = startProgress(os.Stderr, to-from+1, progressInterval)
+		s.nodes = &prog.nodes
+	}
+
+	var results []targetResult
+	writeResult := func(r targetResult) {
+		if tmpl != nil {
+			sr := makeSolveResult(r.Target, digits, r.Solutions, printer)
+			sr.Incomplete = r.Incomplete
+			if err := writeTemplate(out, tmpl, sr); err != nil {
+				fatalf("Failed to execute template: %v", err)
+			}
+		} else if err := writeRangeResult(out, r, show, printer); err != nil {
+			fatalf("Failed to write result: %v", err)
+		}
+		results = append(results, r)
+		if prog != nil {
+			prog.done.Add(1)
+		}
+	}
+	for _, r := range resumed {
+		writeResult(r)
+	}
+
+	var counts *solutionCounts
+	if *countDistinct {
+		countCtx := ctx
+		if *timeout > 0 {
+			var cancel context.CancelFunc
+			countCtx, cancel = context.WithTimeout(ctx, *timeout)
+			defer cancel()
+		}
+		counts = countSolutions(countCtx, digits)
+	}
+
+	lastSave := time.Now()
+	solve := func(target int) (targetResult, bool) {
+		if counts != nil {
+			return targetResult{Target: target, Count: counts.totals[target], Incomplete: counts.incomplete}, ctx.Err() == nil
+		}
+		// Each target gets the whole budget.
+		s := s
+		s.budget = newNodeBudget(*maxNodes)
+		if *countOnly {
+			n, incomplete := s.countWithin(*timeout, target, digits)
+			return targetResult{Target: target, Count: n, Incomplete: incomplete, Exhausted: s.budget.exhausted()}, ctx.Err() == nil
+		}
+		solns, incomplete := s.solveWithin(*timeout, target, digits)
+		if ctx.Err() != nil {
+			// Interrupted: leave out the target which was cut short, and write up the rest.
+			return targetResult{}, false
+		}
+		return targetResult{Target: target, Solutions: sortSolutions(solns, order, printer), Incomplete: incomplete, Exhausted: s.budget.exhausted()}, true
+	}
+	solveTargets(from+len(resumed), to, *parallelism, solve, func(r targetResult) {
+		writeResult(r)
+		state.Results = append(state.Results, makeCheckpointResult(r))
+		if time.Since(lastSave) >= *checkpointInterval {
+			saveCheckpoint()
+			lastSave = time.Now()
+		}
+	})
+	saveCheckpoint()
+	if prog != nil {
+		prog.finish()
+	}
+	if ctx.Err() != nil {
+		slog.Warn("Interrupted", "targets_done", len(results), "targets", to-from+1)
+	}
+
+	if tmpl == nil {
+		fmt.Fprint(out, formatRangeSummary(results))
+	}
+
+	if *xlsxPath != "" {
+		if err := saveXLSX(*xlsxPath, []digitSetResults{{Digits: digits, Results: results}}, printer); err != nil {
+			fatalf("Failed to write workbook: %v", err)
+		}
+	}
+
+	if *histogramFlag {
+		counts := make([]int, len(results))
+		for i, r := range results {
+			counts[i] = r.solutionCount()
+		}
+		fmt.Fprint(out, formatHistogram(counts))
+	}
+
+	if *opStatsFlag {
+		var st operationStats
+		for _, r := range results {
+			st.add(r.Solutions)
+		}
+		if err := st.write(out, printer); err != nil {
+			fatalf("Failed to write operation stats: %v", err)
+		}
+	}
+
+	if *reportPath != "" {
+		var b bytes.Buffer
+		if err := writeReport(&b, digits, results, printer); err != nil {
+			fatalf("Failed to render report: %v", err)
+		}
+		if err := writeFileAtomic(*reportPath, b.Bytes()); err != nil {
+			fatalf("Failed to write report: %v", err)
+		}
+	}
+
+	// Targets without solutions are an expected part of a range's results, but timeouts aren't.
+	exhausted, incomplete := 0, false
+	for _, r := range results {
+		if r.Exhausted {
+			exhausted++
+		}
+		incomplete = incomplete || r.Incomplete
+	}
+	if exhausted > 0 {
+		slog.Warn("Node budget exhausted, so some targets may have more solutions", "targets", exhausted, "max_nodes", *maxNodes)
+	}
+	if incomplete {
+		return exitIncomplete
+	}
+	return exitSolved
+}
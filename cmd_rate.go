@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+func runRate(ctx context.Context, args []string) int {
+	fs := newFlagSet("rate")
+	digitsStr := fs.String("digits", "", "A comma-separated list of digits; they may also be given as arguments")
+	target := fs.Int("target", 0, "The target to rate the puzzle for")
+	jsonFlag := fs.Bool("json", false, "Write the rating as a JSON object with the signals it's based on")
+	open := addOutFlag(fs, "rating")
+	args = parseArgs(fs, args)
+
+	digits := mustParseDigits(*digitsStr, args)
+	mustValidateTarget(*target)
+
+	r, err := ratePuzzle(ctx, nil, puzzle{Digits: digits, Target: *target})
+	switch {
+	case errors.Is(err, errNoSolution):
+		slog.Error("No solution found, so there's nothing to rate")
+		return exitNoSolution
+	case err != nil:
+		return exitInterrupted
+	}
+
+	out, closeOut := open()
+	if *jsonFlag {
+		if err := json.NewEncoder(out).Encode(r); err != nil {
+			fatalf("Failed to write rating: %v", err)
+		}
+	} else {
+		division := ""
+		if r.NeedsDivision {
+			division = ", every one dividing"
+		}
+		fmt.Fprintf(out, "Rated %d of 5: %d solutions%s, the shortest with %d operations, needing values up to %d, after searching %d sub-problems\n",
+			r.Rating, r.Solutions, division, r.MinOps, r.LargestIntermediate, r.Nodes)
+	}
+	closeOut()
+	return exitSolved
+}
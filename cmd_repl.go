@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+func runREPL(ctx context.Context, args []string) int {
+	fs := newFlagSet("repl")
+	digitsStr := fs.String("digits", "", "A comma-separated list of digits to start with; they may also be given as arguments, or set later")
+	printerFlags := addPrinterFlags(fs)
+	cacheDir := addCacheFlag(fs)
+	args = parseArgs(fs, args)
+
+	var digits []int
+	if *digitsStr != "" || len(args) > 0 {
+		digits = mustParseDigits(*digitsStr, args)
+	}
+
+	go func() {
+		// Reading stdin can't be interrupted, so exit straight away on Ctrl-C.
+		<-ctx.Done()
+		fmt.Fprintln(os.Stdout)
+		exit(exitInterrupted)
+	}()
+
+	cache := mustOpenCache(*cacheDir)
+	r := newREPL(os.Stdout, printerFlags.printer(), digits, cache)
+	if len(digits) > 0 {
+		fmt.Fprintf(os.Stdout, "Using %s. Type help for commands.\n", formatDigits(digits))
+	} else {
+		fmt.Fprintln(os.Stdout, "Type help for commands.")
+	}
+	err := r.run(os.Stdin)
+	cache.flush()
+	if err != nil {
+		fatalf("Failed to read input: %v", err)
+	}
+	return exitSolved
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+func runSelfTest(ctx context.Context, args []string) int {
+	fs := newFlagSet("selftest")
+	puzzles := fs.Int("puzzles", 100, "How many random puzzles to check")
+	maxDigits := fs.Int("max_digits", 6, "The most digits a puzzle has; each has at least 1")
+	oracleDigits := fs.Int("oracle_digits", 4, "The most digits a puzzle can have to be checked against the brute-force solver, which is very slow for more")
+	seed := addSeedFlag(fs)
+	progressFlag := fs.Bool("progress", false, "Show how many puzzles have been checked on stderr")
+	if args := parseArgs(fs, args); len(args) > 0 {
+		usagef("Unexpected arguments %q", args)
+	}
+	if *puzzles <= 0 {
+		usagef("--puzzles must be positive, got %d", *puzzles)
+	}
+	if *maxDigits <= 0 || *maxDigits > maxDigitCount {
+		usagef("--max_digits must be between 1 and %d, got %d", maxDigitCount, *maxDigits)
+	}
+
+	s := solver{ctx: ctx}
+	var prog *progress
+	var done func()
+	if *progressFlag {
+		prog = startProgress(os.Stderr, *puzzles, progressInterval)
+		s.nodes = &prog.nodes
+		done = func() { prog.done.Add(1) }
+	}
+	opts := selfTestOptions{Puzzles: *puzzles, MaxDigits: *maxDigits, OracleDigits: *oracleDigits}
+	failures := s.selfTest(newRand(*seed), opts, done)
+	if prog != nil {
+		prog.finish()
+	}
+	for _, f := range failures {
+		fmt.Println(f)
+	}
+	if ctx.Err() != nil {
+		return exitInterrupted
+	}
+	fmt.Printf("Checked %d puzzles: %d failures\n", *puzzles, len(failures))
+	if len(failures) > 0 {
+		return exitFailed
+	}
+	return exitSolved
+}
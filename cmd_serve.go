@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/hulkholden/digits/digitspb"
+	"google.golang.org/grpc"
+)
+
+// shutdownTimeout is how long the server waits for requests in progress once it's asked to stop.
+const shutdownTimeout = 5 * time.Second
+
+func runServe(ctx context.Context, args []string) int {
+	fs := newFlagSet("serve")
+	listen := fs.String("listen", ":8080", "The address to listen for HTTP requests on, with the web UI at /")
+	grpcListen := fs.String("grpc_listen", "", "If set, also serve the gRPC API in digitspb/digits.proto on this address")
+	timeout := fs.Duration("timeout", 10*time.Second, "If positive, stop each request's search after this long, and reply with the solutions found so far marked incomplete")
+	maxNodes := addMaxNodesFlag(fs)
+	if args := parseArgs(fs, args); len(args) > 0 {
+		usagef("Unexpected arguments %q", args)
+	}
+
+	// The config's presets are offered to generate requests.
+	c, _, _, err := loadConfig()
+	if err != nil {
+		usagef("Invalid config: %v", err)
+	}
+	srv := &server{timeout: *timeout, maxNodes: *maxNodes, config: c, log: slog.Default()}
+	hs := &http.Server{Addr: *listen, Handler: srv.handler(), ReadHeaderTimeout: 10 * time.Second}
+	errc := make(chan error, 2)
+	go func() {
+		errc <- hs.ListenAndServe()
+	}()
+	slog.Info("Serving", "listen", *listen)
+
+	var gs *grpc.Server
+	if *grpcListen != "" {
+		lis, err := net.Listen("tcp", *grpcListen)
+		if err != nil {
+			fatalf("Failed to listen for gRPC: %v", err)
+		}
+		gs = grpc.NewServer()
+		digitspb.RegisterDigitsServer(gs, &grpcServer{srv: srv})
+		go func() {
+			errc <- gs.Serve(lis)
+		}()
+		slog.Info("Serving gRPC", "listen", *grpcListen)
+	}
+
+	select {
+	case err := <-errc:
+		fatalf("Failed to serve: %v", err)
+	case <-ctx.Done():
+	}
+	slog.Info("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	// A clean shutdown is the usual way for serve to end, so it's a success, unless requests had to be cut off.
+	status := exitSolved
+	if gs != nil {
+		// Streams may run for a while, so they're cut off if they don't finish in time.
+		stopped := make(chan struct{})
+		go func() {
+			gs.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			slog.Warn("Cutting off gRPC streams which didn't finish in time")
+			gs.Stop()
+			status = exitFailed
+		}
+	}
+	if err := hs.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Warn("Failed to shut down cleanly", "error", err)
+		status = exitFailed
+	}
+	return status
+}
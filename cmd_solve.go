@@ -0,0 +1,608 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"text/template"
+	"time"
+)
+
+func runSolve(ctx context.Context, args []string) int {
+	fs := newFlagSet("solve")
+	digitsStr := fs.String("digits", "", "A comma-separated list of digits; they may also be given as arguments")
+	target := fs.Int("target", 0, "The exact target value to solve for")
+	solveAll := fs.Bool("solve_all", false, "Rather than solving for --target, list every value that can be made from the digits")
+	maxValue := fs.Int("max_value", 1000, "The largest value listed by --solve_all")
+	output := addOutputFlags(fs)
+	filter := addFilterFlags(fs)
+	timeout := addTimeoutFlag(fs)
+	maxNodes := addMaxNodesFlag(fs)
+	cacheDir := addCacheFlag(fs)
+	progressFlag := fs.Bool("progress", false, "Show the search's progress on stderr")
+	formatStr := fs.String("format", "text", "Output format: text, latex, mathml or mermaid")
+	latexMultiplyStr := fs.String("latex_multiply", "times", "LaTeX multiplication symbol: times or cdot")
+	svgPath := fs.String("svg", "", "If set, write an SVG drawing of the best solution's expression tree to this path, chosen by --rank")
+	pngPath := fs.String("png", "", "If set, write a PNG image of the best solution to this path, chosen by --rank")
+	pngStyleStr := fs.String("png_style", "tree", "What to draw in the PNG image: text or tree")
+	themeStr := fs.String("theme", "light", "Colour theme for images: light or dark")
+	firstOnly := fs.Bool("first_only", false, "Stop searching as soon as any solution is found, and print just that one")
+	maxResults := fs.Int("max_results", 0, "If positive, stop searching once this many solutions are found")
+	shortestOnly := fs.Bool("shortest_only", false, "Only print the shortest of the solutions with the fewest operations, which stops searching once they're found, rather than every solution")
+	smallestOnly := fs.Bool("smallest_only", false, "Only print the solution whose largest intermediate value is smallest, for working out in your head, which searches with a bound on the values worked out rather than for every solution")
+	top := fs.Int("top", 1, "How many solutions --shortest_only or --smallest_only prints")
+	sortStr := fs.String("sort", "found", "Order to list solutions in: found (search order), length, ops, intermediate (smallest intermediate results first), lex, easiest (lowest difficulty score first), cleverest (highest cleverness score first) or human (roundest intermediate results first)")
+	familiesStr := fs.String("families", "none", "Group similar solutions into families, and print one of each with the family's size: none, skeleton (the same operations arranged the same way), intermediate (the last step uses the same biggest value) or distributive (the same once common factors are taken out of sums)")
+	dedupStr := fs.String("dedup", "structure", "When solutions count as the same, so only the first is listed: structure (written the same once normalized) or intermediates (working out the same values, even in a different order, which is stricter)")
+	rankStr := fs.String("rank", "shortest", "How to pick the best solution, printed after the others: shortest (fewest operations), easiest (lowest difficulty score), cleverest (highest cleverness score) or human (passing through round numbers like multiples of 10, 25 and 100, the way people tend to solve it)")
+	explainFlag := fs.Bool("explain", false, "Describe each step of the best solution in words, chosen by --rank")
+	engineStr := addEngineFlag(fs)
+	countOnly := fs.Bool("count_only", false, "Only print how many solutions there are, which is faster as they aren't kept")
+	parallelism := addParallelismFlag(fs)
+	closest := fs.Bool("closest", false, "If the target can't be made, solve for the closest value which can instead")
+	sample := fs.Int("sample", 0, "If positive, print this many solutions chosen at random from every distinct solution, without finding them all")
+	seed := addSeedFlag(fs)
+	stream := fs.Bool("stream", false, "Write each solution as soon as it's found, never holding them all, so memory stays bounded however many there are; much slower, and duplicates are only removed while --stream_memory remembers them")
+	streamMemory := fs.Int("stream_memory", 1000000, "How many recent solutions --stream remembers to remove duplicates")
+	crosscheckFlag := fs.Bool("crosscheck", false, "Also find every solution by brute force, which is very slow, and report any differences from the solutions found, exiting with status 4 if there are any; the search engine leaves out solutions like (a + b)*(c + d), which --engine=splits finds")
+	statsFlag := fs.Bool("stats", false, "After the solutions, print how long the solve took, how many sub-problems it searched, how many solutions were kept of the expressions built, the deepest solution, and how often each operation is used")
+	searchTrace := fs.String("search_trace", "", "If set, record the search's branches, prunes and sub-searches to this path as JSON lines, for digits trace to summarize; only the search engine is traced")
+	args = parseArgs(fs, args)
+
+	digits := mustParseDigits(*digitsStr, args)
+	if err := checkSolveTarget(*target, *solveAll); err != nil {
+		usagef("%v", err)
+	}
+	if (*shortestOnly || *smallestOnly) && *top <= 0 {
+		usagef("--top must be positive, got %d", *top)
+	}
+	if *maxResults < 0 {
+		usagef("--max_results must not be negative, got %d", *maxResults)
+	}
+	if *firstOnly && *maxResults > 0 {
+		usagef("only one of --first_only or --max_results may be provided")
+	}
+	engine, err := parseEngine(*engineStr)
+	if err != nil {
+		usagef("--engine invalid: %v", err)
+	}
+	order, err := parseSolutionOrder(*sortStr)
+	if err != nil {
+		usagef("--sort invalid: %v", err)
+	}
+	rank, err := parseSolutionRank(*rankStr)
+	if err != nil {
+		usagef("--rank invalid: %v", err)
+	}
+	families, err := parseFamilyKind(*familiesStr)
+	if err != nil {
+		usagef("--families invalid: %v", err)
+	}
+	dedup, err := parseDedupMode(*dedupStr)
+	if err != nil {
+		usagef("--dedup invalid: %v", err)
+	}
+	printer := output.printer()
+	tmpl := output.outputTemplate()
+	format, err := parseOutputFormat(*formatStr)
+	if err != nil {
+		usagef("--format invalid: %v", err)
+	}
+	latex, err := newLaTeXFormatter(*latexMultiplyStr)
+	if err != nil {
+		usagef("--latex_multiply invalid: %v", err)
+	}
+	pngStyle, err := parsePNGStyle(*pngStyleStr)
+	if err != nil {
+		usagef("--png_style invalid: %v", err)
+	}
+	theme, err := parseTheme(*themeStr)
+	if err != nil {
+		usagef("--theme invalid: %v", err)
+	}
+	out, closeOut := output.open()
+	defer closeOut()
+
+	if *solveAll {
+		writeAllValues(out, digits, *maxValue, printer, tmpl)
+		return exitSolved
+	}
+
+	if err := filter.validate(); err != nil {
+		usagef("Invalid filter: %v", err)
+	}
+	if *parallelism < 1 {
+		usagef("--parallelism must be positive, got %d", *parallelism)
+	}
+	if *sample < 0 {
+		usagef("--sample must not be negative, got %d", *sample)
+	}
+	if *sample > 0 {
+		for _, f := range []struct {
+			name string
+			set  bool
+		}{
+			{"first_only", *firstOnly},
+			{"max_results", *maxResults > 0},
+			{"count_only", *countOnly},
+			{"closest", *closest},
+			{"engine", engine != engineSearch},
+			{"filters", *filter != (solutionFilter{})},
+			{"max_nodes", *maxNodes > 0},
+		} {
+			if f.set {
+				usagef("--sample can't be used with --%s", f.name)
+			}
+		}
+	}
+	if *countOnly {
+		for _, f := range []struct {
+			name string
+			set  bool
+		}{
+			{"first_only", *firstOnly},
+			{"max_results", *maxResults > 0},
+			{"closest", *closest},
+			{"dedup", dedup != dedupStructure},
+			{"template", tmpl != nil},
+			{"svg", *svgPath != ""},
+			{"png", *pngPath != ""},
+		} {
+			if f.set {
+				usagef("--count_only can't be used with --%s", f.name)
+			}
+		}
+	}
+	if *stream {
+		if *streamMemory <= 0 {
+			usagef("--stream_memory must be positive, got %d", *streamMemory)
+		}
+		for _, f := range []struct {
+			name string
+			set  bool
+		}{
+			{"count_only", *countOnly},
+			{"shortest_only", *shortestOnly},
+			{"sample", *sample > 0},
+			{"closest", *closest},
+			{"engine", engine != engineSearch},
+			{"sort", order != orderFound},
+			{"families", families != familyNone},
+			{"dedup", dedup != dedupStructure},
+			{"format", format != outputText},
+			{"template", tmpl != nil},
+			{"svg", *svgPath != ""},
+			{"png", *pngPath != ""},
+			{"explain", *explainFlag},
+			{"cache_dir", *cacheDir != ""},
+		} {
+			if f.set {
+				usagef("--stream can't be used with --%s, which needs every solution", f.name)
+			}
+		}
+	}
+	if *smallestOnly {
+		for _, f := range []struct {
+			name string
+			set  bool
+		}{
+			{"shortest_only", *shortestOnly},
+			{"count_only", *countOnly},
+			{"sample", *sample > 0},
+			{"stream", *stream},
+			{"engine", engine != engineSearch},
+			{"families", families != familyNone},
+		} {
+			if f.set {
+				usagef("--smallest_only can't be used with --%s", f.name)
+			}
+		}
+	}
+	if families != familyNone {
+		for _, f := range []struct {
+			name string
+			set  bool
+		}{
+			{"count_only", *countOnly},
+			{"first_only", *firstOnly},
+			{"shortest_only", *shortestOnly},
+			{"format", format != outputText},
+			{"template", tmpl != nil},
+		} {
+			if f.set {
+				usagef("--families can't be used with --%s", f.name)
+			}
+		}
+	}
+	if *statsFlag {
+		for _, f := range []struct {
+			name string
+			set  bool
+		}{
+			{"count_only", *countOnly},
+			{"stream", *stream},
+			{"format", format != outputText},
+			{"template", tmpl != nil},
+		} {
+			if f.set {
+				usagef("--stats can't be used with --%s", f.name)
+			}
+		}
+	}
+	if *crosscheckFlag {
+		for _, f := range []struct {
+			name string
+			set  bool
+		}{
+			{"first_only", *firstOnly},
+			{"max_results", *maxResults > 0},
+			{"count_only", *countOnly},
+			{"shortest_only", *shortestOnly},
+			{"smallest_only", *smallestOnly},
+			{"sample", *sample > 0},
+			{"stream", *stream},
+			{"closest", *closest},
+			{"cache_dir", *cacheDir != ""},
+		} {
+			if f.set {
+				usagef("--crosscheck can't be used with --%s, as it compares every solution", f.name)
+			}
+		}
+	}
+	s := solver{limit: *maxResults, filter: *filter, ctx: ctx, log: slog.Default(), parallelism: *parallelism}
+	if *firstOnly {
+		s.limit = 1
+	}
+	if s, err = s.withEngine(engine, *timeout, digits); err != nil {
+		usagef("--engine invalid: %v", err)
+	}
+	if *searchTrace != "" {
+		if engine != engineSearch {
+			usagef("--search_trace can only be used with --engine=search")
+		}
+		t, err := createTrace(*searchTrace)
+		if err != nil {
+			fatalf("Failed to create search trace: %v", err)
+		}
+		s.trace = t
+		defer func() {
+			if err := t.close(); err != nil {
+				slog.Warn("Failed to write search trace", "error", err)
+			}
+		}()
+	}
+	var generated atomic.Int64
+	s.generated = &generated
+	var prog *progress
+	if *progressFlag {
+		prog = startProgress(os.Stderr, 0, progressInterval)
+		s.nodes = &prog.nodes
+	}
+	if *statsFlag && s.nodes == nil {
+		s.nodes = new(atomic.Int64)
+	}
+	if *crosscheckFlag {
+		s.budget = newNodeBudget(*maxNodes)
+		solns, incomplete := s.solveWithin(*timeout, *target, digits)
+		s.budget = nil
+		oracle, stopped := s.oracleSolve(*target, digits)
+		if prog != nil {
+			prog.finish()
+		}
+		if incomplete || stopped {
+			slog.Warn("Stopped before finding every solution, so there's nothing to compare")
+			return exitIncomplete
+		}
+		r := crosscheck(solns, oracle)
+		if err := r.write(out, printer); err != nil {
+			fatalf("Failed to write crosscheck: %v", err)
+		}
+		if !r.ok() {
+			return exitFailed
+		}
+		return exitSolved
+	}
+	if *countOnly {
+		s.budget = newNodeBudget(*maxNodes)
+		n, incomplete := s.countWithin(*timeout, *target, digits)
+		if prog != nil {
+			prog.finish()
+		}
+		fmt.Fprintln(out, solutionCount(n, generated.Load()))
+		switch {
+		case ctx.Err() != nil:
+			slog.Warn("Interrupted, so there may be more solutions")
+			return exitIncomplete
+		case s.budget.exhausted():
+			slog.Warn("Node budget exhausted, so there may be more solutions", "max_nodes", *maxNodes)
+			return exitIncomplete
+		case incomplete:
+			slog.Warn("Timed out, so there may be more solutions", "timeout", *timeout)
+			return exitIncomplete
+		case n == 0:
+			return exitNoSolution
+		}
+		return exitSolved
+	}
+	if *stream {
+		s.budget = newNodeBudget(*maxNodes)
+		seen := newRecentSet(*streamMemory)
+		n := 0
+		stopped := s.streamSolutionsWithin(*timeout, *target, digits, seen, func(soln expression) bool {
+			if _, err := fmt.Fprintf(out, "%d: %d = %s\n", n, soln.Val, printer.format(soln)); err != nil {
+				fatalf("Failed to write solution: %v", err)
+			}
+			n++
+			return s.limit == 0 || n < s.limit
+		})
+		if prog != nil {
+			prog.finish()
+		}
+		if seen.forgot > 0 {
+			slog.Warn("Too many solutions to remember, so some may be repeated", "stream_memory", *streamMemory)
+		}
+		switch {
+		case ctx.Err() != nil:
+			slog.Warn("Interrupted, so there may be more solutions", "found", n)
+			return exitIncomplete
+		case s.budget.exhausted():
+			slog.Warn("Node budget exhausted, so there may be more solutions", "max_nodes", *maxNodes, "found", n)
+			return exitIncomplete
+		case stopped:
+			slog.Warn("Timed out, so there may be more solutions", "timeout", *timeout, "found", n)
+			return exitIncomplete
+		case n == 0:
+			fmt.Fprintf(out, "no solution found :(\n")
+			return exitNoSolution
+		}
+		return exitSolved
+	}
+	cache := mustOpenCache(*cacheDir)
+	defer cache.flush()
+	options := cacheOptions(*engineStr, *filter, s.limit)
+	find := func(target int) ([]expression, bool) {
+		if solns, ok := cache.lookup(digits, options, target); ok {
+			return solns, false
+		}
+		// Each search gets the whole budget.
+		s.budget = newNodeBudget(*maxNodes)
+		// These are only some of the solutions, so they aren't cached.
+		if *shortestOnly {
+			return s.solveFewestWithin(*timeout, target, digits, *top)
+		}
+		if *smallestOnly {
+			return s.solveSmallestWithin(*timeout, target, digits)
+		}
+		solns, incomplete := s.solveWithin(*timeout, target, digits)
+		if !incomplete {
+			cache.record(digits, options, target, solns)
+		}
+		return solns, incomplete
+	}
+	start := time.Now()
+	var solns []expression
+	var incomplete bool
+	if *sample > 0 {
+		solns, incomplete = sampleSolutions(ctx, *timeout, *target, digits, *sample, newRand(*seed))
+	} else {
+		solns, incomplete = find(*target)
+	}
+	if prog != nil {
+		prog.finish()
+	}
+	switch {
+	case ctx.Err() != nil:
+		slog.Warn("Interrupted, so there may be more solutions", "found", len(solns))
+	case s.budget.exhausted():
+		slog.Warn("Node budget exhausted, so there may be more solutions", "max_nodes", *maxNodes, "found", len(solns))
+	case incomplete && engine == engineQuick && len(solns) == 0:
+		slog.Warn("The quick engine gave up, so there may be a solution; try another engine")
+	case incomplete:
+		slog.Warn("Timed out, so there may be more solutions", "timeout", *timeout, "found", len(solns))
+	case *maxResults > 0 && len(solns) == *maxResults:
+		slog.Info("Stopped at --max_results, so there may be more solutions", "found", len(solns))
+	}
+	// solved is the value the solutions make, which is only different from target with --closest.
+	solved := *target
+	if *closest && len(solns) == 0 && !incomplete {
+		reachable := reachableValues(digits)
+		if s.subsets != nil {
+			reachable = s.subsets.reachable()
+		}
+		if v := closestValue(reachable, *target); v != 0 {
+			solved = v
+			solns, incomplete = find(solved)
+		}
+	}
+	elapsed := time.Since(start)
+	status := exitSolved
+	switch {
+	case incomplete:
+		status = exitIncomplete
+	case len(solns) == 0 || solved != *target:
+		status = exitNoSolution
+	}
+	solns = dedup.dedup(solns)
+	solns = sortSolutions(solns, order, printer)
+	if *shortestOnly {
+		solns = shortestN(solns, *top)
+	}
+	if *smallestOnly {
+		solns = smallestIntermediate(solns, *top)
+	}
+	if tmpl != nil {
+		r := makeSolveResult(*target, digits, solns, printer)
+		r.Incomplete = incomplete
+		r.Delta = solved - *target
+		if err := writeTemplate(out, tmpl, r); err != nil {
+			fatalf("Failed to execute template: %v", err)
+		}
+		return status
+	}
+	if len(solns) == 0 {
+		fmt.Fprintf(out, "no solution found :(\n")
+		// Values made without filters or a time limit say nothing about what those searches could find.
+		if !incomplete && *filter == (solutionFilter{}) {
+			writeClosest(out, *target, digits, printer)
+		}
+		return status
+	}
+
+	for _, soln := range solns {
+		if err := checkSolution(soln, digits, solved); err != nil {
+			fatalf("Generated an invalid solution: %v", err)
+		}
+	}
+
+	best, err := rank.best(solns)
+	if err != nil {
+		fatalf("Failed to get best solution: %v", err)
+	}
+
+	if *svgPath != "" {
+		if err := writeFileAtomic(*svgPath, []byte(renderSVG(best, printer.symbols, theme))); err != nil {
+			fatalf("Failed to write SVG: %v", err)
+		}
+	}
+	if *pngPath != "" {
+		data, err := renderPNG(best, solved, printer, pngStyle, theme)
+		if err != nil {
+			fatalf("Failed to render PNG: %v", err)
+		}
+		if err := writeFileAtomic(*pngPath, data); err != nil {
+			fatalf("Failed to write PNG: %v", err)
+		}
+	}
+
+	switch format {
+	case outputLaTeX:
+		if err := latex.writeDocument(out, solved, digits, solns, best); err != nil {
+			fatalf("Failed to write LaTeX: %v", err)
+		}
+	case outputMathML:
+		if err := writeMathML(out, solved, solns, best); err != nil {
+			fatalf("Failed to write MathML: %v", err)
+		}
+	case outputMermaid:
+		if err := writeMermaid(out, solved, solns, printer); err != nil {
+			fatalf("Failed to write Mermaid: %v", err)
+		}
+	default:
+		if solved != *target {
+			fmt.Fprintf(out, "No exact solution, so these are approximate: %d is the closest value to %d (%+d)\n", solved, *target, solved-*target)
+		}
+		if *shortestOnly || *smallestOnly || *firstOnly {
+			writeBrief(out, solns, best, printer, *explainFlag)
+			break
+		}
+		if families != familyNone {
+			groups := groupFamilies(solns, families)
+			for i, f := range groups {
+				fmt.Fprintf(out, "%d: %d = %s (family of %d)\n", i, f.Representative.Val, printer.format(f.Representative), len(f.Members))
+			}
+			fmt.Fprintf(out, "%d solutions in %d families\n", len(solns), len(groups))
+		} else {
+			for i, soln := range solns {
+				fmt.Fprintf(out, "%d: %d = %s\n", i, soln.Val, printer.format(soln))
+			}
+		}
+		fmt.Fprintln(out, solutionCount(len(solns), generated.Load()))
+		if rank == rankShortest {
+			fmt.Fprintf(out, "Shortest solution: %s\n", printer.format(best))
+		} else {
+			fmt.Fprintf(out, "Best solution (%s): %s\n", *rankStr, printer.format(best))
+		}
+		if *explainFlag {
+			fmt.Fprintln(out, explain(best))
+		}
+	}
+	if *statsFlag {
+		st := collectStats(solns)
+		st.Elapsed, st.Nodes, st.Generated = elapsed, s.nodes.Load(), generated.Load()
+		if err := st.write(out, printer); err != nil {
+			fatalf("Failed to write stats: %v", err)
+		}
+	}
+	return status
+}
+
+// checkSolveTarget reports an error unless exactly one of target and solveAll is given, and target can be a puzzle's.
+func checkSolveTarget(target int, solveAll bool) error {
+	switch {
+	case target == 0 && !solveAll:
+		return errors.New("--target or --solve_all must be provided")
+	case target != 0 && solveAll:
+		return errors.New("only one of --target or --solve_all may be provided")
+	case !solveAll:
+		if err := validateTarget(target); err != nil {
+			return fmt.Errorf("--target invalid: %v", err)
+		}
+	}
+	return nil
+}
+
+// writeBrief writes the solutions without numbering them or the count and best solution after, for
+// --shortest_only, --smallest_only and --first_only, which only want a few. If explain is set, best
+// is described after them, as with the full output.
+func writeBrief(out io.Writer, solns []expression, best expression, p printer, explainBest bool) {
+	for _, soln := range solns {
+		fmt.Fprintf(out, "%d = %s\n", soln.Val, p.format(soln))
+	}
+	if explainBest {
+		fmt.Fprintln(out, explain(best))
+	}
+}
+
+// writeClosest writes the closest values to target that can be made from digits, with a solution for each.
+func writeClosest(out io.Writer, target int, digits []int, p printer) {
+	below, above := closestReachable(reachableValues(digits), target)
+	for _, c := range []struct {
+		label string
+		value int
+	}{{"below", below}, {"above", above}} {
+		if c.value == 0 {
+			fmt.Fprintf(out, "Nothing can be made %s %d\n", c.label, target)
+			continue
+		}
+		shortest, err := shortest(solve(c.value, digits))
+		if err != nil {
+			fatalf("Failed to solve reachable value %d: %v", c.value, err)
+		}
+		fmt.Fprintf(out, "Closest %s: %d = %s (%d away)\n", c.label, c.value, p.format(shortest), abs(c.value-target))
+	}
+}
+
+// writeAllValues writes each value up to maxValue that can be made from digits,
+// with its solution count and shortest solution.
+func writeAllValues(out io.Writer, digits []int, maxValue int, p printer, tmpl *template.Template) {
+	for _, v := range reachableValues(digits) {
+		if v > maxValue {
+			break
+		}
+		solns := solve(v, digits)
+		if tmpl != nil {
+			if err := writeTemplate(out, tmpl, makeSolveResult(v, digits, solns, p)); err != nil {
+				fatalf("Failed to execute template: %v", err)
+			}
+			continue
+		}
+		shortest, err := shortest(solns)
+		if err != nil {
+			fatalf("Failed to get shortest solution for reachable value %d: %v", v, err)
+		}
+		fmt.Fprintf(out, "%d: %d solutions, e.g. %s\n", v, len(solns), p.format(shortest))
+	}
+}
+
+// solutionCount describes how many solutions were found, and how many expressions the search built before
+// removing duplicates, if it's known.
+func solutionCount(n int, generated int64) string {
+	if generated == 0 {
+		return fmt.Sprintf("%d solutions", n)
+	}
+	return fmt.Sprintf("%d solutions, from %d expressions before removing duplicates", n, generated)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_writeBrief(t *testing.T) {
+	// This is what solve --shortest_only prints, with and without --explain.
+	best, err := shortest(solve(93, []int{5, 7, 9, 10, 15, 25}))
+	if err != nil {
+		t.Fatalf("shortest() failed unexpectedly: %v", err)
+	}
+	tests := map[string]struct {
+		explain bool
+		want    string
+	}{
+		"shortest only": {
+			want: "93 = 9*7 + 25 + 5\n",
+		},
+		"explain shortest only": {
+			explain: true,
+			want:    "93 = 9*7 + 25 + 5\nMultiply 9 by 7 to get 63, then add 25 to get 88, then add 5 to get 93.\n",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			var b bytes.Buffer
+			writeBrief(&b, []expression{best}, best, newPrinter(notationInfix, false), tt.explain)
+			if diff := cmp.Diff(tt.want, b.String()); diff != "" {
+				t.Errorf("writeBrief() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_checkSolveTarget(t *testing.T) {
+	tests := map[string]struct {
+		target   int
+		solveAll bool
+		wantErr  bool
+	}{
+		"target":          {target: 93},
+		"solve all":       {solveAll: true},
+		"neither":         {wantErr: true},
+		"both":            {target: 93, solveAll: true, wantErr: true},
+		"negative target": {target: -5, wantErr: true},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			if err := checkSolveTarget(tt.target, tt.solveAll); (err != nil) != tt.wantErr {
+				t.Errorf("checkSolveTarget(%d, %t) error = %v, wantErr %t", tt.target, tt.solveAll, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"os"
+)
+
+func runTrace(ctx context.Context, args []string) int {
+	fs := newFlagSet("trace")
+	slowest := fs.Int("slowest", 10, "How many of the slowest sub-searches to list")
+	open := addOutFlag(fs, "summary")
+	args = parseArgs(fs, args)
+
+	if len(args) != 1 {
+		usagef("Want the path of a trace written by solve --search_trace, e.g. digits trace search.jsonl")
+	}
+	if *slowest < 0 {
+		usagef("--slowest must not be negative, got %d", *slowest)
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		fatalf("Failed to open trace: %v", err)
+	}
+	defer f.Close()
+	sum, err := summarizeTrace(f, *slowest)
+	if err != nil {
+		fatalf("Failed to read trace %s: %v", args[0], err)
+	}
+	out, closeOut := open()
+	if err := sum.write(out); err != nil {
+		fatalf("Failed to write summary: %v", err)
+	}
+	closeOut()
+	return exitSolved
+}
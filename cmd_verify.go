@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func runVerify(ctx context.Context, args []string) int {
+	fs := newFlagSet("verify")
+	digitsStr := fs.String("digits", "", "A comma-separated list of the digits the answer may use")
+	target := fs.Int("target", 0, "The target the answer should make")
+	printerFlags := addPrinterFlags(fs)
+	open := addOutFlag(fs, "verdict")
+	args = parseArgs(fs, args)
+
+	digits, err := parseDigits(*digitsStr)
+	if err != nil {
+		usagef("--digits invalid: %v", err)
+	}
+	mustValidateTarget(*target)
+	if len(args) == 0 {
+		usagef("No answer given, e.g. digits verify --digits=5,7,9,25 --target=93 \"(9*7)+25+5\"")
+	}
+
+	answer := strings.Join(args, " ")
+	e, err := parseInfix(answer)
+	if err != nil {
+		usagef("Invalid answer: %v", err)
+	}
+	violations := answerViolations(e, digits, *target)
+	out, closeOut := open()
+	writeVerdict(out, e, violations, printerFlags.printer())
+	closeOut()
+	if len(violations) > 0 {
+		return exitNoSolution
+	}
+	return exitSolved
+}
+
+// writeVerdict writes whether the answer e is correct, with its value if it is, and every rule it breaks if it isn't.
+func writeVerdict(w io.Writer, e expression, violations []string, p printer) {
+	if len(violations) == 0 {
+		fmt.Fprintf(w, "Correct: %d = %s\n", e.Val, p.format(e))
+		return
+	}
+	fmt.Fprintf(w, "Incorrect: %s\n", p.format(e))
+	for _, v := range violations {
+		fmt.Fprintf(w, "  %s\n", v)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_writeVerdict(t *testing.T) {
+	digits := []int{5, 7, 9, 25}
+	tests := map[string]struct {
+		answer string
+		want   string
+	}{
+		"correct": {
+			answer: "(9*7) + 25 + 5",
+			want:   "Correct: 93 = 9*7 + 25 + 5\n",
+		},
+		"incorrect": {
+			answer: "9*9 + 7",
+			want:   "Incorrect: 9*9 + 7\n  digit 9 used twice\n  9*9 + 7 = 88, not 93\n",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			e, err := parseInfix(tt.answer)
+			if err != nil {
+				t.Fatalf("parseInfix(%q) failed unexpectedly: %v", tt.answer, err)
+			}
+			var b bytes.Buffer
+			writeVerdict(&b, e, answerViolations(e, digits, 93), newPrinter(notationInfix, false))
+			if diff := cmp.Diff(tt.want, b.String()); diff != "" {
+				t.Errorf("writeVerdict() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
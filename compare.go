@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// compareReport compares how well two sets of digits solve a range of targets.
+type compareReport struct {
+	From int         `json:"from"`
+	To   int         `json:"to"`
+	A    compareSide `json:"a"`
+	B    compareSide `json:"b"`
+	// OnlyA and OnlyB are the targets only A, or only B, can make.
+	OnlyA []int `json:"only_a"`
+	OnlyB []int `json:"only_b"`
+}
+
+// compareSide is how well one set of digits solves the targets.
+type compareSide struct {
+	Digits   []int   `json:"digits"`
+	Solvable int     `json:"solvable"`
+	Coverage float64 `json:"coverage"`
+	// MeanSolutions is the average number of distinct solutions per target, counting those with none.
+	MeanSolutions float64 `json:"mean_solutions"`
+}
+
+// compareDigits compares digits a and b, whose solutions are counted by countsA and countsB, over the targets from from to to.
+func compareDigits(a, b []int, countsA, countsB *solutionCounts, from, to int) compareReport {
+	r := compareReport{From: from, To: to, A: compareSide{Digits: a}, B: compareSide{Digits: b}, OnlyA: []int{}, OnlyB: []int{}}
+	n := to - from + 1
+	for t := from; t <= to; t++ {
+		ca, cb := countsA.totals[t], countsB.totals[t]
+		r.A.MeanSolutions += float64(ca) / float64(n)
+		r.B.MeanSolutions += float64(cb) / float64(n)
+		if ca > 0 {
+			r.A.Solvable++
+		}
+		if cb > 0 {
+			r.B.Solvable++
+		}
+		switch {
+		case ca > 0 && cb == 0:
+			r.OnlyA = append(r.OnlyA, t)
+		case cb > 0 && ca == 0:
+			r.OnlyB = append(r.OnlyB, t)
+		}
+	}
+	r.A.Coverage = float64(r.A.Solvable) / float64(n)
+	r.B.Coverage = float64(r.B.Solvable) / float64(n)
+	return r
+}
+
+func (r compareReport) writeJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// writeText describes the comparison side by side, then lists the targets only one set solves.
+func (r compareReport) writeText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Targets %d to %d:\n", r.From, r.To)
+	for _, s := range []struct {
+		name string
+		side compareSide
+	}{{"a", r.A}, {"b", r.B}} {
+		fmt.Fprintf(&b, "  %s (%s): solves %d (%.1f%%), with %.1f solutions on average\n",
+			s.name, formatDigits(s.side.Digits), s.side.Solvable, 100*s.side.Coverage, s.side.MeanSolutions)
+	}
+	switch {
+	case r.A.Solvable > r.B.Solvable:
+		fmt.Fprintf(&b, "a solves %d more targets\n", r.A.Solvable-r.B.Solvable)
+	case r.B.Solvable > r.A.Solvable:
+		fmt.Fprintf(&b, "b solves %d more targets\n", r.B.Solvable-r.A.Solvable)
+	default:
+		fmt.Fprintf(&b, "a and b solve as many targets\n")
+	}
+	for _, only := range []struct {
+		name    string
+		targets []int
+	}{{"a", r.OnlyA}, {"b", r.OnlyB}} {
+		fmt.Fprintf(&b, "Only %s solves %d targets", only.name, len(only.targets))
+		if len(only.targets) > 0 {
+			fmt.Fprintf(&b, ": %s", formatDigits(only.targets))
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// compareFormats are the ways analyze compare can write its comparison.
+var compareFormats = map[string]func(compareReport, io.Writer) error{
+	"text": compareReport.writeText,
+	"json": compareReport.writeJSON,
+}
+
+func parseCompareFormat(s string) (func(compareReport, io.Writer) error, error) {
+	f, ok := compareFormats[s]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q", s)
+	}
+	return f, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func Test_compareDigits(t *testing.T) {
+	a, b := []int{1, 3, 5, 10}, []int{2, 4, 6, 8}
+	got := compareDigits(a, b, countSolutions(nil, a), countSolutions(nil, b), 17, 21)
+	want := compareReport{
+		From:  17,
+		To:    21,
+		A:     compareSide{Digits: a, Solvable: 5, Coverage: 1},
+		B:     compareSide{Digits: b, Solvable: 3, Coverage: 0.6},
+		OnlyA: []int{17, 21},
+		OnlyB: []int{},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(compareSide{}, "MeanSolutions")); diff != "" {
+		t.Errorf("compareDigits() mismatch (-want +got):\n%s", diff)
+	}
+	// The average counts the targets with no solution as 0.
+	total := 0
+	for t := 17; t <= 21; t++ {
+		total += countSolutions(nil, b).totals[t]
+	}
+	if diff := cmp.Diff(float64(total)/5, got.B.MeanSolutions, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("compareDigits() mean solutions mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_compareReport_writeText(t *testing.T) {
+	r := compareReport{
+		From:  17,
+		To:    21,
+		A:     compareSide{Digits: []int{1, 3, 5, 10}, Solvable: 5, Coverage: 1, MeanSolutions: 7.25},
+		B:     compareSide{Digits: []int{2, 4, 6, 8}, Solvable: 3, Coverage: 0.6, MeanSolutions: 4},
+		OnlyA: []int{17, 21},
+	}
+	var b strings.Builder
+	if err := r.writeText(&b); err != nil {
+		t.Fatalf("writeText() failed unexpectedly: %v", err)
+	}
+	want := "Targets 17 to 21:\n" +
+		"  a (1,3,5,10): solves 5 (100.0%), with 7.2 solutions on average\n" +
+		"  b (2,4,6,8): solves 3 (60.0%), with 4.0 solutions on average\n" +
+		"a solves 2 more targets\n" +
+		"Only a solves 2 targets: 17,21\n" +
+		"Only b solves 0 targets\n"
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("writeText() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// configFlags are the --config and --preset flags, registered on every command by newFlagSet.
+var configFlags struct {
+	path   string
+	preset string
+}
+
+func addConfigFlags(fs *flag.FlagSet) {
+	fs.StringVar(&configFlags.path, "config", "", "Read default flag values from this TOML file, rather than ~/.config/digits/config.toml")
+	fs.StringVar(&configFlags.preset, "preset", "", "Use the flag values from this [presets.<name>] table of the config file")
+}
+
+// config holds default flag values read from a TOML file like:
+//
+//	unicode = true
+//
+//	[solve]
+//	format = "latex"
+//
+//	[presets.easy]
+//	no_division = true
+//	max_ops = 3
+//
+// Top-level values apply to every command with a flag of that name, tables named after a command
+// apply to just that command, and presets only apply when chosen with --preset.
+// Flags given on the command line always win.
+type config struct {
+	options  map[string]any
+	commands map[string]map[string]any
+	presets  map[string]map[string]any
+}
+
+func parseConfig(data string) (config, error) {
+	var raw map[string]any
+	if _, err := toml.Decode(data, &raw); err != nil {
+		return config{}, err
+	}
+
+	c := config{
+		options:  make(map[string]any),
+		commands: make(map[string]map[string]any),
+		presets:  make(map[string]map[string]any),
+	}
+	for k, v := range raw {
+		table, isTable := v.(map[string]any)
+		switch {
+		case k == "presets":
+			if !isTable {
+				return config{}, fmt.Errorf("presets must be a table of [presets.<name>] tables")
+			}
+			for name, p := range table {
+				preset, ok := p.(map[string]any)
+				if !ok {
+					return config{}, fmt.Errorf("preset %q must be a table", name)
+				}
+				c.presets[name] = preset
+			}
+		case isTable:
+			c.commands[k] = table
+		default:
+			c.options[k] = v
+		}
+	}
+	return c, nil
+}
+
+// apply sets the flags in fs which weren't given on the command line from the config for command.
+func (c config) apply(fs *flag.FlagSet, command, preset string) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	if err := c.applyLayer(fs, set, "top level", c.options, false); err != nil {
+		return err
+	}
+	// The command's own table is meant for it alone, so an unknown option is a mistake.
+	if err := c.applyLayer(fs, set, fmt.Sprintf("[%s]", command), c.commands[command], true); err != nil {
+		return err
+	}
+	if preset == "" {
+		return nil
+	}
+	return c.applyPreset(fs, set, preset)
+}
+
+// applyPreset sets the flags in fs which aren't in set from the preset's table.
+func (c config) applyPreset(fs *flag.FlagSet, set map[string]bool, preset string) error {
+	p, ok := c.presets[preset]
+	if !ok {
+		return fmt.Errorf("unknown preset %q", preset)
+	}
+	return c.applyLayer(fs, set, fmt.Sprintf("[presets.%s]", preset), p, false)
+}
+
+// applyLayer sets the flags in fs which aren't in set from values, read from the table called name.
+// Options fs doesn't have are skipped, unless strict.
+func (c config) applyLayer(fs *flag.FlagSet, set map[string]bool, name string, values map[string]any, strict bool) error {
+	for opt, v := range values {
+		if set[opt] || opt == "config" || opt == "preset" {
+			continue
+		}
+		if fs.Lookup(opt) == nil {
+			if strict {
+				return fmt.Errorf("%s has unknown option %q", name, opt)
+			}
+			continue
+		}
+		if err := fs.Set(opt, formatConfigValue(v)); err != nil {
+			return fmt.Errorf("%s option %q: %v", name, opt, err)
+		}
+	}
+	return nil
+}
+
+// formatConfigValue writes a TOML value as it would be given on the command line.
+// Arrays are written comma-separated, like --digits.
+func formatConfigValue(v any) string {
+	if vs, ok := v.([]any); ok {
+		parts := make([]string, len(vs))
+		for i, v := range vs {
+			parts[i] = fmt.Sprint(v)
+		}
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+// defaultConfigPath returns the path of the config file read when --config isn't given.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "digits", "config.toml")
+}
+
+// loadConfig reads the config file chosen by the config flags, returning its path and whether it exists.
+// A missing default config file isn't an error, but a missing --config file is.
+func loadConfig() (config, string, bool, error) {
+	path := configFlags.path
+	if path == "" {
+		path = defaultConfigPath()
+	}
+	if path == "" {
+		return config{}, "", false, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && configFlags.path == "" {
+		return config{}, path, false, nil
+	}
+	if err != nil {
+		return config{}, path, false, err
+	}
+	c, err := parseConfig(string(data))
+	if err != nil {
+		return config{}, path, false, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, path, true, nil
+}
+
+// applyConfig sets the flags in fs from the config file chosen by the config flags.
+func applyConfig(fs *flag.FlagSet) error {
+	c, path, found, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if !found {
+		if configFlags.preset != "" && path != "" {
+			return fmt.Errorf("--preset %q needs a config file, but %s doesn't exist", configFlags.preset, path)
+		}
+		return nil
+	}
+	command := strings.TrimPrefix(fs.Name(), "digits ")
+	if err := c.apply(fs, command, configFlags.preset); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
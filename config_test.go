@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testConfig = `
+unicode = true
+theme = "dark"
+
+[solve]
+notation = "rpn"
+max_ops = 4
+
+[presets.easy]
+no_division = true
+max_ops = 3
+digits = [5, 7, 9]
+`
+
+func Test_config_apply(t *testing.T) {
+	tests := map[string]struct {
+		args    []string
+		command string
+		preset  string
+		want    map[string]string
+	}{
+		"defaults": {
+			command: "solve",
+			want:    map[string]string{"unicode": "true", "notation": "rpn", "max_ops": "4", "no_division": "false", "digits": ""},
+		},
+		"other command": {
+			command: "range",
+			want:    map[string]string{"unicode": "true", "notation": "infix", "max_ops": "0", "no_division": "false", "digits": ""},
+		},
+		"preset": {
+			command: "solve",
+			preset:  "easy",
+			want:    map[string]string{"unicode": "true", "notation": "rpn", "max_ops": "3", "no_division": "true", "digits": "5,7,9"},
+		},
+		"command line wins": {
+			args:    []string{"--max_ops=6", "--unicode=false"},
+			command: "solve",
+			preset:  "easy",
+			want:    map[string]string{"unicode": "false", "notation": "rpn", "max_ops": "6", "no_division": "true", "digits": "5,7,9"},
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			c, err := parseConfig(testConfig)
+			if err != nil {
+				t.Fatalf("parseConfig() failed unexpectedly: %v", err)
+			}
+
+			fs := flag.NewFlagSet(tt.command, flag.ContinueOnError)
+			fs.String("digits", "", "")
+			addPrinterFlags(fs)
+			addFilterFlags(fs)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatalf("Parse(%q) failed unexpectedly: %v", tt.args, err)
+			}
+			if err := c.apply(fs, tt.command, tt.preset); err != nil {
+				t.Fatalf("apply() failed unexpectedly: %v", err)
+			}
+
+			got := make(map[string]string)
+			for name := range tt.want {
+				got[name] = fs.Lookup(name).Value.String()
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("flags mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_config_apply_errors(t *testing.T) {
+	tests := map[string]struct {
+		config string
+		preset string
+		want   string
+	}{
+		"unknown preset": {
+			config: testConfig,
+			preset: "hard",
+			want:   `unknown preset "hard"`,
+		},
+		"unknown command option": {
+			config: "[solve]\nnotaton = \"rpn\"\n",
+			want:   `[solve] has unknown option "notaton"`,
+		},
+		"invalid value": {
+			config: "max_ops = \"lots\"\n",
+			want:   `top level option "max_ops": parse error`,
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			c, err := parseConfig(tt.config)
+			if err != nil {
+				t.Fatalf("parseConfig() failed unexpectedly: %v", err)
+			}
+			fs := flag.NewFlagSet("solve", flag.ContinueOnError)
+			addPrinterFlags(fs)
+			addFilterFlags(fs)
+
+			got := ""
+			if err := c.apply(fs, "solve", tt.preset); err != nil {
+				got = err.Error()
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"math/bits"
+	"slices"
+	"sort"
+)
+
+// solutionCounts holds how many distinct solutions make each value from some digits. They're counted
+// with a dynamic program over the subsets of the digits, rather than by finding each solution.
+//
+// Solutions are distinct if they differ other than by reordering or regrouping sums and products:
+// a sum is a set of terms, each added or subtracted, and a product is a set of factors, each multiplied
+// or divided, so 9 - (5 - 2) is the same solution as 9 + 2 - 5, and 6 / 4 * 2 the same as 6 * 2 / 4.
+// Solutions which only swap copies of a repeated digit are the same solution too.
+type solutionCounts struct {
+	// totals maps each value to the number of solutions for it, using any of the digits.
+	totals map[int]int
+	// labeled is totals with the copies of a repeated digit told apart, as the tables below do.
+	// It's the same as totals if no digit is repeated.
+	labeled map[int]int
+	// incomplete reports whether counting was cancelled or timed out, so the counts are missing.
+	incomplete bool
+
+	// The tables below are indexed by the mask of the digits used, and are kept for sampling solutions.
+	digits []int
+	// terms counts the expressions which aren't sums, which can be terms of a sum, by value;
+	// factors counts those which aren't products, which can be factors of a product;
+	// sums counts the sets of one or more terms, each added or subtracted, by their total, which may not be positive;
+	// products counts the sets of one or more factors, each multiplied or divided, by their product.
+	// sums and products aren't kept for the full set of digits, as it can't be part of a bigger sum or product.
+	terms, factors []map[int]int
+	sums           []map[int]int
+	products       []map[fraction]int
+}
+
+// fraction is a product of factors, some of which divide: num / den in lowest terms.
+type fraction struct {
+	num, den int
+	// hasNum reports whether any factor multiplies, as a product can't only divide.
+	hasNum bool
+}
+
+// countSolutions counts the solutions for every value which can be made from digits, stopping early if ctx is done.
+// The tables tell the copies of a repeated digit apart, so if there are any, countDistinct counts them again without.
+func countSolutions(ctx context.Context, digits []int) *solutionCounts {
+	n := len(digits)
+	full := 1<<n - 1
+	masks := make([]int, 0, full)
+	for mask := 1; mask <= full; mask++ {
+		masks = append(masks, mask)
+	}
+	sort.SliceStable(masks, func(i, j int) bool {
+		return bits.OnesCount(uint(masks[i])) < bits.OnesCount(uint(masks[j]))
+	})
+
+	c := &solutionCounts{
+		labeled:  make(map[int]int),
+		digits:   digits,
+		terms:    make([]map[int]int, full+1),
+		factors:  make([]map[int]int, full+1),
+		sums:     make([]map[int]int, full+1),
+		products: make([]map[fraction]int, full+1),
+	}
+	terms, factors, sums, products := c.terms, c.factors, c.sums, c.products
+	for _, mask := range masks {
+		if ctx != nil && ctx.Err() != nil {
+			return &solutionCounts{incomplete: true}
+		}
+		t := make(map[int]int)
+		f := make(map[int]int)
+		if bits.OnesCount(uint(mask)) == 1 {
+			d := digits[bits.TrailingZeros(uint(mask))]
+			t[d]++
+			f[d]++
+			c.labeled[d]++
+		}
+
+		// Sums and products of two or more parts: one part holds the lowest digit, so each set is counted once.
+		low := mask & -mask
+		multiSums := make(map[int]int)
+		multiProducts := make(map[fraction]int)
+		for part := (mask - 1) & mask; part > 0; part = (part - 1) & mask {
+			if part&low == 0 {
+				continue
+			}
+			rest := mask ^ part
+			for w, nw := range terms[part] {
+				for v, nv := range sums[rest] {
+					multiSums[v+w] += nw * nv
+					multiSums[v-w] += nw * nv
+				}
+			}
+			for w, nw := range factors[part] {
+				for p, np := range products[rest] {
+					if q, ok := p.times(fraction{num: w, den: 1, hasNum: true}); ok {
+						multiProducts[q] += nw * np
+					}
+					if q, ok := p.times(fraction{num: 1, den: w}); ok {
+						multiProducts[q] += nw * np
+					}
+				}
+			}
+		}
+		for v, nv := range multiSums {
+			if v > 0 {
+				f[v] += nv
+				c.labeled[v] += nv
+			}
+		}
+		for p, np := range multiProducts {
+			if p.den == 1 && p.hasNum {
+				t[p.num] += np
+				c.labeled[p.num] += np
+			}
+		}
+		terms[mask], factors[mask] = t, f
+
+		// Every digit is used by then, so the full set is never part of a bigger sum or product.
+		if mask == full {
+			break
+		}
+		for w, nw := range t {
+			multiSums[w] += nw
+			multiSums[-w] += nw
+		}
+		for w, nw := range f {
+			multiProducts[fraction{num: w, den: 1, hasNum: true}] += nw
+			multiProducts[fraction{num: 1, den: w}] += nw
+		}
+		sums[mask], products[mask] = multiSums, multiProducts
+	}
+	c.totals = c.labeled
+	if len(countValues(digits)) < len(digits) {
+		if c.totals = countDistinct(ctx, digits); c.totals == nil {
+			return &solutionCounts{incomplete: true}
+		}
+	}
+	return c
+}
+
+// times returns the product of p and q in lowest terms, or false if it's too big to represent.
+func (p fraction) times(q fraction) (fraction, bool) {
+	num, ok := mulInts(p.num, q.num)
+	if !ok {
+		return fraction{}, false
+	}
+	den, ok := mulInts(p.den, q.den)
+	if !ok {
+		return fraction{}, false
+	}
+	g := gcd(num, den)
+	return fraction{num: num / g, den: den / g, hasNum: p.hasNum || q.hasNum}, true
+}
+
+// mulInts multiplies positive a and b, reporting false if the result overflows.
+func mulInts(a, b int) (int, bool) {
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	if hi != 0 || lo > 1<<62 {
+		return 0, false
+	}
+	return int(lo), true
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// countValues returns the distinct values in digits in ascending order, with how many times each appears.
+func countValues(digits []int) []valueCount {
+	var out []valueCount
+	sorted := slices.Clone(digits)
+	slices.Sort(sorted)
+	for _, d := range sorted {
+		if len(out) > 0 && out[len(out)-1].value == d {
+			out[len(out)-1].n++
+		} else {
+			out = append(out, valueCount{value: d, n: 1})
+		}
+	}
+	return out
+}
+
+type valueCount struct {
+	value, n int
+}
+
+// countDistinct counts the solutions for every value which can be made from digits, like countSolutions,
+// but over the sub-multisets of the digits rather than their subsets, so copies of a repeated digit are
+// never told apart. It returns nil if ctx is done first.
+//
+// A sum is a multiset of terms. They're counted by adding the terms made from each sub-multiset in turn,
+// any number of times: k of the n different terms with one value can be chosen C(n+k-1, k) ways. Products are
+// counted the same way from factors. Sub-multisets are taken smallest first, so a sum of two or more terms
+// from a sub-multiset is counted before the sub-multiset's own terms are added.
+func countDistinct(ctx context.Context, digits []int) map[int]int {
+	values := countValues(digits)
+	// A sub-multiset is numbered by how many copies of each value it has, in mixed radix.
+	strides := make([]int, len(values))
+	size := 1
+	for i, v := range values {
+		strides[i] = size
+		size *= v.n + 1
+	}
+	copies := func(m, i int) int { return m / strides[i] % (values[i].n + 1) }
+	// contains reports whether m has at least k times the copies of s.
+	contains := func(m, s, k int) bool {
+		for i := range values {
+			if copies(m, i) < k*copies(s, i) {
+				return false
+			}
+		}
+		return true
+	}
+	ms := make([]int, size)
+	for m := range ms {
+		ms[m] = m
+	}
+	count := func(m int) int {
+		n := 0
+		for i := range values {
+			n += copies(m, i)
+		}
+		return n
+	}
+	sort.SliceStable(ms, func(i, j int) bool { return count(ms[i]) < count(ms[j]) })
+
+	totals := make(map[int]int)
+	terms := make([]map[int]int, size)
+	factors := make([]map[int]int, size)
+	sums := make([]map[int]int, size)
+	products := make([]map[fraction]int, size)
+	for m := range sums {
+		sums[m] = make(map[int]int)
+		products[m] = make(map[fraction]int)
+	}
+	sums[0][0] = 1
+	products[0][fraction{num: 1, den: 1}] = 1
+
+	for lo := 1; lo < len(ms); {
+		hi := lo
+		for hi < len(ms) && count(ms[hi]) == count(ms[lo]) {
+			hi++
+		}
+		// Every sum or product of two or more parts from these sub-multisets has been counted, so their terms and factors are known.
+		for _, m := range ms[lo:hi] {
+			if ctx != nil && ctx.Err() != nil {
+				return nil
+			}
+			t := make(map[int]int)
+			f := make(map[int]int)
+			if count(m) == 1 {
+				var d int
+				for i := range values {
+					if m == strides[i] {
+						d = values[i].value
+					}
+				}
+				t[d]++
+				f[d]++
+				totals[d]++
+			}
+			for v, nv := range sums[m] {
+				if v > 0 {
+					f[v] += nv
+					totals[v] += nv
+				}
+			}
+			for p, np := range products[m] {
+				if p.den == 1 && p.hasNum {
+					t[p.num] += np
+					totals[p.num] += np
+				}
+			}
+			terms[m], factors[m] = t, f
+		}
+		// Then add them as parts of the sums and products of the bigger sub-multisets.
+		for _, s := range ms[lo:hi] {
+			if ctx != nil && ctx.Err() != nil {
+				return nil
+			}
+			for w, nw := range terms[s] {
+				for _, sign := range []int{1, -1} {
+					// Bigger sub-multisets are numbered higher, so going down reads each smaller one before it's changed.
+					for m := size - 1; m >= s; m-- {
+						if !contains(m, s, 1) {
+							continue
+						}
+						ways := 1
+						for k := 1; contains(m, s, k); k++ {
+							ways = ways * (nw + k - 1) / k
+							for v, nv := range sums[m-k*s] {
+								sums[m][v+k*sign*w] += ways * nv
+							}
+						}
+					}
+				}
+			}
+			for w, nw := range factors[s] {
+				for _, part := range []fraction{{num: w, den: 1, hasNum: true}, {num: 1, den: w}} {
+					for m := size - 1; m >= s; m-- {
+						if !contains(m, s, 1) {
+							continue
+						}
+						ways := 1
+						power := fraction{num: 1, den: 1}
+						for k := 1; contains(m, s, k); k++ {
+							ways = ways * (nw + k - 1) / k
+							var ok bool
+							if power, ok = power.times(part); !ok {
+								break
+							}
+							for p, np := range products[m-k*s] {
+								if q, ok := p.times(power); ok {
+									products[m][q] += ways * np
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+		lo = hi
+	}
+	return totals
+}
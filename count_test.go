@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// normalForm writes e with its sums and products flattened and their parts sorted, so solutions which
+// countSolutions counts as the same are written the same way.
+func normalForm(e expression) string {
+	var parts []string
+	var kind string
+	switch e.Op {
+	case opNone:
+		return strconv.Itoa(e.Val)
+	case opAdd, opNegate:
+		kind = "sum"
+		var walk func(e expression, sign string)
+		walk = func(e expression, sign string) {
+			switch e.Op {
+			case opAdd:
+				for _, c := range e.Children {
+					walk(*c, sign)
+				}
+			case opNegate:
+				walk(*e.Children[0], map[string]string{"+": "-", "-": "+"}[sign])
+			default:
+				parts = append(parts, sign+normalForm(e))
+			}
+		}
+		walk(e, "+")
+	default:
+		kind = "product"
+		var walk func(e expression, op string)
+		walk = func(e expression, op string) {
+			switch e.Op {
+			case opMultiply:
+				for _, c := range e.Children {
+					walk(*c, op)
+				}
+			case opDivide:
+				walk(*e.Children[0], op)
+				walk(*e.Children[1], map[string]string{"*": "/", "/": "*"}[op])
+			default:
+				parts = append(parts, op+normalForm(e))
+			}
+		}
+		walk(e, "*")
+	}
+	slices.Sort(parts)
+	return kind + "(" + strings.Join(parts, " ") + ")"
+}
+
+// countByEnumeration counts the distinct solutions for each value by combining pairs of digits in every order.
+func countByEnumeration(digits []int) map[int]int {
+	seen := make(map[string]int)
+	var combine func(exprs []expression)
+	combine = func(exprs []expression) {
+		for i := range exprs {
+			for j := range exprs {
+				if i == j {
+					continue
+				}
+				a, b := exprs[i], exprs[j]
+				var made []expression
+				if i < j {
+					made = append(made, makeAdd(a, b), makeMultiply(a, b))
+				}
+				if a.Val > b.Val {
+					made = append(made, makeAdd(a, makeNegate(b)))
+				}
+				if a.Val%b.Val == 0 {
+					made = append(made, makeDivide(a, b))
+				}
+				for _, e := range made {
+					seen[normalForm(e)] = e.Val
+					rest := []expression{e}
+					for k, x := range exprs {
+						if k != i && k != j {
+							rest = append(rest, x)
+						}
+					}
+					combine(rest)
+				}
+			}
+		}
+	}
+	var exprs []expression
+	for _, d := range digits {
+		exprs = append(exprs, makeConstant(d))
+		seen[strconv.Itoa(d)] = d
+	}
+	combine(exprs)
+
+	counts := make(map[int]int)
+	for _, v := range seen {
+		counts[v]++
+	}
+	return counts
+}
+
+func Test_countSolutions(t *testing.T) {
+	tests := map[string][]int{
+		"two digits":   {3, 6},
+		"four digits":  {2, 3, 5, 7},
+		"with one":     {1, 2, 4, 8},
+		"five digits":  {2, 3, 5, 7, 10},
+		"large digits": {25, 50, 3, 8},
+		// Solutions which only swap copies of a digit are the same.
+		"repeated digit":  {2, 2, 3},
+		"repeated pairs":  {5, 5, 7, 7},
+		"repeated thrice": {2, 2, 2, 3},
+		"mixed repeats":   {1, 1, 4, 6, 6},
+	}
+	for tn, digits := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := countSolutions(context.Background(), digits)
+			if got.incomplete {
+				t.Errorf("countSolutions() reported incomplete results")
+			}
+			if diff := cmp.Diff(countByEnumeration(digits), got.totals); diff != "" {
+				t.Errorf("countSolutions() mismatch (-enumerated +counted):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_countSolutions_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := countSolutions(ctx, []int{2, 3, 5, 7}); !got.incomplete {
+		t.Errorf("countSolutions() after cancellation wasn't incomplete")
+	}
+}
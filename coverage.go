@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// coverageReport says which targets in a range can be made from some digits, for plotting.
+type coverageReport struct {
+	Digits []int `json:"digits"`
+	From   int   `json:"from"`
+	To     int   `json:"to"`
+	// Solvable has an entry for each target from From to To, saying whether it can be made.
+	Solvable []bool          `json:"solvable"`
+	Summary  coverageSummary `json:"summary"`
+}
+
+// coverageSummary sums up a coverageReport.
+type coverageSummary struct {
+	Solvable   int `json:"solvable"`
+	Unsolvable int `json:"unsolvable"`
+	// Coverage is the fraction of the targets which can be made.
+	Coverage float64 `json:"coverage"`
+	// FirstUnsolvable is the smallest target which can't be made, or 0 if they all can.
+	FirstUnsolvable int `json:"first_unsolvable,omitempty"`
+	// LongestRun and LongestGap are the most targets in a row which can, and can't, be made, starting at the targets given.
+	LongestRun      int `json:"longest_run"`
+	LongestRunStart int `json:"longest_run_start,omitempty"`
+	LongestGap      int `json:"longest_gap"`
+	LongestGapStart int `json:"longest_gap_start,omitempty"`
+}
+
+// analyzeCoverage reports which targets from from to to are in reachable, which must be sorted.
+func analyzeCoverage(digits, reachable []int, from, to int) coverageReport {
+	r := coverageReport{Digits: digits, From: from, To: to, Solvable: make([]bool, to-from+1)}
+	sum := &r.Summary
+	run, gap := 0, 0
+	for i := range r.Solvable {
+		t := from + i
+		j := sort.SearchInts(reachable, t)
+		r.Solvable[i] = j < len(reachable) && reachable[j] == t
+		if r.Solvable[i] {
+			sum.Solvable++
+			run, gap = run+1, 0
+			if run > sum.LongestRun {
+				sum.LongestRun, sum.LongestRunStart = run, t-run+1
+			}
+			continue
+		}
+		sum.Unsolvable++
+		if sum.FirstUnsolvable == 0 {
+			sum.FirstUnsolvable = t
+		}
+		run, gap = 0, gap+1
+		if gap > sum.LongestGap {
+			sum.LongestGap, sum.LongestGapStart = gap, t-gap+1
+		}
+	}
+	sum.Coverage = coverageFraction(reachable, from, to)
+	return r
+}
+
+// coverageFraction returns the fraction of the targets from from to to which are in reachable, which must be sorted.
+// It only searches reachable for the ends of the range, so it's quick enough to score many sets of digits.
+func coverageFraction(reachable []int, from, to int) float64 {
+	n := sort.SearchInts(reachable, to+1) - sort.SearchInts(reachable, from)
+	return float64(n) / float64(to-from+1)
+}
+
+func (r coverageReport) writeJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// writeCSV writes a row for each target, saying whether it can be made, after a header.
+func (r coverageReport) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"target", "solvable"})
+	for i, ok := range r.Solvable {
+		cw.Write([]string{strconv.Itoa(r.From + i), strconv.FormatBool(ok)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeFraction writes just the fraction of the targets which can be made, for scripts scoring digits.
+func (r coverageReport) writeFraction(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "%.4f\n", r.Summary.Coverage)
+	return err
+}
+
+// coverageFormats are the ways analyze coverage can write its report.
+var coverageFormats = map[string]func(coverageReport, io.Writer) error{
+	"json":     coverageReport.writeJSON,
+	"csv":      coverageReport.writeCSV,
+	"fraction": coverageReport.writeFraction,
+}
+
+func parseCoverageFormat(s string) (func(coverageReport, io.Writer) error, error) {
+	f, ok := coverageFormats[s]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q", s)
+	}
+	return f, nil
+}
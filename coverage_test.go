@@ -0,0 +1,116 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_analyzeCoverage(t *testing.T) {
+	tests := map[string]struct {
+		reachable []int
+		from, to  int
+		want      coverageReport
+	}{
+		"gaps": {
+			reachable: []int{1, 2, 3, 5, 6, 9, 12},
+			from:      2,
+			to:        10,
+			want: coverageReport{
+				From:     2,
+				To:       10,
+				Solvable: []bool{true, true, false, true, true, false, false, true, false},
+				Summary: coverageSummary{
+					Solvable: 5, Unsolvable: 4, Coverage: 5.0 / 9, FirstUnsolvable: 4,
+					LongestRun: 2, LongestRunStart: 2, LongestGap: 2, LongestGapStart: 7,
+				},
+			},
+		},
+		"all solvable": {
+			reachable: []int{1, 2, 3},
+			from:      1,
+			to:        3,
+			want: coverageReport{
+				From:     1,
+				To:       3,
+				Solvable: []bool{true, true, true},
+				Summary:  coverageSummary{Solvable: 3, Coverage: 1, LongestRun: 3, LongestRunStart: 1},
+			},
+		},
+		"none solvable": {
+			from: 100,
+			to:   101,
+			want: coverageReport{
+				From:     100,
+				To:       101,
+				Solvable: []bool{false, false},
+				Summary:  coverageSummary{Unsolvable: 2, FirstUnsolvable: 100, LongestGap: 2, LongestGapStart: 100},
+			},
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := analyzeCoverage(nil, tt.reachable, tt.from, tt.to)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("analyzeCoverage() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_coverageReport_write(t *testing.T) {
+	r := analyzeCoverage([]int{2, 3}, []int{2, 3, 5, 6}, 4, 6)
+	tests := map[string]struct {
+		format string
+		want   string
+	}{
+		"json": {
+			format: "json",
+			want:   `{"digits":[2,3],"from":4,"to":6,"solvable":[false,true,true],"summary":{"solvable":2,"unsolvable":1,"coverage":0.6666666666666666,"first_unsolvable":4,"longest_run":2,"longest_run_start":5,"longest_gap":1,"longest_gap_start":4}}` + "\n",
+		},
+		"csv": {
+			format: "csv",
+			want:   "target,solvable\n4,false\n5,true\n6,true\n",
+		},
+		"fraction": {
+			format: "fraction",
+			want:   "0.6667\n",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			write, err := parseCoverageFormat(tt.format)
+			if err != nil {
+				t.Fatalf("parseCoverageFormat() failed unexpectedly: %v", err)
+			}
+			var b strings.Builder
+			if err := write(r, &b); err != nil {
+				t.Fatalf("write() failed unexpectedly: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, b.String()); diff != "" {
+				t.Errorf("write() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_coverageFraction(t *testing.T) {
+	reachable := []int{1, 2, 3, 5, 6, 9, 12}
+	tests := map[string]struct {
+		from, to int
+		want     float64
+	}{
+		"all":           {from: 1, to: 3, want: 1},
+		"some":          {from: 2, to: 9, want: 5.0 / 8},
+		"none":          {from: 13, to: 20, want: 0},
+		"single target": {from: 12, to: 12, want: 1},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, coverageFraction(reachable, tt.from, tt.to)); diff != "" {
+				t.Errorf("coverageFraction() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// dedupMode chooses when two solutions count as the same, so only the first is kept.
+type dedupMode int
+
+const (
+	// dedupStructure keeps every solution which is written differently once normalized, which is what the solvers find.
+	dedupStructure dedupMode = iota
+	// dedupIntermediates also treats solutions which work out the same values as the same, even if they take the steps
+	// in a different order or combine different numbers, as they feel the same to a player.
+	dedupIntermediates
+)
+
+var dedupModeNames = map[string]dedupMode{
+	"structure":     dedupStructure,
+	"intermediates": dedupIntermediates,
+}
+
+func parseDedupMode(s string) (dedupMode, error) {
+	m, ok := dedupModeNames[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown dedup mode %q", s)
+	}
+	return m, nil
+}
+
+// dedup returns solns without those which mode counts as the same as one before them.
+func (mode dedupMode) dedup(solns []expression) []expression {
+	if mode == dedupStructure {
+		return solns
+	}
+	seen := make(map[string]bool)
+	var out []expression
+	for _, e := range solns {
+		key := e.fingerprint()
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// fingerprint returns the values e's steps work out, smallest first, separated by commas.
+// A single digit has no steps, so it's the digit itself.
+func (e expression) fingerprint() string {
+	steps := e.steps()
+	if len(steps) == 0 {
+		return strconv.Itoa(e.Val)
+	}
+	values := make([]int, len(steps))
+	for i, st := range steps {
+		values[i] = st.Result
+	}
+	sort.Ints(values)
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = strconv.Itoa(v)
+	}
+	return strings.Join(strs, ",")
+}
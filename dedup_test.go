@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_dedupMode_dedup(t *testing.T) {
+	var solns []expression
+	for _, s := range []string{"10*(15 - 5) - 7", "9*7 + 25 + 5", "10*(25 - 15) - 7", "(9 + 25/(15 - 10))*7 - 5", "25"} {
+		e, err := parseInfix(s)
+		if err != nil {
+			t.Fatalf("parseInfix(%q) failed unexpectedly: %v", s, err)
+		}
+		solns = append(solns, e)
+	}
+	p := newPrinter(notationInfix, false)
+
+	tests := map[string]struct {
+		mode dedupMode
+		want []string
+	}{
+		"structure": {
+			mode: dedupStructure,
+			want: []string{"10*(15 - 5) - 7", "9*7 + 25 + 5", "10*(25 - 15) - 7", "(9 + 25/(15 - 10))*7 - 5", "25"},
+		},
+		"intermediates": {
+			mode: dedupIntermediates,
+			want: []string{"10*(15 - 5) - 7", "9*7 + 25 + 5", "(9 + 25/(15 - 10))*7 - 5", "25"},
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			var got []string
+			for _, e := range tt.mode.dedup(solns) {
+				got = append(got, p.format(e))
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("dedup() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_expression_fingerprint(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		want  string
+	}{
+		"digit":       {input: "25", want: "25"},
+		"one step":    {input: "9*7", want: "63"},
+		"sorted":      {input: "9*7 + 25 + 5", want: "63,88,93"},
+		"repeated":    {input: "(5 + 5)*(5 + 5)", want: "10,10,100"},
+		"subtraction": {input: "10*(15 - 5) - 7", want: "10,93,100"},
+		"division":    {input: "(7 + 25/5)*9", want: "5,12,108"},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			e, err := parseInfix(tt.input)
+			if err != nil {
+				t.Fatalf("parseInfix() failed unexpectedly: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, e.fingerprint()); diff != "" {
+				t.Errorf("fingerprint() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,36 +1,64 @@
 package main
 
 import (
-	"flag"
+	"cmp"
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
+	"math/bits"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
-
-	"golang.org/x/exp/slices"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
-var (
-	digitsStr = flag.String("digits", "", "A comma-separated list of digits")
-
-	targetRange = flag.String("target_range", "", "The target range to produce solutions for (inclusive)")
-	target      = flag.Int("target", 0, "The exact target value to solve for")
-)
+// maxDigitCount bounds how many digits can be given, as the search grows factorially with them.
+const maxDigitCount = 10
 
+// parseDigits parses a comma-separated list of digits, e.g. "5,7,9".
+// Errors say which entry is wrong, counting from 1.
 func parseDigits(s string) ([]int, error) {
 	parts := strings.Split(s, ",")
+	if len(parts) > maxDigitCount {
+		return nil, fmt.Errorf("too many digits: got %d, but at most %d can be searched", len(parts), maxDigitCount)
+	}
 
 	r := make([]int, len(parts))
 	for i, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			return nil, fmt.Errorf("entry %d is empty", i+1)
+		}
 		v, err := strconv.Atoi(p)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("entry %d (%q) is not a whole number", i+1, p)
+		}
+		if v <= 0 {
+			return nil, fmt.Errorf("entry %d (%d) must be positive", i+1, v)
 		}
 		r[i] = v
 	}
 	return r, nil
 }
 
+// parseDigitArgs returns the digits given either as a comma-separated --digits value,
+// or as positional arguments like "5 7 9 10 15 25".
+func parseDigitArgs(flagValue string, args []string) ([]int, error) {
+	switch {
+	case flagValue != "" && len(args) > 0:
+		return nil, fmt.Errorf("digits given both with --digits and as arguments %q", strings.Join(args, " "))
+	case flagValue != "":
+		return parseDigits(flagValue)
+	case len(args) > 0:
+		return parseDigits(strings.Join(args, ","))
+	}
+	return nil, fmt.Errorf("no digits given")
+}
+
 func parseTargetRange(s string) (int, int, error) {
 	parts := strings.Split(s, ",")
 	if len(parts) != 2 {
@@ -46,11 +74,11 @@ func parseTargetRange(s string) (int, int, error) {
 		return 0, 0, fmt.Errorf("error parsing %q: %v", parts[1], err)
 	}
 
-	if min <= 0 {
-		return 0, 0, fmt.Errorf("range lower bound must be positive, got %d", min)
+	if err := validateTarget(min); err != nil {
+		return 0, 0, fmt.Errorf("range lower bound: %v", err)
 	}
-	if max <= 0 {
-		return 0, 0, fmt.Errorf("range upper bound must be positive, got %d", max)
+	if err := validateTarget(max); err != nil {
+		return 0, 0, fmt.Errorf("range upper bound: %v", err)
 	}
 
 	// Just flip inverted ranges.
@@ -149,23 +177,7 @@ func makeDivide(a, b expression) expression {
 }
 
 func (e expression) String() string {
-	if e.Op == opNone {
-		return fmt.Sprintf("%d", e.Val)
-	}
-
-	if e.Op == opNegate {
-		if len(e.Children) != 1 {
-			panic(fmt.Sprintf("want 1 operand for negate, got %d", len(e.Children)))
-		}
-		return fmt.Sprintf("-%s", e.Children[0].String())
-	}
-
-	children := make([]string, len(e.Children))
-	for i, c := range e.Children {
-		children[i] = c.String()
-	}
-
-	return fmt.Sprintf("(%s)", strings.Join(children, fmt.Sprintf(" %s ", e.Op.String())))
+	return formatFull(e, opStrings)
 }
 
 func (e expression) eval() (int, bool) {
@@ -202,36 +214,149 @@ func (e expression) eval() (int, bool) {
 	return val, true
 }
 
-// fuse merges nested expressions like (a + (b + c)) into (a + b + c)
+// fuse merges nested sums and products, so solutions which only regroup them are written the same way.
+// Sums are flattened through negation and subtraction, so a - (b - c) becomes a + c - b, and a chain like
+// a - b - c becomes a single sum a + -b + -c. Products are flattened through division,
+// so a / b / c becomes a / (b * c) and a / (b / c) becomes (a * c) / b. A product with divisors is written
+// as the product of the multipliers divided by the product of the divisors, each sorted like canonicalize.
 func (e expression) fuse() expression {
-	// TODO: we can do this for opSubtract and opDiv too, but we need to make sure first element stays the same.
-	// Or, we could represent subtraction as addition over negated values?
-	if !e.Op.commutative() {
-		return e
+	return e.fuseWith(nil)
+}
+
+// fuseWith is fuse, taking the nodes and operand lists it makes from arena.
+func (e expression) fuseWith(arena *exprArena) expression {
+	switch e.Op {
+	case opAdd, opSubtract:
+		return e.fuseSum(arena)
+	case opMultiply, opDivide:
+		return e.fuseProduct(arena)
 	}
+	return e
+}
 
-	newChildren := make([]*expression, 0, len(e.Children))
-	for _, c := range e.Children {
-		if c.Op != e.Op {
-			newChildren = append(newChildren, c)
-		} else {
-			newChildren = append(newChildren, c.Children...)
+func (e expression) fuseSum(arena *exprArena) expression {
+	n := 0
+	var count func(c *expression)
+	count = func(c *expression) {
+		switch c.Op {
+		case opAdd, opSubtract:
+			for _, gc := range c.Children {
+				count(gc)
+			}
+		case opNegate:
+			count(c.Children[0])
+		default:
+			n++
 		}
 	}
-
-	e.Children = newChildren
+	count(&e)
+	terms := arena.children(n)[:0]
+	var add func(c *expression, negated bool)
+	add = func(c *expression, negated bool) {
+		switch {
+		case c.Op == opAdd:
+			for _, gc := range c.Children {
+				add(gc, negated)
+			}
+		case c.Op == opSubtract:
+			// Every operand after the first is subtracted.
+			for i, gc := range c.Children {
+				add(gc, negated != (i > 0))
+			}
+		case c.Op == opNegate:
+			add(c.Children[0], !negated)
+		case negated:
+			operand := arena.children(1)
+			operand[0] = c
+			terms = append(terms, arena.node(expression{Val: -c.Val, Op: opNegate, Children: operand}))
+		default:
+			terms = append(terms, c)
+		}
+	}
+	add(&e, false)
+	e.Op = opAdd
+	e.Children = terms
 	return e
 }
 
-// canonicalize ensures commutative operations are always expressed consistently (lowest operand first).
+func (e expression) fuseProduct(arena *exprArena) expression {
+	var nums, dens []*expression
+	var add func(c *expression, divides bool)
+	add = func(c *expression, divides bool) {
+		switch {
+		case c.Op == opMultiply:
+			for _, gc := range c.Children {
+				add(gc, divides)
+			}
+		case c.Op == opDivide:
+			for i, gc := range c.Children {
+				add(gc, divides != (i > 0))
+			}
+		case divides:
+			dens = append(dens, c)
+		default:
+			nums = append(nums, c)
+		}
+	}
+	add(&e, false)
+	if len(dens) == 0 {
+		e.Op = opMultiply
+		e.Children = arena.children(len(nums))
+		copy(e.Children, nums)
+		return e
+	}
+	children := arena.children(2)
+	children[0], children[1] = arena.product(nums), arena.product(dens)
+	return expression{Val: e.Val, Op: opDivide, Children: children}
+}
+
+// product returns a node for the product of factors, which is the factor itself if there's only one.
+// The factors are sorted like canonicalize.
+func (a *exprArena) product(factors []*expression) *expression {
+	if len(factors) == 1 {
+		return factors[0]
+	}
+	c := a.children(len(factors))
+	copy(c, factors)
+	slices.SortFunc(c, compareExprs)
+	val := 1
+	for _, f := range factors {
+		val *= f.Val
+	}
+	return a.node(expression{Val: val, Op: opMultiply, Children: c})
+}
+
+// canonicalize ensures commutative operations are always expressed consistently, ordering their operands with compareExprs.
 func (e expression) canonicalize() expression {
-	// Sort operands by magnitude (largest to smallest).
 	if e.Op.commutative() {
-		slices.SortFunc(e.Children, func(a, b *expression) bool { return abs(a.Val) > abs(b.Val) })
+		slices.SortFunc(e.Children, compareExprs)
 	}
 	return e
 }
 
+// compareExprs orders expressions by magnitude, largest first, then by their structure, so expressions
+// are only tied if they're written the same way.
+func compareExprs(a, b *expression) int {
+	if c := cmp.Compare(abs(b.Val), abs(a.Val)); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Op, b.Op); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(b.Val, a.Val); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(len(a.Children), len(b.Children)); c != 0 {
+		return c
+	}
+	for i := range a.Children {
+		if c := compareExprs(a.Children[i], b.Children[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
 func abs(x int) int {
 	if x < 0 {
 		return -x
@@ -239,148 +364,561 @@ func abs(x int) int {
 	return x
 }
 
+// solver searches for solutions. The zero value finds every solution.
+//
+// A solver is safe to use from many goroutines at once, so one configured solver can serve concurrent callers.
+// Its methods take it by value, each search has its own arena and memo, and what it shares between searches
+// is either safe for concurrent use (cache, nodes and budget) or never changed once built (subsets).
+// Solutions may be shared with other searches through cache, so callers mustn't modify them.
+type solver struct {
+	// limit stops the search once this many solutions have been found, if positive.
+	limit int
+	// filter drops solutions before they count towards the limit.
+	filter solutionFilter
+	// ctx, if set, stops the search once it's done, returning the solutions found so far.
+	ctx context.Context
+	// nodes, if set, counts the sub-problems searched.
+	nodes *atomic.Int64
+	// generated, if set, counts the expressions the top-level search builds, before they're normalized,
+	// filtered and deduplicated, so it can be compared with the number of solutions.
+	generated *atomic.Int64
+	// budget, if set, stops the search once it's searched that many sub-problems, like a timeout.
+	budget *nodeBudget
+	// log, if set, gets debug logs of the top-level search's pruning decisions.
+	log *slog.Logger
+	// memo, if set, caches the solutions of sub-searches. It's created by the top-level search, or taken from cache.
+	memo *searchMemo
+	// cache, if set, shares the sub-searches of every top-level search of the same digits.
+	cache *searchCache
+	// pool is the top-level search's digits in ascending order, which searches choose from by bitmask.
+	pool []int
+	// largest, if set, holds the biggest value each mask of the pool can make, so sub-searches for more are skipped.
+	largest []int
+	// maxIntermediate, if positive, skips sub-searches for values bigger than it, so no solution works out more along the way.
+	maxIntermediate int
+	// trace, if set, records the search's branches, prunes and sub-searches, to see where it spent its time.
+	trace *searchTracer
+	// arena allocates the search's expressions. Each search, and each goroutine of a parallel search, has its own.
+	arena *exprArena
+	// counted, if set, makes the top-level search collect the hashes of its solutions here rather than keep them.
+	counted map[uint64]bool
+	// parallelism, if more than 1, spreads the top-level search's digit choices across this many goroutines.
+	parallelism int
+	// engine chooses how solveWithin finds solutions. engineSearch uses solve, engineQuick solveQuick,
+	// and the other engines use the subsets table.
+	engine  engine
+	subsets *subsetSolutions
+}
+
+// minMemoDigits is the fewest digits a sub-search needs for it to be memoized.
+// Smaller searches are quicker to repeat than to look up.
+const minMemoDigits = 2
+
+// memoKey identifies a sub-search by its target and the digits it uses, as a bitmask over the pool.
+type memoKey struct {
+	target int
+	mask   uint
+}
+
+// searchMemo caches the solutions of sub-searches by memoKey.
+// It's safe for concurrent use, so the goroutines of a parallel search can share their work.
+type searchMemo struct {
+	mu        sync.Mutex
+	solutions map[memoKey][]expression
+}
+
+func newSearchMemo() *searchMemo {
+	return &searchMemo{solutions: make(map[memoKey][]expression)}
+}
+
+func (m *searchMemo) get(k memoKey) ([]expression, bool) {
+	m.mu.Lock()
+	solns, ok := m.solutions[k]
+	m.mu.Unlock()
+	return solns, ok
+}
+
+func (m *searchMemo) put(k memoKey, solns []expression) {
+	m.mu.Lock()
+	m.solutions[k] = solns
+	m.mu.Unlock()
+}
+
+// sortDigits returns digits in ascending order, and the index in that order of each of the digits.
+func sortDigits(digits []int) (pool, choices []int) {
+	order := make([]int, len(digits))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return digits[order[i]] < digits[order[j]] })
+	pool = make([]int, len(digits))
+	choices = make([]int, len(digits))
+	for i, d := range order {
+		pool[i] = digits[d]
+		choices[d] = i
+	}
+	return pool, choices
+}
+
+// largestValues returns the biggest value which can be made from each mask of digits, which are positive.
+// Subtracting and dividing never make more, so it's the most made by adding or multiplying two parts.
+// Values which would overflow are capped.
+func largestValues(digits []int) []int {
+	largest := make([]int, 1<<len(digits))
+	for mask := 1; mask < len(largest); mask++ {
+		if bits.OnesCount(uint(mask)) == 1 {
+			largest[mask] = digits[bits.TrailingZeros(uint(mask))]
+			continue
+		}
+		for part := (mask - 1) & mask; part > 0; part = (part - 1) & mask {
+			a, b := largest[part], largest[mask^part]
+			product, ok := mulInts(a, b)
+			if !ok {
+				product = math.MaxInt
+			}
+			largest[mask] = max(largest[mask], product, a+b)
+		}
+	}
+	return largest
+}
+
+// canonicalMask maps mask to the one which uses the lowest indices for each repeated digit,
+// so sub-searches of the same digits share a memo entry.
+func (s solver) canonicalMask(mask uint) uint {
+	var out uint
+	for i := 0; i < len(s.pool); {
+		n := 0
+		j := i
+		for ; j < len(s.pool) && s.pool[j] == s.pool[i]; j++ {
+			if mask&(1<<j) != 0 {
+				n++
+			}
+		}
+		out |= (1<<n - 1) << i
+		i = j
+	}
+	return out
+}
+
+// repeated reports whether mask also holds an earlier copy of the pool's digit at c.
+// Copies of a digit find the same solutions, so searches only try the first one left.
+func (s solver) repeated(mask uint, c int) bool {
+	for j := c - 1; j >= 0 && s.pool[j] == s.pool[c]; j-- {
+		if mask&(1<<j) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// stopped reports whether the search has been cancelled, timed out or run out of budget.
+func (s solver) stopped() bool {
+	return (s.ctx != nil && s.ctx.Err() != nil) || s.budget.exhausted()
+}
+
+// debug logs to the solver's logger, if it has one.
+func (s solver) debug(msg string, args ...any) {
+	if s.log != nil {
+		s.log.Debug(msg, args...)
+	}
+}
+
 func solve(target int, digits []int) []expression {
+	return solver{}.solve(target, digits)
+}
+
+// count returns how many solutions solve would find for target, without keeping them.
+// Each solution is only kept long enough to hash, and its nodes are reused for the next,
+// so duplicates are found by hash rather than compared in full.
+func (s solver) count(target int, digits []int) int {
+	s.counted = make(map[uint64]bool)
+	s.solve(target, digits)
+	return len(s.counted)
+}
+
+// countWithin is count with the search bounded by timeout, if it's positive, like solveWithin.
+// The subsets and splits engines count the solutions they find.
+func (s solver) countWithin(timeout time.Duration, target int, digits []int) (int, bool) {
+	if s.engine != engineSearch {
+		solns, incomplete := s.solveWithin(timeout, target, digits)
+		return len(solns), incomplete
+	}
+	if timeout > 0 {
+		parent := s.ctx
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+		s.ctx = ctx
+	}
+	n := s.count(target, digits)
+	return n, s.stopped()
+}
+
+// solveWithin is solve with the search bounded by timeout, if it's positive.
+// It also reports whether the search timed out, in which case the solutions may be incomplete.
+func (s solver) solveWithin(timeout time.Duration, target int, digits []int) ([]expression, bool) {
+	switch s.engine {
+	case engineSubsets:
+		return s.subsets.solutions(target), s.subsets.incomplete || s.stopped()
+	case engineSplits:
+		return s.solveSplits(timeout, target, digits)
+	case engineQuick:
+		return s.solveQuickWithin(timeout, target, digits)
+	}
+	if timeout <= 0 {
+		return s.solve(target, digits), s.stopped()
+	}
+	parent := s.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	s.ctx = ctx
+	solns := s.solve(target, digits)
+	return solns, s.stopped()
+}
+
+func (s solver) solve(target int, digits []int) []expression {
+	if s.stopped() {
+		return nil
+	}
+	// Sub-searches choose the digits from the pool by bitmask, rather than copying the rest of the digits for each choice.
+	// They're searched in ascending order, so the memoized solutions don't depend on which order got there first,
+	// and a parallel search lists them the same way from one run to the next.
+	pool, choices := sortDigits(digits)
+	s.pool = pool
+	s.arena = &exprArena{}
+	// Solutions for the other digits are found in full, so the limit and filter apply to how they're combined with a.
+	// That also means they can be memoized, as a sub-search always has the same solutions.
+	sub := s.subSolver(pool)
+	all := uint(1)<<len(digits) - 1
+	start := time.Now()
+	var solns []expression
+	if s.parallelism > 1 && len(digits) > 1 {
+		solns = s.searchParallel(target, all, choices, sub)
+	} else {
+		solns = s.search(target, all, choices, sub)
+	}
+	s.traceSearch(target, all, len(solns), false, start)
+	return solns
+}
+
+// searchCache holds the sub-searches of the digits in pool, so the searches for many targets can share them.
+// A range of targets would otherwise repeat nearly the same sub-searches for each one.
+// It's safe for concurrent use.
+type searchCache struct {
+	pool    []int
+	memo    *searchMemo
+	largest []int
+}
+
+func newSearchCache(digits []int) *searchCache {
+	pool, _ := sortDigits(digits)
+	return &searchCache{pool: pool, memo: newSearchMemo(), largest: largestValues(pool)}
+}
+
+// subSolver returns the solver for the sub-searches of a top-level search of pool.
+// They use s's cache if it's for the same digits, and a memo of their own otherwise.
+func (s solver) subSolver(pool []int) solver {
+	sub := solver{ctx: s.ctx, nodes: s.nodes, budget: s.budget, pool: pool, arena: s.arena, maxIntermediate: s.maxIntermediate, trace: s.trace}
+	// A bound on intermediate values leaves out solutions, so the shared sub-searches can't be used with one.
+	if c := s.cache; c != nil && slices.Equal(c.pool, pool) && s.maxIntermediate == 0 {
+		sub.memo, sub.largest = c.memo, c.largest
+	} else {
+		sub.memo, sub.largest = newSearchMemo(), largestValues(pool)
+	}
+	return sub
+}
+
+// solveMask finds the solutions for target using the digits from the pool in mask, memoizing them.
+func (s solver) solveMask(target int, mask uint) []expression {
+	if s.stopped() {
+		return nil
+	}
+	if s.largest != nil && target > s.largest[mask] {
+		if s.trace != nil {
+			s.trace.write(traceEvent{Event: "prune", Depth: s.depth(mask), Target: target, Reason: "the digits can't make that much"})
+		}
+		return nil
+	}
+	// A single digit isn't worked out, so it's allowed to be bigger.
+	if s.maxIntermediate > 0 && target > s.maxIntermediate && bits.OnesCount(mask) > 1 {
+		if s.trace != nil {
+			s.trace.write(traceEvent{Event: "prune", Depth: s.depth(mask), Target: target, Reason: "bigger than the bound on intermediate values"})
+		}
+		return nil
+	}
+	var start time.Time
+	if s.trace != nil {
+		start = time.Now()
+	}
+	var buf [maxDigitCount]int
+	choices := buf[:0]
+	for m := mask; m != 0; m &= m - 1 {
+		choices = append(choices, bits.TrailingZeros(m))
+	}
+	if len(choices) < minMemoDigits {
+		solns := s.search(target, mask, choices, s)
+		s.traceSearch(target, mask, len(solns), false, start)
+		return solns
+	}
+	key := memoKey{target: target, mask: s.canonicalMask(mask)}
+	if solns, ok := s.memo.get(key); ok {
+		s.traceSearch(target, mask, len(solns), true, start)
+		return solns
+	}
+	solns := s.search(target, mask, choices, s)
+	if !s.stopped() {
+		s.memo.put(key, solns)
+	}
+	s.traceSearch(target, mask, len(solns), false, start)
+	return solns
+}
+
+// searchParallel is search with each choice of top-level digit searched on one of s.parallelism goroutines.
+// The goroutines share sub's memo, and their solutions are merged in the order search would find them.
+func (s solver) searchParallel(target int, mask uint, choices []int, sub solver) []expression {
+	found := make([][]expression, len(choices))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for i := 0; i < min(s.parallelism, len(choices)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			arena := &exprArena{}
+			s, sub := s, sub
+			s.arena, sub.arena = arena, arena
+			counted := s.counted
+			if counted != nil {
+				s.counted = make(map[uint64]bool)
+			}
+			for c := range jobs {
+				found[c] = s.search(target, mask, choices[c:c+1], sub)
+			}
+			if counted != nil {
+				mu.Lock()
+				for h := range s.counted {
+					counted[h] = true
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for c := range choices {
+		jobs <- c
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Each choice's solutions are already normalized and filtered, but may repeat those of earlier choices.
 	var solutions []expression
+	var seen expressionSet
+	for _, solns := range found {
+		for _, e := range solns {
+			if !seen.add(e) {
+				continue
+			}
+			solutions = append(solutions, e)
+			if s.limit > 0 && len(solutions) >= s.limit {
+				return solutions
+			}
+		}
+	}
+	return solutions
+}
 
-	// Cache this outside the loop to reduce thrashing.
-	var other []int
+// search finds the solutions for target using the digits from the pool in mask.
+// It tries each of choices, which index the pool, as the digit combined with the solutions sub finds for the rest.
+func (s solver) search(target int, mask uint, choices []int, sub solver) []expression {
+	var solutions []expression
+	if s.nodes != nil {
+		s.nodes.Add(1)
+	}
+	s.budget.spend()
+
+	// Normalize and remove duplicates as solutions are found, so the search can stop as soon as the limit is reached.
+	// Most searches find nothing, so seen is only allocated once needed.
+	var seen expressionSet
+	add := func(e expression) bool {
+		if e.Val != target {
+			panic(fmt.Sprintf("generated invalid solution: %s = %d, want %d", e, e.Val, target))
+		}
+		if s.generated != nil {
+			s.generated.Add(1)
+		}
+		built := e
+		e = e.fuseWith(s.arena)
+		e = e.canonicalize()
+		if !s.filter.keep(e) {
+			s.debug("Filtered out solution", "target", target, "solution", e)
+			s.arena.release(built, e)
+			return true
+		}
+		if s.counted != nil {
+			s.counted[e.hash()] = true
+			s.arena.release(built, e)
+			return true
+		}
+		if !seen.add(e) {
+			s.arena.release(built, e)
+			return true
+		}
+		solutions = append(solutions, e)
+		if s.limit > 0 && len(solutions) >= s.limit {
+			s.debug("Reached solution limit", "target", target, "limit", s.limit)
+			return false
+		}
+		return true
+	}
 
 	// See if there is a valid solution of the form 'a op otherDigits' or 'otherDigits op a'.
-	for aIdx := 0; aIdx < len(digits); aIdx++ {
+	for _, c := range choices {
+		if s.stopped() {
+			break
+		}
+		// Otherwise every solution would be found once per copy, and removed as a duplicate.
+		// The first copy comes first in choices, so skipping the others doesn't change the order solutions are found in.
+		if s.repeated(mask, c) {
+			continue
+		}
 		// Identity.
-		a := digits[aIdx]
+		a := s.pool[c]
 		aExp := makeConstant(a)
-		if a == target {
-			solutions = append(solutions, aExp)
+		if a == target && !add(aExp) {
+			return solutions
 		}
 
-		other = other[:0]
-		other = append(other, digits[:aIdx]...)
-		other = append(other, digits[aIdx+1:]...)
+		other := mask &^ (1 << c)
 
 		// Addition.
 		if target > a {
-			for _, soln := range solve(target-a, other) {
-				solutions = append(solutions, makeAdd(aExp, soln))
+			s.traceBranch(target, mask, a, "a + rest", target-a)
+			for _, soln := range sub.solveMask(target-a, other) {
+				if !add(s.arena.makeAdd(aExp, soln)) {
+					return solutions
+				}
 			}
+		} else {
+			s.pruned(target, mask, a, "a + rest", "digit is at least the target")
 		}
 
 		// Subtraction.
 		if a > target {
-			for _, soln := range solve(a-target, other) {
-				solutions = append(solutions, makeAdd(aExp, makeNegate(soln)))
+			s.traceBranch(target, mask, a, "a - rest", a-target)
+			for _, soln := range sub.solveMask(a-target, other) {
+				if !add(s.arena.makeAdd(aExp, s.arena.makeNegate(soln))) {
+					return solutions
+				}
 			}
+		} else {
+			s.pruned(target, mask, a, "a - rest", "digit is not larger than the target")
 		}
-		for _, soln := range solve(target+a, other) {
-			solutions = append(solutions, makeAdd(soln, makeNegate(aExp)))
+		// The rest would have to be bigger than any int, if the sum or product overflows.
+		if target <= math.MaxInt-a {
+			s.traceBranch(target, mask, a, "rest - a", target+a)
+			for _, soln := range sub.solveMask(target+a, other) {
+				if !add(s.arena.makeAdd(soln, s.arena.makeNegate(aExp))) {
+					return solutions
+				}
+			}
+		} else {
+			s.pruned(target, mask, a, "rest - a", "the rest would overflow")
 		}
 
 		// Multiplication.
 		if (target % a) == 0 {
-			for _, soln := range solve(target/a, other) {
-				solutions = append(solutions, makeMultiply(aExp, soln))
+			s.traceBranch(target, mask, a, "a * rest", target/a)
+			for _, soln := range sub.solveMask(target/a, other) {
+				if !add(s.arena.makeMultiply(aExp, soln)) {
+					return solutions
+				}
 			}
+		} else {
+			s.pruned(target, mask, a, "a * rest", "target is not a multiple of the digit")
 		}
 
 		// Division.
 		if (a % target) == 0 {
-			for _, soln := range solve(a/target, other) {
-				solutions = append(solutions, makeDivide(aExp, soln))
+			s.traceBranch(target, mask, a, "a / rest", a/target)
+			for _, soln := range sub.solveMask(a/target, other) {
+				if !add(s.arena.makeDivide(aExp, soln)) {
+					return solutions
+				}
 			}
+		} else {
+			s.pruned(target, mask, a, "a / rest", "digit is not a multiple of the target")
 		}
-		for _, soln := range solve(target*a, other) {
-			solutions = append(solutions, makeDivide(soln, aExp))
+		if product, ok := mulInts(target, a); ok {
+			s.traceBranch(target, mask, a, "rest / a", product)
+			for _, soln := range sub.solveMask(product, other) {
+				if !add(s.arena.makeDivide(soln, aExp)) {
+					return solutions
+				}
+			}
+		} else {
+			s.pruned(target, mask, a, "rest / a", "the rest would overflow")
 		}
 	}
 
-	// TODO: divide digits into two sets. For each solution in set A, see if there is a solution in set B which will form the target.
-
-	for _, soln := range solutions {
-		if soln.Val != target {
-			panic(fmt.Sprintf("generated invalid solution: %s = %d, want %d", soln, soln.Val, target))
-		}
-	}
+	// This only finds solutions which combine one digit with the rest, so misses ones like (a + b) * (c + d).
+	// engineSplits finds those by joining every split of the digits into two groups.
 
-	// Normalize and remove duplicates.
-	seen := make(map[string]bool)
-	var solsOut []expression
-	for _, s := range solutions {
-		s = s.fuse()
-		s = s.canonicalize()
-		key := s.String()
-		if !seen[key] {
-			seen[key] = true
-			solsOut = append(solsOut, s)
-		}
-	}
-	return solsOut
+	return solutions
 }
 
+// shortest returns the solution needing the fewest operations, breaking ties by the shortest written form,
+// then by the order they were found in. A solution with fewer, bigger numbers, like 25*4, beats one
+// with more single digits, even if it's written longer.
 func shortest(solns []expression) (expression, error) {
 	if len(solns) == 0 {
 		return expression{}, fmt.Errorf("no solutions")
 	}
-
-	shortest := ""
-	var shortestSoln expression
-
-	for _, soln := range solns {
-		str := soln.String()
-		if shortest == "" || len(str) < len(shortest) {
-			shortest = str
-			shortestSoln = soln
+	best, bestKey := solns[0], shortestKey(solns[0])
+	for _, soln := range solns[1:] {
+		if key := shortestKey(soln); key.less(bestKey) {
+			best, bestKey = soln, key
 		}
 	}
-	return shortestSoln, nil
+	return best, nil
 }
 
-func main() {
-	flag.Parse()
-
-	if *digitsStr == "" {
-		log.Fatalf("--digits must be provided")
+// shortestN returns up to n of the shortest solutions, shortest first, ordered like shortest.
+func shortestN(solns []expression, n int) []expression {
+	keys := make([]lengthKey, len(solns))
+	for i, s := range solns {
+		keys[i] = shortestKey(s)
 	}
-	digits, err := parseDigits(*digitsStr)
-	if err != nil {
-		log.Fatalf("--digits invalid: %v", err)
+	idx := make([]int, len(solns))
+	for i := range idx {
+		idx[i] = i
 	}
+	sort.SliceStable(idx, func(i, j int) bool { return keys[idx[i]].less(keys[idx[j]]) })
+	if len(idx) > n {
+		idx = idx[:n]
+	}
+	sorted := make([]expression, len(idx))
+	for i, j := range idx {
+		sorted[i] = solns[j]
+	}
+	return sorted
+}
 
-	// TODO: When printing out the solution we want to show binary operations (i.e. unfuse the n-ary operations).
-
-	switch {
-	case *targetRange != "":
-		min, max, err := parseTargetRange(*targetRange)
-		if err != nil {
-			log.Fatalf("--target_range invalid: %v", err)
-		}
-		for i := min; i <= max; i++ {
-			solns := solve(i, digits)
-			fmt.Printf("%d: %d solutions found\n", i, len(solns))
-		}
-	case *target != 0:
-		solns := solve(*target, digits)
-		if len(solns) == 0 {
-			fmt.Printf("no solution found :(\n")
-			return
-		}
+// lengthKey is what shortest compares solutions by.
+type lengthKey struct {
+	ops, chars int
+}
 
-		for i, soln := range solns {
-			result, ok := soln.eval()
-			if !ok {
-				log.Fatalf("result is invalid")
-			}
-			if result != *target {
-				log.Fatalf("generated incorrect solution: %s = %d, != %d!", soln, result, *target)
-			}
-			fmt.Printf("%d: %d = %s\n", i, result, soln)
-		}
+func shortestKey(e expression) lengthKey {
+	return lengthKey{ops: len(e.steps()), chars: len(e.String())}
+}
 
-		shortest, err := shortest(solns)
-		if err != nil {
-			log.Fatalf("Failed to get shortest solution: %v", err)
-		}
-		fmt.Printf("Shortest solution: %s\n", shortest)
-	default:
-		log.Fatalf("--target or --solve_all must be provided")
+func (k lengthKey) less(o lengthKey) bool {
+	if k.ops != o.ops {
+		return k.ops < o.ops
 	}
+	return k.chars < o.chars
 }
@@ -1,7 +1,15 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -17,7 +25,7 @@ func Test_solve(t *testing.T) {
 		"a": {
 			digits:       []int{5, 7, 9, 10, 15, 25},
 			target:       93,
-			wantLen:      68,
+			wantLen:      55,
 			wantFirst:    "((((25 * (15 + 10)) + -9) / 7) + 5)",
 			wantShortest: "((9 * 7) + 25 + 5)",
 		},
@@ -25,29 +33,29 @@ func Test_solve(t *testing.T) {
 
 			digits:       []int{4, 5, 7, 8, 15, 20},
 			target:       113,
-			wantLen:      190,
+			wantLen:      82,
 			wantFirst:    "(((20 + -7) * 8) + 5 + 4)",
 			wantShortest: "((15 * 7) + 8)",
 		},
 		"c": {
 			digits:       []int{3, 4, 6, 9, 11, 15},
 			target:       205,
-			wantLen:      34,
+			wantLen:      25,
 			wantFirst:    "(((15 + 9 + -6) * 11) + 4 + 3)",
 			wantShortest: "((9 * 6 * 4) + -11)",
 		},
 		"d": {
 			digits:       []int{3, 5, 9, 11, 23, 25},
 			target:       351,
-			wantLen:      24,
+			wantLen:      16,
 			wantFirst:    "(((11 * 9) + 23 + -5) * 3)",
 			wantShortest: "((25 + 11 + 3) * 9)",
 		},
 		"f": {
 			digits:       []int{24, 8, 10, 20, 5, 15},
 			target:       497,
-			wantLen:      11,
-			wantFirst:    "((24 * 20) + 15 + 10 + -8)",
+			wantLen:      9,
+			wantFirst:    "(((15 + 10) * 20) + -8 + 5)",
 			wantShortest: "((24 * 20) + 15 + 10 + -8)",
 		},
 	}
@@ -74,3 +82,530 @@ func Test_solve(t *testing.T) {
 		})
 	}
 }
+
+func Test_expression_fuse(t *testing.T) {
+	tests := map[string]struct {
+		expr expression
+		want string
+	}{
+		"sum": {
+			expr: makeAdd(makeConstant(25), makeAdd(makeConstant(7), makeConstant(5))),
+			want: "(25 + 7 + 5)",
+		},
+		"negated sum": {
+			expr: makeAdd(makeConstant(25), makeNegate(makeAdd(makeConstant(7), makeNegate(makeConstant(5))))),
+			want: "(25 + -7 + 5)",
+		},
+		"subtraction chain": {
+			expr: makeSubtract(makeSubtract(makeConstant(25), makeConstant(7)), makeConstant(5)),
+			want: "(25 + -7 + -5)",
+		},
+		"subtracting a subtraction": {
+			expr: makeSubtract(makeConstant(25), makeSubtract(makeConstant(7), makeConstant(5))),
+			want: "(25 + -7 + 5)",
+		},
+		"many operands": {
+			expr: expression{Val: 13, Op: opSubtract, Children: []*expression{ptr(makeConstant(25)), ptr(makeConstant(7)), ptr(makeConstant(5))}},
+			want: "(25 + -7 + -5)",
+		},
+		"division chain": {
+			expr: makeDivide(makeDivide(makeConstant(100), makeConstant(5)), makeConstant(2)),
+			want: "(100 / (5 * 2))",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := tt.expr.fuse()
+			if got.String() != tt.want {
+				t.Errorf("fuse() = %s, want %s", got, tt.want)
+			}
+			if got.Val != tt.expr.Val {
+				t.Errorf("fuse().Val = %d, want %d", got.Val, tt.expr.Val)
+			}
+		})
+	}
+}
+
+func Test_expression_fuse_parsed(t *testing.T) {
+	// Answers written with subtraction fuse into the same form as solve's solutions.
+	e, err := parseInfix("10 - 5 - 2")
+	if err != nil {
+		t.Fatalf("parseInfix() failed unexpectedly: %v", err)
+	}
+	got := e.fuse().canonicalize()
+	var solns []string
+	for _, s := range solve(3, []int{10, 5, 2}) {
+		solns = append(solns, s.String())
+	}
+	if !slices.Contains(solns, got.String()) {
+		t.Errorf("fuse() = %s, want one of solve()'s solutions %v", got, solns)
+	}
+}
+
+func Test_parseDigits(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		want    []int
+		wantErr string
+	}{
+		"valid": {
+			input: "5, 7,9",
+			want:  []int{5, 7, 9},
+		},
+		"empty entry": {
+			input:   "5,,7",
+			wantErr: "entry 2 is empty",
+		},
+		"trailing comma": {
+			input:   "5,7,",
+			wantErr: "entry 3 is empty",
+		},
+		"not a number": {
+			input:   "5,7,x9",
+			wantErr: `entry 3 ("x9") is not a whole number`,
+		},
+		"fraction": {
+			input:   "2.5",
+			wantErr: `entry 1 ("2.5") is not a whole number`,
+		},
+		"negative": {
+			input:   "5,-7",
+			wantErr: "entry 2 (-7) must be positive",
+		},
+		"too many": {
+			input:   "1,2,3,4,5,6,7,8,9,10,11",
+			wantErr: "too many digits: got 11, but at most 10 can be searched",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got, err := parseDigits(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("parseDigits(%q) = %v, %v, want error %q", tt.input, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDigits(%q) failed unexpectedly: %v", tt.input, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("parseDigits(%q) mismatch (-want +got):\n%s", tt.input, diff)
+			}
+		})
+	}
+}
+
+func Test_parseDigitArgs(t *testing.T) {
+	tests := map[string]struct {
+		flagValue string
+		args      []string
+		want      []int
+		wantErr   bool
+	}{
+		"flag": {
+			flagValue: "5,7,9",
+			want:      []int{5, 7, 9},
+		},
+		"args": {
+			args: []string{"5", "7", "9"},
+			want: []int{5, 7, 9},
+		},
+		"comma-separated args": {
+			args: []string{"5,7", "9"},
+			want: []int{5, 7, 9},
+		},
+		"mixed": {
+			flagValue: "5,7",
+			args:      []string{"9"},
+			wantErr:   true,
+		},
+		"missing": {
+			wantErr: true,
+		},
+		"not a number": {
+			args:    []string{"5", "x"},
+			wantErr: true,
+		},
+		"zero": {
+			flagValue: "5,0",
+			wantErr:   true,
+		},
+		"empty element": {
+			flagValue: "5,,7",
+			wantErr:   true,
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got, err := parseDigitArgs(tt.flagValue, tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseDigitArgs() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDigitArgs() failed unexpectedly: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("parseDigitArgs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_shortest_fewerOperations(t *testing.T) {
+	// 10935 / 15 is written longer than 9 * 9 * 9, but needs one operation rather than two.
+	fewer := makeDivide(makeConstant(10935), makeConstant(15))
+	more := makeMultiply(makeMultiply(makeConstant(9), makeConstant(9)), makeConstant(9)).fuse()
+	longer := makeDivide(makeConstant(145800), makeConstant(200))
+	got, err := shortest([]expression{more, longer, fewer})
+	if err != nil {
+		t.Fatalf("shortest() failed unexpectedly: %v", err)
+	}
+	if got.String() != fewer.String() {
+		t.Errorf("shortest() = %s, want %s", got, fewer)
+	}
+}
+
+func Test_shortestN(t *testing.T) {
+	solns := solve(93, []int{5, 7, 9, 10, 15, 25})
+	tests := map[string]struct {
+		n       int
+		want    []string
+		wantLen int
+	}{
+		"one": {
+			n:    1,
+			want: []string{"((9 * 7) + 25 + 5)"},
+		},
+		"three": {
+			n:    3,
+			want: []string{"((9 * 7) + 25 + 5)", "((15 * 5) + 25 + -7)", "(((7 + 5) * 9) + -15)"},
+		},
+		"more than found": {
+			n:       1000,
+			wantLen: 55,
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := shortestN(solns, tt.n)
+			if tt.wantLen > 0 {
+				if len(got) != tt.wantLen {
+					t.Errorf("shortestN() got %d results, want %d", len(got), tt.wantLen)
+				}
+				return
+			}
+			var strs []string
+			for _, s := range got {
+				strs = append(strs, s.String())
+			}
+			if diff := cmp.Diff(tt.want, strs); diff != "" {
+				t.Errorf("shortestN() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_solver_limit(t *testing.T) {
+	digits := []int{5, 7, 9, 10, 15, 25}
+	all := solve(93, digits)
+	for _, limit := range []int{1, 10, 55, 100} {
+		got := solver{limit: limit}.solve(93, digits)
+		want := all
+		if limit < len(all) {
+			want = all[:limit]
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("solver{limit: %d}.solve() mismatch (-want +got):\n%s", limit, diff)
+		}
+	}
+}
+
+func Test_solver_solveWithin(t *testing.T) {
+	digits := []int{5, 7, 9, 10, 15, 25}
+
+	solns, incomplete := solver{}.solveWithin(time.Minute, 93, digits)
+	if incomplete || len(solns) != 55 {
+		t.Errorf("solveWithin(1m) = %d solutions, incomplete %t; want 55, false", len(solns), incomplete)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	solns, incomplete = solver{ctx: ctx}.solveWithin(time.Minute, 93, digits)
+	if !incomplete || len(solns) != 0 {
+		t.Errorf("solveWithin() after cancellation = %d solutions, incomplete %t; want 0, true", len(solns), incomplete)
+	}
+}
+
+func Test_sortDigits(t *testing.T) {
+	pool, choices := sortDigits([]int{25, 5, 7, 5})
+	if diff := cmp.Diff([]int{5, 5, 7, 25}, pool); diff != "" {
+		t.Errorf("sortDigits() pool mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{3, 0, 2, 1}, choices); diff != "" {
+		t.Errorf("sortDigits() choices mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_largestValues(t *testing.T) {
+	got := largestValues([]int{1, 2, 3})
+	if diff := cmp.Diff([]int{0, 1, 2, 3, 3, 4, 6, 9}, got); diff != "" {
+		t.Errorf("largestValues() mismatch (-want +got):\n%s", diff)
+	}
+	big := make([]int, maxDigitCount)
+	for i := range big {
+		big[i] = 100
+	}
+	if got := largestValues(big); got[len(got)-1] != math.MaxInt {
+		t.Errorf("largestValues(%v) = %d, want it capped at %d", big, got[len(got)-1], math.MaxInt)
+	}
+}
+
+func Test_solver_canonicalMask(t *testing.T) {
+	s := solver{pool: []int{5, 5, 5, 7, 25, 25}}
+	tests := map[string]struct {
+		mask uint
+		want uint
+	}{
+		"no repeats":     {mask: 0b011001, want: 0b011001},
+		"first of three": {mask: 0b000100, want: 0b000001},
+		"two of three":   {mask: 0b000101, want: 0b000011},
+		"every repeat":   {mask: 0b111111, want: 0b111111},
+		"second of two":  {mask: 0b101000, want: 0b011000},
+		"no digits":      {mask: 0, want: 0},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			if got := s.canonicalMask(tt.mask); got != tt.want {
+				t.Errorf("canonicalMask(%06b) = %06b, want %06b", tt.mask, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_solve_repeatedDigits(t *testing.T) {
+	// Repeated digits make for many identical sub-searches, which are memoized.
+	solutions := func(digits []int) []string {
+		var out []string
+		for _, s := range solve(500, digits) {
+			out = append(out, s.String())
+		}
+		slices.Sort(out)
+		return out
+	}
+	got := solutions([]int{2, 2, 5, 5, 10, 10})
+	if len(got) != 51 {
+		t.Errorf("solve() got %d results, want 51", len(got))
+	}
+	if diff := cmp.Diff(got, solutions([]int{10, 5, 2, 10, 5, 2})); diff != "" {
+		t.Errorf("solve() with the digits reordered mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_solver_parallelism(t *testing.T) {
+	solutions := func(s solver, target int, digits []int) string {
+		var b strings.Builder
+		for _, e := range s.solve(target, digits) {
+			fmt.Fprintln(&b, e)
+		}
+		return b.String()
+	}
+	tests := map[string]struct {
+		s      solver
+		target int
+		digits []int
+	}{
+		"every solution": {target: 93, digits: []int{5, 7, 9, 10, 15, 25}},
+		"limit":          {s: solver{limit: 10}, target: 93, digits: []int{5, 7, 9, 10, 15, 25}},
+		"filter":         {s: solver{filter: solutionFilter{noDivision: true}}, target: 93, digits: []int{5, 7, 9, 10, 15, 25}},
+		// Repeated and unsorted digits share sub-searches between the goroutines in different orders.
+		"repeated digits": {target: 500, digits: []int{10, 5, 2, 10, 5, 2}},
+		"unsorted digits": {target: 497, digits: []int{24, 8, 10, 20, 5, 15}},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			want := solutions(tt.s, tt.target, tt.digits)
+			// The output must be identical however many goroutines there are, and from run to run.
+			for _, parallelism := range []int{2, 4, 4, 16} {
+				s := tt.s
+				s.parallelism = parallelism
+				if diff := cmp.Diff(want, solutions(s, tt.target, tt.digits)); diff != "" {
+					t.Errorf("solve() with parallelism %d mismatch (-serial +parallel):\n%s", parallelism, diff)
+				}
+			}
+		})
+	}
+}
+
+func Benchmark_solve(b *testing.B) {
+	benchmarks := map[string]struct {
+		target int
+		digits []int
+	}{
+		"a": {target: 93, digits: []int{5, 7, 9, 10, 15, 25}},
+		"b": {target: 113, digits: []int{4, 5, 7, 8, 15, 20}},
+		"c": {target: 205, digits: []int{3, 4, 6, 9, 11, 15}},
+		"d": {target: 351, digits: []int{3, 5, 9, 11, 23, 25}},
+		// Repeated digits, whose copies are only searched once.
+		"repeated": {target: 500, digits: []int{10, 5, 2, 10, 5, 2}},
+	}
+	for bn, bb := range benchmarks {
+		b.Run(bn, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				solve(bb.target, bb.digits)
+			}
+		})
+	}
+}
+
+func Test_solver_cache(t *testing.T) {
+	digits := []int{5, 7, 9, 10, 15, 25}
+	tests := map[string]struct {
+		cache  *searchCache
+		digits []int
+	}{
+		"same digits":      {cache: newSearchCache(digits), digits: digits},
+		"reordered digits": {cache: newSearchCache([]int{25, 15, 10, 9, 7, 5}), digits: digits},
+		// A cache for other digits is ignored.
+		"other digits": {cache: newSearchCache([]int{1, 2, 3}), digits: digits},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			// Sharing sub-searches between targets mustn't change any target's solutions.
+			for target := 80; target <= 120; target++ {
+				want := fmt.Sprint(solve(target, tt.digits))
+				got := fmt.Sprint(solver{cache: tt.cache}.solve(target, tt.digits))
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Fatalf("solve(%d) with a cache mismatch (-want +got):\n%s", target, diff)
+				}
+			}
+		})
+	}
+}
+
+func Test_solver_count(t *testing.T) {
+	tests := map[string]struct {
+		s      solver
+		target int
+		digits []int
+	}{
+		"every solution":  {target: 93, digits: []int{5, 7, 9, 10, 15, 25}},
+		"filter":          {s: solver{filter: solutionFilter{noDivision: true}}, target: 93, digits: []int{5, 7, 9, 10, 15, 25}},
+		"repeated digits": {target: 500, digits: []int{10, 5, 2, 10, 5, 2}},
+		"parallel":        {s: solver{parallelism: 4}, target: 113, digits: []int{4, 5, 7, 8, 15, 20}},
+		"no solution":     {target: 1000, digits: []int{3, 4, 6}},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			want := len(tt.s.solve(tt.target, tt.digits))
+			if got := tt.s.count(tt.target, tt.digits); got != want {
+				t.Errorf("count() = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func Test_solver_generated(t *testing.T) {
+	tests := map[string]struct {
+		s      solver
+		target int
+		digits []int
+		want   int64
+	}{
+		"every solution":  {target: 93, digits: []int{5, 7, 9, 10, 15, 25}, want: 87},
+		"parallel":        {s: solver{parallelism: 4}, target: 93, digits: []int{5, 7, 9, 10, 15, 25}, want: 87},
+		"filtered":        {s: solver{filter: solutionFilter{noDivision: true}}, target: 93, digits: []int{5, 7, 9, 10, 15, 25}, want: 87},
+		"repeated digits": {target: 12, digits: []int{2, 2, 3, 3}, want: 6},
+		"no solution":     {target: 1000, digits: []int{3, 4, 6}},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			var generated atomic.Int64
+			tt.s.generated = &generated
+			solns := tt.s.solve(tt.target, tt.digits)
+			if got := generated.Load(); got != tt.want || got < int64(len(solns)) {
+				t.Errorf("solve() generated %d expressions for %d solutions, want %d", got, len(solns), tt.want)
+			}
+		})
+	}
+}
+
+func Test_solver_concurrent(t *testing.T) {
+	digits := []int{25, 50, 75, 100, 3, 6}
+	subsets, err := solver{}.withEngine(engineSubsets, 0, digits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		s    solver
+		call func(s solver, target int) string
+	}{
+		"solve": {call: func(s solver, target int) string { return fmt.Sprint(s.solve(target, digits)) }},
+		"shared cache": {s: solver{cache: newSearchCache(digits)}, call: func(s solver, target int) string {
+			return fmt.Sprint(s.solve(target, digits))
+		}},
+		"parallel": {s: solver{parallelism: 2, cache: newSearchCache(digits)}, call: func(s solver, target int) string {
+			return fmt.Sprint(s.solve(target, digits))
+		}},
+		"count":  {call: func(s solver, target int) string { return fmt.Sprint(s.count(target, digits)) }},
+		"fewest": {call: func(s solver, target int) string { return fmt.Sprint(s.solveFewest(target, digits, 1)) }},
+		"subsets": {s: subsets, call: func(s solver, target int) string {
+			solns, _ := s.solveWithin(0, target, digits)
+			return fmt.Sprint(solns)
+		}},
+		"quick": {s: solver{engine: engineQuick}, call: func(s solver, target int) string {
+			solns, _ := s.solveWithin(0, target, digits)
+			return fmt.Sprint(solns)
+		}},
+		// Callers sort and print the solutions they're given, which mustn't change them for anyone else.
+		"sorted": {s: solver{cache: newSearchCache(digits)}, call: func(s solver, target int) string {
+			solns := sortSolutions(s.solve(target, digits), orderLex, printer{})
+			return fmt.Sprint(solns)
+		}},
+	}
+	targets := []int{123, 500, 952, 952, 500, 123}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			want := make([]string, len(targets))
+			for i, target := range targets {
+				want[i] = tt.call(tt.s, target)
+			}
+			// Every call shares tt.s, as a server handling requests at once would.
+			got := make([]string, len(targets))
+			var wg sync.WaitGroup
+			for i, target := range targets {
+				i, target := i, target
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					got[i] = tt.call(tt.s, target)
+				}()
+			}
+			wg.Wait()
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("concurrent calls mismatch (-serial +concurrent):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_solve_overflow(t *testing.T) {
+	// Sub-targets which would overflow are skipped rather than wrapping to zero and dividing by it.
+	digits := []int{65536, 65536, 65536, 65536, 65536}
+	if got := solve(999, digits); len(got) != 0 {
+		t.Errorf("solve(999, %v) = %v, want no solutions", digits, got)
+	}
+	if got := solve(4, digits); len(got) == 0 {
+		t.Errorf("solve(4, %v) found no solutions, want (65536 + 65536 + 65536 + 65536)/65536", digits)
+	}
+	seen := newRecentSet(1000)
+	n := 0
+	solver{}.streamSolutions(4, digits, seen, func(expression) bool { n++; return true })
+	if n == 0 {
+		t.Errorf("streamSolutions(4, %v) found no solutions", digits)
+	}
+}
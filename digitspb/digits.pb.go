@@ -0,0 +1,940 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v4.25.3
+// source: digits.proto
+
+// The gRPC API digits serve offers alongside its HTTP JSON API. The fields are like those of the JSON
+// requests and responses of the same names. Digits, targets and values are int64, so they
+// aren't cut short where the JSON API's aren't.
+
+package digitspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SolveRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Digits []int64 `protobuf:"varint,1,rep,packed,name=digits,proto3" json:"digits,omitempty"`
+	Target int64   `protobuf:"varint,2,opt,name=target,proto3" json:"target,omitempty"`
+	// notation takes the same names as solve's --notation, and defaults to infix.
+	Notation string `protobuf:"bytes,3,opt,name=notation,proto3" json:"notation,omitempty"`
+	Unicode  bool   `protobuf:"varint,4,opt,name=unicode,proto3" json:"unicode,omitempty"`
+	// max_results, if positive, ends the stream after this many solutions.
+	MaxResults    int32 `protobuf:"varint,5,opt,name=max_results,json=maxResults,proto3" json:"max_results,omitempty"`
+	NoDivision    bool  `protobuf:"varint,6,opt,name=no_division,json=noDivision,proto3" json:"no_division,omitempty"`
+	NoNegation    bool  `protobuf:"varint,7,opt,name=no_negation,json=noNegation,proto3" json:"no_negation,omitempty"`
+	NoTrivial     bool  `protobuf:"varint,8,opt,name=no_trivial,json=noTrivial,proto3" json:"no_trivial,omitempty"`
+	MaxOps        int32 `protobuf:"varint,9,opt,name=max_ops,json=maxOps,proto3" json:"max_ops,omitempty"`
+	MaxDigitsUsed int32 `protobuf:"varint,10,opt,name=max_digits_used,json=maxDigitsUsed,proto3" json:"max_digits_used,omitempty"`
+}
+
+func (x *SolveRequest) Reset() {
+	*x = SolveRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_digits_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SolveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SolveRequest) ProtoMessage() {}
+
+func (x *SolveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_digits_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SolveRequest.ProtoReflect.Descriptor instead.
+func (*SolveRequest) Descriptor() ([]byte, []int) {
+	return file_digits_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SolveRequest) GetDigits() []int64 {
+	if x != nil {
+		return x.Digits
+	}
+	return nil
+}
+
+func (x *SolveRequest) GetTarget() int64 {
+	if x != nil {
+		return x.Target
+	}
+	return 0
+}
+
+func (x *SolveRequest) GetNotation() string {
+	if x != nil {
+		return x.Notation
+	}
+	return ""
+}
+
+func (x *SolveRequest) GetUnicode() bool {
+	if x != nil {
+		return x.Unicode
+	}
+	return false
+}
+
+func (x *SolveRequest) GetMaxResults() int32 {
+	if x != nil {
+		return x.MaxResults
+	}
+	return 0
+}
+
+func (x *SolveRequest) GetNoDivision() bool {
+	if x != nil {
+		return x.NoDivision
+	}
+	return false
+}
+
+func (x *SolveRequest) GetNoNegation() bool {
+	if x != nil {
+		return x.NoNegation
+	}
+	return false
+}
+
+func (x *SolveRequest) GetNoTrivial() bool {
+	if x != nil {
+		return x.NoTrivial
+	}
+	return false
+}
+
+func (x *SolveRequest) GetMaxOps() int32 {
+	if x != nil {
+		return x.MaxOps
+	}
+	return 0
+}
+
+func (x *SolveRequest) GetMaxDigitsUsed() int32 {
+	if x != nil {
+		return x.MaxDigitsUsed
+	}
+	return 0
+}
+
+type Solution struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Text   string  `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Value  int64   `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	Ops    int32   `protobuf:"varint,3,opt,name=ops,proto3" json:"ops,omitempty"`
+	Digits []int64 `protobuf:"varint,4,rep,packed,name=digits,proto3" json:"digits,omitempty"`
+	// steps are the operations in the order a person would do them, like "9 * 7 = 63".
+	Steps []string `protobuf:"bytes,5,rep,name=steps,proto3" json:"steps,omitempty"`
+}
+
+func (x *Solution) Reset() {
+	*x = Solution{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_digits_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Solution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Solution) ProtoMessage() {}
+
+func (x *Solution) ProtoReflect() protoreflect.Message {
+	mi := &file_digits_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Solution.ProtoReflect.Descriptor instead.
+func (*Solution) Descriptor() ([]byte, []int) {
+	return file_digits_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Solution) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Solution) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Solution) GetOps() int32 {
+	if x != nil {
+		return x.Ops
+	}
+	return 0
+}
+
+func (x *Solution) GetDigits() []int64 {
+	if x != nil {
+		return x.Digits
+	}
+	return nil
+}
+
+func (x *Solution) GetSteps() []string {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+// SolveEvent is a solution, or the done event which ends a Solve stream.
+type SolveEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Event:
+	//	*SolveEvent_Solution
+	//	*SolveEvent_Done
+	Event isSolveEvent_Event `protobuf_oneof:"event"`
+}
+
+func (x *SolveEvent) Reset() {
+	*x = SolveEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_digits_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SolveEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SolveEvent) ProtoMessage() {}
+
+func (x *SolveEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_digits_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SolveEvent.ProtoReflect.Descriptor instead.
+func (*SolveEvent) Descriptor() ([]byte, []int) {
+	return file_digits_proto_rawDescGZIP(), []int{2}
+}
+
+func (m *SolveEvent) GetEvent() isSolveEvent_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (x *SolveEvent) GetSolution() *Solution {
+	if x, ok := x.GetEvent().(*SolveEvent_Solution); ok {
+		return x.Solution
+	}
+	return nil
+}
+
+func (x *SolveEvent) GetDone() *SolveDone {
+	if x, ok := x.GetEvent().(*SolveEvent_Done); ok {
+		return x.Done
+	}
+	return nil
+}
+
+type isSolveEvent_Event interface {
+	isSolveEvent_Event()
+}
+
+type SolveEvent_Solution struct {
+	Solution *Solution `protobuf:"bytes,1,opt,name=solution,proto3,oneof"`
+}
+
+type SolveEvent_Done struct {
+	Done *SolveDone `protobuf:"bytes,2,opt,name=done,proto3,oneof"`
+}
+
+func (*SolveEvent_Solution) isSolveEvent_Event() {}
+
+func (*SolveEvent_Done) isSolveEvent_Event() {}
+
+type SolveDone struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Solutions int64 `protobuf:"varint,1,opt,name=solutions,proto3" json:"solutions,omitempty"`
+	// incomplete reports whether the search stopped early, so there may be more solutions, and reason why:
+	// "timeout" or "max_nodes".
+	Incomplete bool   `protobuf:"varint,2,opt,name=incomplete,proto3" json:"incomplete,omitempty"`
+	Reason     string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *SolveDone) Reset() {
+	*x = SolveDone{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_digits_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SolveDone) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SolveDone) ProtoMessage() {}
+
+func (x *SolveDone) ProtoReflect() protoreflect.Message {
+	mi := &file_digits_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SolveDone.ProtoReflect.Descriptor instead.
+func (*SolveDone) Descriptor() ([]byte, []int) {
+	return file_digits_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SolveDone) GetSolutions() int64 {
+	if x != nil {
+		return x.Solutions
+	}
+	return 0
+}
+
+func (x *SolveDone) GetIncomplete() bool {
+	if x != nil {
+		return x.Incomplete
+	}
+	return false
+}
+
+func (x *SolveDone) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type GenerateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// preset names a [presets.<name>] table in the server's config file.
+	Preset string `protobuf:"bytes,1,opt,name=preset,proto3" json:"preset,omitempty"`
+	// difficulty, if given, asks for a puzzle with exactly this rating, from 1 to 5.
+	Difficulty int32  `protobuf:"varint,2,opt,name=difficulty,proto3" json:"difficulty,omitempty"`
+	Large      *int32 `protobuf:"varint,3,opt,name=large,proto3,oneof" json:"large,omitempty"`
+	MinRating  *int32 `protobuf:"varint,4,opt,name=min_rating,json=minRating,proto3,oneof" json:"min_rating,omitempty"`
+	MaxRating  *int32 `protobuf:"varint,5,opt,name=max_rating,json=maxRating,proto3,oneof" json:"max_rating,omitempty"`
+	Seed       int64  `protobuf:"varint,6,opt,name=seed,proto3" json:"seed,omitempty"`
+}
+
+func (x *GenerateRequest) Reset() {
+	*x = GenerateRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_digits_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GenerateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateRequest) ProtoMessage() {}
+
+func (x *GenerateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_digits_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateRequest.ProtoReflect.Descriptor instead.
+func (*GenerateRequest) Descriptor() ([]byte, []int) {
+	return file_digits_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GenerateRequest) GetPreset() string {
+	if x != nil {
+		return x.Preset
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetDifficulty() int32 {
+	if x != nil {
+		return x.Difficulty
+	}
+	return 0
+}
+
+func (x *GenerateRequest) GetLarge() int32 {
+	if x != nil && x.Large != nil {
+		return *x.Large
+	}
+	return 0
+}
+
+func (x *GenerateRequest) GetMinRating() int32 {
+	if x != nil && x.MinRating != nil {
+		return *x.MinRating
+	}
+	return 0
+}
+
+func (x *GenerateRequest) GetMaxRating() int32 {
+	if x != nil && x.MaxRating != nil {
+		return *x.MaxRating
+	}
+	return 0
+}
+
+func (x *GenerateRequest) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+type GenerateResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Digits []int64 `protobuf:"varint,1,rep,packed,name=digits,proto3" json:"digits,omitempty"`
+	Target int64   `protobuf:"varint,2,opt,name=target,proto3" json:"target,omitempty"`
+	Rating int32   `protobuf:"varint,3,opt,name=rating,proto3" json:"rating,omitempty"`
+	// solution is the shortest solution, for revealing the answer, and hints lead up to it, each giving
+	// away more than the last.
+	Solution *Solution `protobuf:"bytes,4,opt,name=solution,proto3" json:"solution,omitempty"`
+	Hints    []string  `protobuf:"bytes,5,rep,name=hints,proto3" json:"hints,omitempty"`
+}
+
+func (x *GenerateResponse) Reset() {
+	*x = GenerateResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_digits_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GenerateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateResponse) ProtoMessage() {}
+
+func (x *GenerateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_digits_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateResponse.ProtoReflect.Descriptor instead.
+func (*GenerateResponse) Descriptor() ([]byte, []int) {
+	return file_digits_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GenerateResponse) GetDigits() []int64 {
+	if x != nil {
+		return x.Digits
+	}
+	return nil
+}
+
+func (x *GenerateResponse) GetTarget() int64 {
+	if x != nil {
+		return x.Target
+	}
+	return 0
+}
+
+func (x *GenerateResponse) GetRating() int32 {
+	if x != nil {
+		return x.Rating
+	}
+	return 0
+}
+
+func (x *GenerateResponse) GetSolution() *Solution {
+	if x != nil {
+		return x.Solution
+	}
+	return nil
+}
+
+func (x *GenerateResponse) GetHints() []string {
+	if x != nil {
+		return x.Hints
+	}
+	return nil
+}
+
+type VerifyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Digits []int64 `protobuf:"varint,1,rep,packed,name=digits,proto3" json:"digits,omitempty"`
+	Target int64   `protobuf:"varint,2,opt,name=target,proto3" json:"target,omitempty"`
+	// answer is written like "(9*7) + 25 + 5".
+	Answer string `protobuf:"bytes,3,opt,name=answer,proto3" json:"answer,omitempty"`
+}
+
+func (x *VerifyRequest) Reset() {
+	*x = VerifyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_digits_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyRequest) ProtoMessage() {}
+
+func (x *VerifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_digits_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyRequest.ProtoReflect.Descriptor instead.
+func (*VerifyRequest) Descriptor() ([]byte, []int) {
+	return file_digits_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *VerifyRequest) GetDigits() []int64 {
+	if x != nil {
+		return x.Digits
+	}
+	return nil
+}
+
+func (x *VerifyRequest) GetTarget() int64 {
+	if x != nil {
+		return x.Target
+	}
+	return 0
+}
+
+func (x *VerifyRequest) GetAnswer() string {
+	if x != nil {
+		return x.Answer
+	}
+	return ""
+}
+
+type VerifyResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Valid bool `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	// value is left out if the answer can't be worked out, like when it divides by zero.
+	Value      *int64   `protobuf:"varint,2,opt,name=value,proto3,oneof" json:"value,omitempty"`
+	Violations []string `protobuf:"bytes,3,rep,name=violations,proto3" json:"violations,omitempty"`
+}
+
+func (x *VerifyResponse) Reset() {
+	*x = VerifyResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_digits_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyResponse) ProtoMessage() {}
+
+func (x *VerifyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_digits_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyResponse.ProtoReflect.Descriptor instead.
+func (*VerifyResponse) Descriptor() ([]byte, []int) {
+	return file_digits_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *VerifyResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *VerifyResponse) GetValue() int64 {
+	if x != nil && x.Value != nil {
+		return *x.Value
+	}
+	return 0
+}
+
+func (x *VerifyResponse) GetViolations() []string {
+	if x != nil {
+		return x.Violations
+	}
+	return nil
+}
+
+var File_digits_proto protoreflect.FileDescriptor
+
+var file_digits_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x64, 0x69, 0x67, 0x69, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09,
+	0x64, 0x69, 0x67, 0x69, 0x74, 0x73, 0x2e, 0x76, 0x31, 0x22, 0xb7, 0x02, 0x0a, 0x0c, 0x53, 0x6f,
+	0x6c, 0x76, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69,
+	0x67, 0x69, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x03, 0x52, 0x06, 0x64, 0x69, 0x67, 0x69,
+	0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x6e, 0x6f,
+	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6e, 0x6f,
+	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x75, 0x6e, 0x69, 0x63, 0x6f, 0x64,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x75, 0x6e, 0x69, 0x63, 0x6f, 0x64, 0x65,
+	0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x61, 0x78, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x6d, 0x61, 0x78, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x6f, 0x5f, 0x64, 0x69, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x6e, 0x6f, 0x44, 0x69, 0x76, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x6f, 0x5f, 0x6e, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x6e, 0x6f, 0x4e, 0x65, 0x67, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x6f, 0x5f, 0x74, 0x72, 0x69, 0x76, 0x69, 0x61,
+	0x6c, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x6e, 0x6f, 0x54, 0x72, 0x69, 0x76, 0x69,
+	0x61, 0x6c, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x61, 0x78, 0x5f, 0x6f, 0x70, 0x73, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x06, 0x6d, 0x61, 0x78, 0x4f, 0x70, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6d,
+	0x61, 0x78, 0x5f, 0x64, 0x69, 0x67, 0x69, 0x74, 0x73, 0x5f, 0x75, 0x73, 0x65, 0x64, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x6d, 0x61, 0x78, 0x44, 0x69, 0x67, 0x69, 0x74, 0x73, 0x55,
+	0x73, 0x65, 0x64, 0x22, 0x74, 0x0a, 0x08, 0x53, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74,
+	0x65, 0x78, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6f, 0x70, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x6f, 0x70, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x64,
+	0x69, 0x67, 0x69, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x03, 0x52, 0x06, 0x64, 0x69, 0x67,
+	0x69, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x22, 0x74, 0x0a, 0x0a, 0x53, 0x6f, 0x6c,
+	0x76, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x31, 0x0a, 0x08, 0x73, 0x6f, 0x6c, 0x75, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x64, 0x69, 0x67, 0x69,
+	0x74, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00,
+	0x52, 0x08, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a, 0x04, 0x64, 0x6f,
+	0x6e, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x64, 0x69, 0x67, 0x69, 0x74,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6f, 0x6c, 0x76, 0x65, 0x44, 0x6f, 0x6e, 0x65, 0x48, 0x00,
+	0x52, 0x04, 0x64, 0x6f, 0x6e, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x22,
+	0x61, 0x0a, 0x09, 0x53, 0x6f, 0x6c, 0x76, 0x65, 0x44, 0x6f, 0x6e, 0x65, 0x12, 0x1c, 0x0a, 0x09,
+	0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x09, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x69, 0x6e,
+	0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a,
+	0x69, 0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x22, 0xe8, 0x01, 0x0a, 0x0f, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x73, 0x65, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x72, 0x65, 0x73, 0x65, 0x74, 0x12, 0x1e,
+	0x0a, 0x0a, 0x64, 0x69, 0x66, 0x66, 0x69, 0x63, 0x75, 0x6c, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0a, 0x64, 0x69, 0x66, 0x66, 0x69, 0x63, 0x75, 0x6c, 0x74, 0x79, 0x12, 0x19,
+	0x0a, 0x05, 0x6c, 0x61, 0x72, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52,
+	0x05, 0x6c, 0x61, 0x72, 0x67, 0x65, 0x88, 0x01, 0x01, 0x12, 0x22, 0x0a, 0x0a, 0x6d, 0x69, 0x6e,
+	0x5f, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x48, 0x01, 0x52,
+	0x09, 0x6d, 0x69, 0x6e, 0x52, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x88, 0x01, 0x01, 0x12, 0x22, 0x0a,
+	0x0a, 0x6d, 0x61, 0x78, 0x5f, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x05, 0x48, 0x02, 0x52, 0x09, 0x6d, 0x61, 0x78, 0x52, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x88, 0x01,
+	0x01, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x04, 0x73, 0x65, 0x65, 0x64, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x6c, 0x61, 0x72, 0x67, 0x65, 0x42,
+	0x0d, 0x0a, 0x0b, 0x5f, 0x6d, 0x69, 0x6e, 0x5f, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x42, 0x0d,
+	0x0a, 0x0b, 0x5f, 0x6d, 0x61, 0x78, 0x5f, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x22, 0xa1, 0x01,
+	0x0a, 0x10, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x69, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x03, 0x52, 0x06, 0x64, 0x69, 0x67, 0x69, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61,
+	0x72, 0x67, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67,
+	0x65, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x06, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x2f, 0x0a, 0x08, 0x73, 0x6f,
+	0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x64,
+	0x69, 0x67, 0x69, 0x74, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x08, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x68,
+	0x69, 0x6e, 0x74, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x68, 0x69, 0x6e, 0x74,
+	0x73, 0x22, 0x57, 0x0a, 0x0d, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x69, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x03, 0x52, 0x06, 0x64, 0x69, 0x67, 0x69, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61,
+	0x72, 0x67, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67,
+	0x65, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x22, 0x6b, 0x0a, 0x0e, 0x56, 0x65,
+	0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x69, 0x64, 0x12, 0x19, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x48, 0x00, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1e, 0x0a,
+	0x0a, 0x76, 0x69, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x0a, 0x76, 0x69, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x42, 0x08, 0x0a,
+	0x06, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x32, 0xc7, 0x01, 0x0a, 0x06, 0x44, 0x69, 0x67, 0x69,
+	0x74, 0x73, 0x12, 0x39, 0x0a, 0x05, 0x53, 0x6f, 0x6c, 0x76, 0x65, 0x12, 0x17, 0x2e, 0x64, 0x69,
+	0x67, 0x69, 0x74, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6f, 0x6c, 0x76, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x64, 0x69, 0x67, 0x69, 0x74, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x6f, 0x6c, 0x76, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x12, 0x43, 0x0a,
+	0x08, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x2e, 0x64, 0x69, 0x67, 0x69,
+	0x74, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x64, 0x69, 0x67, 0x69, 0x74, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x3d, 0x0a, 0x06, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x12, 0x18, 0x2e, 0x64,
+	0x69, 0x67, 0x69, 0x74, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x64, 0x69, 0x67, 0x69, 0x74, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x42, 0x27, 0x5a, 0x25, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x68, 0x75, 0x6c, 0x6b, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x6e, 0x2f, 0x64, 0x69, 0x67, 0x69, 0x74,
+	0x73, 0x2f, 0x64, 0x69, 0x67, 0x69, 0x74, 0x73, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_digits_proto_rawDescOnce sync.Once
+	file_digits_proto_rawDescData = file_digits_proto_rawDesc
+)
+
+func file_digits_proto_rawDescGZIP() []byte {
+	file_digits_proto_rawDescOnce.Do(func() {
+		file_digits_proto_rawDescData = protoimpl.X.CompressGZIP(file_digits_proto_rawDescData)
+	})
+	return file_digits_proto_rawDescData
+}
+
+var file_digits_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_digits_proto_goTypes = []any{
+	(*SolveRequest)(nil),     // 0: digits.v1.SolveRequest
+	(*Solution)(nil),         // 1: digits.v1.Solution
+	(*SolveEvent)(nil),       // 2: digits.v1.SolveEvent
+	(*SolveDone)(nil),        // 3: digits.v1.SolveDone
+	(*GenerateRequest)(nil),  // 4: digits.v1.GenerateRequest
+	(*GenerateResponse)(nil), // 5: digits.v1.GenerateResponse
+	(*VerifyRequest)(nil),    // 6: digits.v1.VerifyRequest
+	(*VerifyResponse)(nil),   // 7: digits.v1.VerifyResponse
+}
+var file_digits_proto_depIdxs = []int32{
+	1, // 0: digits.v1.SolveEvent.solution:type_name -> digits.v1.Solution
+	3, // 1: digits.v1.SolveEvent.done:type_name -> digits.v1.SolveDone
+	1, // 2: digits.v1.GenerateResponse.solution:type_name -> digits.v1.Solution
+	0, // 3: digits.v1.Digits.Solve:input_type -> digits.v1.SolveRequest
+	4, // 4: digits.v1.Digits.Generate:input_type -> digits.v1.GenerateRequest
+	6, // 5: digits.v1.Digits.Verify:input_type -> digits.v1.VerifyRequest
+	2, // 6: digits.v1.Digits.Solve:output_type -> digits.v1.SolveEvent
+	5, // 7: digits.v1.Digits.Generate:output_type -> digits.v1.GenerateResponse
+	7, // 8: digits.v1.Digits.Verify:output_type -> digits.v1.VerifyResponse
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_digits_proto_init() }
+func file_digits_proto_init() {
+	if File_digits_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_digits_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*SolveRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_digits_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*Solution); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_digits_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*SolveEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_digits_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*SolveDone); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_digits_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*GenerateRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_digits_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*GenerateResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_digits_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*VerifyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_digits_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*VerifyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_digits_proto_msgTypes[2].OneofWrappers = []any{
+		(*SolveEvent_Solution)(nil),
+		(*SolveEvent_Done)(nil),
+	}
+	file_digits_proto_msgTypes[4].OneofWrappers = []any{}
+	file_digits_proto_msgTypes[7].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_digits_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_digits_proto_goTypes,
+		DependencyIndexes: file_digits_proto_depIdxs,
+		MessageInfos:      file_digits_proto_msgTypes,
+	}.Build()
+	File_digits_proto = out.File
+	file_digits_proto_rawDesc = nil
+	file_digits_proto_goTypes = nil
+	file_digits_proto_depIdxs = nil
+}
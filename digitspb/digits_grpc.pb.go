@@ -0,0 +1,217 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v4.25.3
+// source: digits.proto
+
+// The gRPC API digits serve offers alongside its HTTP JSON API. The fields are like those of the JSON
+// requests and responses of the same names. Digits, targets and values are int64, so they
+// aren't cut short where the JSON API's aren't.
+
+package digitspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Digits_Solve_FullMethodName    = "/digits.v1.Digits/Solve"
+	Digits_Generate_FullMethodName = "/digits.v1.Digits/Generate"
+	Digits_Verify_FullMethodName   = "/digits.v1.Digits/Verify"
+)
+
+// DigitsClient is the client API for Digits service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Digits solves, generates and checks puzzles.
+type DigitsClient interface {
+	// Solve streams the solutions for a puzzle as they're found, so a client can show them as they arrive,
+	// then ends with a done event saying whether the search finished, like the JSON APIs' incomplete flag.
+	Solve(ctx context.Context, in *SolveRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SolveEvent], error)
+	// Generate returns a random puzzle which has been checked to be solvable.
+	Generate(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (*GenerateResponse, error)
+	// Verify checks an answer to a puzzle, reporting every rule it breaks.
+	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error)
+}
+
+type digitsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDigitsClient(cc grpc.ClientConnInterface) DigitsClient {
+	return &digitsClient{cc}
+}
+
+func (c *digitsClient) Solve(ctx context.Context, in *SolveRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SolveEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Digits_ServiceDesc.Streams[0], Digits_Solve_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SolveRequest, SolveEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Digits_SolveClient = grpc.ServerStreamingClient[SolveEvent]
+
+func (c *digitsClient) Generate(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (*GenerateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GenerateResponse)
+	err := c.cc.Invoke(ctx, Digits_Generate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *digitsClient) Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyResponse)
+	err := c.cc.Invoke(ctx, Digits_Verify_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DigitsServer is the server API for Digits service.
+// All implementations must embed UnimplementedDigitsServer
+// for forward compatibility.
+//
+// Digits solves, generates and checks puzzles.
+type DigitsServer interface {
+	// Solve streams the solutions for a puzzle as they're found, so a client can show them as they arrive,
+	// then ends with a done event saying whether the search finished, like the JSON APIs' incomplete flag.
+	Solve(*SolveRequest, grpc.ServerStreamingServer[SolveEvent]) error
+	// Generate returns a random puzzle which has been checked to be solvable.
+	Generate(context.Context, *GenerateRequest) (*GenerateResponse, error)
+	// Verify checks an answer to a puzzle, reporting every rule it breaks.
+	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
+	mustEmbedUnimplementedDigitsServer()
+}
+
+// UnimplementedDigitsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDigitsServer struct{}
+
+func (UnimplementedDigitsServer) Solve(*SolveRequest, grpc.ServerStreamingServer[SolveEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Solve not implemented")
+}
+func (UnimplementedDigitsServer) Generate(context.Context, *GenerateRequest) (*GenerateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Generate not implemented")
+}
+func (UnimplementedDigitsServer) Verify(context.Context, *VerifyRequest) (*VerifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Verify not implemented")
+}
+func (UnimplementedDigitsServer) mustEmbedUnimplementedDigitsServer() {}
+func (UnimplementedDigitsServer) testEmbeddedByValue()                {}
+
+// UnsafeDigitsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DigitsServer will
+// result in compilation errors.
+type UnsafeDigitsServer interface {
+	mustEmbedUnimplementedDigitsServer()
+}
+
+func RegisterDigitsServer(s grpc.ServiceRegistrar, srv DigitsServer) {
+	// If the following call pancis, it indicates UnimplementedDigitsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Digits_ServiceDesc, srv)
+}
+
+func _Digits_Solve_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SolveRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DigitsServer).Solve(m, &grpc.GenericServerStream[SolveRequest, SolveEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Digits_SolveServer = grpc.ServerStreamingServer[SolveEvent]
+
+func _Digits_Generate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DigitsServer).Generate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Digits_Generate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DigitsServer).Generate(ctx, req.(*GenerateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Digits_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DigitsServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Digits_Verify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DigitsServer).Verify(ctx, req.(*VerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Digits_ServiceDesc is the grpc.ServiceDesc for Digits service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Digits_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "digits.v1.Digits",
+	HandlerType: (*DigitsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Generate",
+			Handler:    _Digits_Generate_Handler,
+		},
+		{
+			MethodName: "Verify",
+			Handler:    _Digits_Verify_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Solve",
+			Handler:       _Digits_Solve_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "digits.proto",
+}
@@ -0,0 +1,4 @@
+// Package digitspb holds the Go bindings for digits.proto, the gRPC API of digits serve.
+package digitspb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative digits.proto
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"crypto/sha256"
+
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// diskCache keeps solutions in a directory, so repeat queries are answered without searching,
+// even by another run of the program. Each set of digits and options has a file of its own,
+// named by a hash of them. Files are replaced atomically, so concurrent runs don't corrupt
+// them, but one run's additions may replace another's.
+//
+// Each file is read once per run and kept in memory, and changes are written by flush, which
+// rewrites the whole file, so recording many targets doesn't rewrite it for each of them.
+// They're also written as they're made if flushInterval has passed since the last write,
+// so a run which is killed only loses what it found since then.
+type diskCache struct {
+	dir string
+	// entries holds the files read so far, by path, and dirty the paths of those changed since they were written.
+	entries   map[string]*cacheEntry
+	dirty     map[string]bool
+	lastFlush time.Time
+}
+
+// flushInterval is how often a diskCache writes its changes as they're made.
+const flushInterval = 5 * time.Second
+
+// cacheFilePattern matches the cache's files, so purging leaves anything else in the directory alone.
+const cacheFilePattern = "solutions-*.json"
+
+// cacheEntry is one of a diskCache's files: what's known about some digits solved with some options.
+type cacheEntry struct {
+	// Digits are in ascending order, as the order they're given in doesn't change the solutions.
+	Digits  []int  `json:"digits"`
+	Options string `json:"options"`
+	// Reachable holds every value which can be made from the digits, if it's been worked out.
+	Reachable []int `json:"reachable,omitempty"`
+	// Solutions maps each target solved to its solutions, written as S-expressions.
+	Solutions map[int][]string `json:"solutions"`
+}
+
+// cacheOptionsVersion starts every entry's Options, and changes with how they're written,
+// so entries saved by an older version are replaced rather than mistaken for current ones.
+const cacheOptionsVersion = "v2"
+
+// cacheOptions describes the options which change the solutions found, to keep those found with different ones apart.
+func cacheOptions(engine string, filter solutionFilter, maxResults int) string {
+	return fmt.Sprintf("%s engine=%s %s max_results=%d", cacheOptionsVersion, engine, filter.key(), maxResults)
+}
+
+// openDiskCache returns the cache in dir, creating the directory if it doesn't exist.
+func openDiskCache(dir string) (*diskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &diskCache{dir: dir, entries: make(map[string]*cacheEntry), dirty: make(map[string]bool), lastFlush: time.Now()}, nil
+}
+
+func (c *diskCache) path(digits []int, options string) string {
+	h := sha256.Sum256([]byte(fmt.Sprintf("%s|%s", formatDigits(digits), options)))
+	return filepath.Join(c.dir, strings.Replace(cacheFilePattern, "*", fmt.Sprintf("%x", h[:12]), 1))
+}
+
+// load returns the entry for digits and options, which is empty if nothing's been cached for them.
+// Changes to it are kept by calling changed, or save.
+func (c *diskCache) load(digits []int, options string) (*cacheEntry, error) {
+	digits = slices.Clone(digits)
+	sort.Ints(digits)
+	path := c.path(digits, options)
+	if e, ok := c.entries[path]; ok {
+		return e, nil
+	}
+	e, err := c.read(path, digits, options)
+	if err != nil {
+		return nil, err
+	}
+	c.entries[path] = e
+	return e, nil
+}
+
+// read returns the entry in the file at path for digits and options, which is empty if there isn't one.
+func (c *diskCache) read(path string, digits []int, options string) (*cacheEntry, error) {
+	want := &cacheEntry{Digits: digits, Options: options, Solutions: make(map[int][]string)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return want, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	// A file for other digits or options, whose hash happens to match, is replaced.
+	if !slices.Equal(e.Digits, digits) || e.Options != options {
+		return want, nil
+	}
+	if e.Solutions == nil {
+		e.Solutions = make(map[int][]string)
+	}
+	return &e, nil
+}
+
+// lookup returns the cached solutions for target from digits with options, and whether there were any.
+// A nil cache has nothing in it, and a cache which can't be read is treated as empty, with a warning.
+func (c *diskCache) lookup(digits []int, options string, target int) ([]expression, bool) {
+	if c == nil {
+		return nil, false
+	}
+	e, err := c.load(digits, options)
+	if err != nil {
+		slog.Warn("Not using the solution cache", "error", err)
+		return nil, false
+	}
+	solns, ok, err := e.solutions(target)
+	if err != nil {
+		slog.Warn("Ignoring the solution cache's bad entry", "error", err)
+		return nil, false
+	}
+	return solns, ok
+}
+
+// record adds every solution for target from digits with options to the cache, if it's set.
+// It warns rather than failing if it can't, as the cache is only an optimization.
+func (c *diskCache) record(digits []int, options string, target int, solns []expression) {
+	if c == nil {
+		return
+	}
+	e, err := c.load(digits, options)
+	if err != nil {
+		slog.Warn("Failed to save to the solution cache", "error", err)
+		return
+	}
+	e.setSolutions(target, solns)
+	c.changed(e)
+}
+
+// changed marks e, which came from load, as needing to be written, and writes every change if it's been
+// flushInterval since they were last written.
+func (c *diskCache) changed(e *cacheEntry) {
+	c.dirty[c.path(e.Digits, e.Options)] = true
+	if time.Since(c.lastFlush) >= flushInterval {
+		c.flush()
+	}
+}
+
+// flush writes every entry changed since it was last written. It does nothing to a nil cache,
+// and warns rather than failing, as the cache is only an optimization.
+func (c *diskCache) flush() {
+	if c == nil {
+		return
+	}
+	c.lastFlush = time.Now()
+	for path := range c.dirty {
+		if err := c.save(c.entries[path]); err != nil {
+			slog.Warn("Failed to save to the solution cache", "error", err)
+		}
+	}
+}
+
+// save writes e to the cache, replacing what was there.
+func (c *diskCache) save(e *cacheEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	path := c.path(e.Digits, e.Options)
+	delete(c.dirty, path)
+	return writeFileAtomic(path, data)
+}
+
+// solutions returns the cached solutions for target, and whether there were any cached.
+func (e *cacheEntry) solutions(target int) ([]expression, bool, error) {
+	strs, ok := e.Solutions[target]
+	if !ok {
+		return nil, false, nil
+	}
+	solns := make([]expression, 0, len(strs))
+	for _, s := range strs {
+		soln, err := parseSExpr(s)
+		if err != nil {
+			return nil, false, fmt.Errorf("target %d: %v", target, err)
+		}
+		if soln.Val != target {
+			return nil, false, fmt.Errorf("target %d: %s = %d", target, s, soln.Val)
+		}
+		solns = append(solns, soln)
+	}
+	return solns, true, nil
+}
+
+// setSolutions records the solutions for target, which must be all of them.
+func (e *cacheEntry) setSolutions(target int, solns []expression) {
+	strs := make([]string, len(solns))
+	for i, s := range solns {
+		strs[i] = formatSExpr(s, opStrings)
+	}
+	e.Solutions[target] = strs
+}
+
+// cacheStats describes what's in a diskCache.
+type cacheStats struct {
+	// Entries is the number of sets of digits and options cached, and Targets the number of targets solved between them.
+	Entries, Targets int
+	Bytes            int64
+}
+
+func (c *diskCache) stats() (cacheStats, error) {
+	paths, err := filepath.Glob(filepath.Join(c.dir, cacheFilePattern))
+	if err != nil {
+		return cacheStats{}, err
+	}
+	var st cacheStats
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return cacheStats{}, err
+		}
+		var e cacheEntry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return cacheStats{}, fmt.Errorf("%s: %v", p, err)
+		}
+		st.Entries++
+		st.Targets += len(e.Solutions)
+		st.Bytes += int64(len(data))
+	}
+	return st, nil
+}
+
+// purge deletes everything in the cache, and returns how many entries there were.
+func (c *diskCache) purge() (int, error) {
+	paths, err := filepath.Glob(filepath.Join(c.dir, cacheFilePattern))
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range paths {
+		if err := os.Remove(p); err != nil {
+			return 0, err
+		}
+	}
+	clear(c.entries)
+	clear(c.dirty)
+	return len(paths), nil
+}
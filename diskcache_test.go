@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_diskCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := openDiskCache(dir)
+	if err != nil {
+		t.Fatalf("openDiskCache() failed unexpectedly: %v", err)
+	}
+	digits := []int{25, 5, 7, 9}
+	options := cacheOptions("search", solutionFilter{}, 0)
+	if _, ok := c.lookup(digits, options, 93); ok {
+		t.Errorf("lookup() on an empty cache succeeded unexpectedly")
+	}
+
+	want := solve(93, digits)
+	c.record(digits, options, 93, want)
+	c.record(digits, options, 1000, nil)
+	// The digits may be given in any order.
+	for _, d := range [][]int{digits, {5, 7, 9, 25}} {
+		got, ok := c.lookup(d, options, 93)
+		if !ok {
+			t.Fatalf("lookup(%v) found nothing, want %d solutions", d, len(want))
+		}
+		if diff := cmp.Diff(fmt.Sprint(want), fmt.Sprint(got)); diff != "" {
+			t.Errorf("lookup(%v) mismatch (-want +got):\n%s", d, diff)
+		}
+	}
+	if got, ok := c.lookup(digits, options, 1000); !ok || len(got) != 0 {
+		t.Errorf("lookup() of a target without solutions = %v, %t; want none, true", got, ok)
+	}
+	if _, ok := c.lookup(digits, cacheOptions("search", solutionFilter{noDivision: true}, 0), 93); ok {
+		t.Errorf("lookup() with other options succeeded unexpectedly")
+	}
+
+	// Files which aren't the cache's are left alone.
+	other := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(other, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c.record([]int{1, 2}, options, 3, solve(3, []int{1, 2}))
+	// Nothing's written until the cache is flushed, and then another run finds it.
+	if st, err := c.stats(); err != nil || st.Entries != 0 {
+		t.Errorf("stats() before flush() = %+v, %v; want no entries", st, err)
+	}
+	c.flush()
+	reopened, err := openDiskCache(dir)
+	if err != nil {
+		t.Fatalf("openDiskCache() failed unexpectedly: %v", err)
+	}
+	if got, ok := reopened.lookup(digits, options, 93); !ok || len(got) != len(want) {
+		t.Errorf("lookup() after flush() = %d solutions, %t; want %d, true", len(got), ok, len(want))
+	}
+	st, err := c.stats()
+	if err != nil {
+		t.Fatalf("stats() failed unexpectedly: %v", err)
+	}
+	if st.Entries != 2 || st.Targets != 3 || st.Bytes == 0 {
+		t.Errorf("stats() = %+v, want 2 entries with 3 targets", st)
+	}
+	if n, err := c.purge(); err != nil || n != 2 {
+		t.Errorf("purge() = %d, %v; want 2 entries deleted", n, err)
+	}
+	if _, ok := c.lookup(digits, options, 93); ok {
+		t.Errorf("lookup() after purge() succeeded unexpectedly")
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("purge() deleted another file: %v", err)
+	}
+}
+
+func Test_cacheOptions(t *testing.T) {
+	got := cacheOptions("search", solutionFilter{noDivision: true, maxOps: 3, noTrivial: true}, 10)
+	want := "v2 engine=search no_division=true no_negation=false max_ops=3 max_digits_used=0 no_trivial=true max_results=10"
+	if got != want {
+		t.Errorf("cacheOptions() = %q, want %q", got, want)
+	}
+}
+
+func Test_solveCache_disk(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := openDiskCache(dir)
+	if err != nil {
+		t.Fatalf("openDiskCache() failed unexpectedly: %v", err)
+	}
+	digits := []int{5, 7, 9, 25}
+	want := fmt.Sprint(newSolveCache(digits, nil).solve(93))
+	if got := fmt.Sprint(newSolveCache(digits, disk).solve(93)); got != want {
+		t.Errorf("solve() = %s, want %s", got, want)
+	}
+	disk.flush()
+	// A new cache picks up what the first one saved.
+	if disk, err = openDiskCache(dir); err != nil {
+		t.Fatalf("openDiskCache() failed unexpectedly: %v", err)
+	}
+	c := newSolveCache(digits, disk)
+	if len(c.entry.Reachable) == 0 || len(c.entry.Solutions[93]) == 0 {
+		t.Fatalf("solve() didn't save to the disk: %+v", c.entry)
+	}
+	if got := fmt.Sprint(c.solve(93)); got != want {
+		t.Errorf("solve() from the disk = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// step is a single binary operation in a worked solution.
+type step struct {
+	Op     operation
+	A, B   int
+	Result int
+	// AStep and BStep are the indexes of the steps which produced A and B, or -1 for input digits.
+	AStep, BStep int
+}
+
+func (s step) String() string {
+	return s.format(opStrings)
+}
+
+// format writes s as an equation, e.g. "9 * 7 = 63".
+func (s step) format(sym symbols) string {
+	return fmt.Sprintf("%d %s %d = %d", s.A, sym[s.Op], s.B, s.Result)
+}
+
+// steps returns the binary operations needed to evaluate e, in the order a person would perform them.
+func (e expression) steps() []step {
+	var out []step
+	// walk returns the index of the step producing e, or -1 if e is a digit.
+	var walk func(e expression) int
+	walk = func(e expression) int {
+		refs := make([]int, len(e.Children))
+		for i, c := range e.Children {
+			refs[i] = walk(*c)
+		}
+		switch e.Op {
+		case opNone:
+			return -1
+		case opNegate:
+			// Negation only survives withSubtraction when every term was negated,
+			// which can't happen for a positive result, so treat it as 0 - x.
+			out = append(out, step{Op: opSubtract, A: 0, B: e.Children[0].Val, Result: e.Val, AStep: -1, BStep: refs[0]})
+		default:
+			out = append(out, step{Op: e.Op, A: e.Children[0].Val, B: e.Children[1].Val, Result: e.Val, AStep: refs[0], BStep: refs[1]})
+		}
+		return len(out) - 1
+	}
+	walk(e.withSubtraction().unfuse())
+	return out
+}
+
+// explain describes how to evaluate e as English sentences, e.g.
+// "Multiply 9 by 7 to get 63, then add 25 to get 88, then add 5 to get 93."
+func explain(e expression) string {
+	steps := e.steps()
+	if len(steps) == 0 {
+		return fmt.Sprintf("Use %d as it is.", e.Val)
+	}
+
+	var sentences []string
+	var clauses []string
+	for i, s := range steps {
+		if i > 0 && (s.AStep == i-1 || s.BStep == i-1) {
+			clauses = append(clauses, "then "+continuingClause(s, s.AStep == i-1))
+			continue
+		}
+		if len(clauses) > 0 {
+			sentences = append(sentences, strings.Join(clauses, ", ")+".")
+		}
+		clauses = []string{standaloneClause(s)}
+	}
+	sentences = append(sentences, strings.Join(clauses, ", ")+".")
+	return strings.Join(sentences, " ")
+}
+
+func standaloneClause(s step) string {
+	var verb string
+	switch s.Op {
+	case opAdd:
+		verb = fmt.Sprintf("Add %d and %d", s.A, s.B)
+	case opSubtract:
+		verb = fmt.Sprintf("Subtract %d from %d", s.B, s.A)
+	case opMultiply:
+		verb = fmt.Sprintf("Multiply %d by %d", s.A, s.B)
+	case opDivide:
+		verb = fmt.Sprintf("Divide %d by %d", s.A, s.B)
+	}
+	return fmt.Sprintf("%s to get %d", verb, s.Result)
+}
+
+// continuingClause describes s when one of its operands is the previous step's result.
+// leftIsPrevious reports whether that operand is s.A.
+func continuingClause(s step, leftIsPrevious bool) string {
+	other := s.B
+	if !leftIsPrevious {
+		other = s.A
+	}
+
+	var verb string
+	switch {
+	case s.Op == opAdd:
+		verb = fmt.Sprintf("add %d", other)
+	case s.Op == opMultiply:
+		verb = fmt.Sprintf("multiply by %d", other)
+	case s.Op == opSubtract && leftIsPrevious:
+		verb = fmt.Sprintf("subtract %d", other)
+	case s.Op == opSubtract:
+		verb = fmt.Sprintf("subtract that from %d", other)
+	case s.Op == opDivide && leftIsPrevious:
+		verb = fmt.Sprintf("divide by %d", other)
+	case s.Op == opDivide:
+		verb = fmt.Sprintf("divide %d by that", other)
+	}
+	return fmt.Sprintf("%s to get %d", verb, s.Result)
+}
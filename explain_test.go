@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_explain(t *testing.T) {
+	tests := map[string]struct {
+		expr expression
+		want string
+	}{
+		"constant": {
+			expr: makeConstant(7),
+			want: "Use 7 as it is.",
+		},
+		"chain": {
+			expr: makeAdd(makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25)), makeConstant(5)).fuse(),
+			want: "Multiply 9 by 7 to get 63, then add 25 to get 88, then add 5 to get 93.",
+		},
+		"subtraction": {
+			expr: makeDivide(makeAdd(makeMultiply(makeConstant(25), makeConstant(4)), makeNegate(makeConstant(9))), makeConstant(7)),
+			want: "Multiply 25 by 4 to get 100, then subtract 9 to get 91, then divide by 7 to get 13.",
+		},
+		"previous on right": {
+			expr: makeDivide(makeConstant(100), makeAdd(makeConstant(15), makeConstant(10))),
+			want: "Add 15 and 10 to get 25, then divide 100 by that to get 4.",
+		},
+		"independent steps": {
+			expr: makeMultiply(makeAdd(makeConstant(2), makeConstant(3)), makeAdd(makeConstant(4), makeConstant(5))),
+			want: "Add 2 and 3 to get 5. Add 4 and 5 to get 9, then multiply by 5 to get 45.",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := explain(tt.expr)
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("explain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// familyKind chooses what makes solutions part of the same family.
+type familyKind int
+
+const (
+	// familyNone doesn't group solutions.
+	familyNone familyKind = iota
+	// familySkeleton groups solutions with the same operations arranged the same way, whatever numbers they use.
+	familySkeleton
+	// familyIntermediate groups solutions whose last step uses the same biggest value, like 9*7 + 25 + 5 and 9*7 + 30
+	// both using 63.
+	familyIntermediate
+	// familyDistributive groups solutions which are the same once common factors are taken out of sums,
+	// like 7*5 + 3*5 and (7 + 3)*5.
+	familyDistributive
+)
+
+var familyKindNames = map[string]familyKind{
+	"none":         familyNone,
+	"skeleton":     familySkeleton,
+	"intermediate": familyIntermediate,
+	"distributive": familyDistributive,
+}
+
+func parseFamilyKind(s string) (familyKind, error) {
+	k, ok := familyKindNames[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown family %q", s)
+	}
+	return k, nil
+}
+
+// solutionFamily is a group of solutions which are alike, represented by the shortest of them.
+type solutionFamily struct {
+	Key            string
+	Representative expression
+	Members        []expression
+}
+
+// groupFamilies groups solns into families of the kind given, biggest first. Families of the same size,
+// and the members of each, keep the order they were found in.
+func groupFamilies(solns []expression, kind familyKind) []solutionFamily {
+	var families []solutionFamily
+	index := make(map[string]int)
+	for _, e := range solns {
+		key := e.familyKey(kind)
+		i, ok := index[key]
+		if !ok {
+			i = len(families)
+			index[key] = i
+			families = append(families, solutionFamily{Key: key})
+		}
+		families[i].Members = append(families[i].Members, e)
+	}
+	for i := range families {
+		families[i].Representative, _ = shortest(families[i].Members)
+	}
+	sort.SliceStable(families, func(i, j int) bool { return len(families[i].Members) > len(families[j].Members) })
+	return families
+}
+
+// familyKey returns a key which is the same for solutions in the same family of the kind given.
+func (e expression) familyKey(kind familyKind) string {
+	switch kind {
+	case familySkeleton:
+		return e.skeleton()
+	case familyIntermediate:
+		key := abs(e.Val)
+		if len(e.Children) > 0 {
+			key = 0
+			for _, c := range e.Children {
+				key = max(key, abs(c.Val))
+			}
+		}
+		return strconv.Itoa(key)
+	case familyDistributive:
+		return formatSExpr(e.factored(), opStrings)
+	}
+	return formatSExpr(e, opStrings)
+}
+
+// skeleton writes e as an S-expression with every number replaced by n, so it only depends on the operations
+// and how they're arranged. Subtracting is treated like adding, and a sum or product lists each different
+// kind of operand once, in sorted order, so adding one more number doesn't make a new skeleton.
+func (e expression) skeleton() string {
+	switch e.Op {
+	case opNone:
+		return "n"
+	case opNegate:
+		return e.Children[0].skeleton()
+	}
+	parts := make([]string, len(e.Children))
+	for i, c := range e.Children {
+		parts[i] = c.skeleton()
+	}
+	if e.Op.commutative() {
+		sort.Strings(parts)
+		parts = slices.Compact(parts)
+	}
+	return fmt.Sprintf("(%s %s)", opStrings[e.Op], strings.Join(parts, " "))
+}
+
+// factored returns e with common factors taken out of its sums wherever they can be, so solutions which only
+// differ by multiplying out, like 7*5 + 3*5 and (7 + 3)*5, factor the same way. In each sum, the factor shared by
+// the most terms which are products is taken out of them first, until no two share one. e must be normalized
+// like solve's solutions.
+func (e expression) factored() expression {
+	if e.Op == opNone {
+		return e
+	}
+	children := make([]*expression, len(e.Children))
+	for i, c := range e.Children {
+		f := c.factored()
+		children[i] = &f
+	}
+	e.Children = children
+	e = e.fuse().canonicalize()
+	for e.Op == opAdd {
+		key, terms := e.commonFactor()
+		if len(terms) < 2 {
+			break
+		}
+		var factor expression
+		var rest, others []*expression
+		for i, c := range e.Children {
+			if !slices.Contains(terms, i) {
+				others = append(others, c)
+				continue
+			}
+			term, negated := c, false
+			if term.Op == opNegate {
+				term, negated = term.Children[0], true
+			}
+			r := term.without(key)
+			if negated {
+				r = makeNegate(r)
+			}
+			rest = append(rest, &r)
+			factor = *term.Children[slices.IndexFunc(term.Children, func(f *expression) bool { return formatSExpr(*f, opStrings) == key })]
+		}
+		sum := expression{Op: opAdd, Children: rest}
+		for _, r := range rest {
+			sum.Val += r.Val
+		}
+		product := makeMultiply(factor, sum.fuse().canonicalize()).fuse().canonicalize()
+		if len(others) == 0 {
+			return product
+		}
+		e = expression{Val: e.Val, Op: opAdd, Children: append(others, &product)}.fuse().canonicalize()
+	}
+	return e
+}
+
+// commonFactor returns the factor shared by the most of sum e's terms which are products, written as an S-expression,
+// and the indices of those terms. Ties go to the factor which sorts first.
+func (e expression) commonFactor() (string, []int) {
+	shared := make(map[string][]int)
+	for i, c := range e.Children {
+		if c.Op == opNegate {
+			c = c.Children[0]
+		}
+		if c.Op != opMultiply {
+			continue
+		}
+		counted := make(map[string]bool)
+		for _, f := range c.Children {
+			key := formatSExpr(*f, opStrings)
+			if !counted[key] {
+				counted[key] = true
+				shared[key] = append(shared[key], i)
+			}
+		}
+	}
+	var best string
+	for key, terms := range shared {
+		if len(terms) > len(shared[best]) || (len(terms) == len(shared[best]) && key < best) {
+			best = key
+		}
+	}
+	return best, shared[best]
+}
+
+// without returns product e with one of its factors written as key taken out.
+func (e *expression) without(key string) expression {
+	var rest []*expression
+	removed := false
+	for _, f := range e.Children {
+		if !removed && formatSExpr(*f, opStrings) == key {
+			removed = true
+			continue
+		}
+		rest = append(rest, f)
+	}
+	if len(rest) == 1 {
+		return *rest[0]
+	}
+	val := 1
+	for _, f := range rest {
+		val *= f.Val
+	}
+	return expression{Val: val, Op: opMultiply, Children: rest}
+}
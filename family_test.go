@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_groupFamilies(t *testing.T) {
+	solns := []expression{
+		makeAdd(makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25)), makeConstant(5)).fuse(),
+		makeAdd(makeMultiply(makeConstant(15), makeConstant(7)), makeConstant(8)),
+		makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(30)),
+		makeAdd(makeMultiply(makeConstant(10), makeConstant(9)), makeConstant(3)),
+		makeAdd(makeMultiply(makeAdd(makeConstant(9), makeConstant(4)), makeConstant(7)), makeConstant(2)),
+	}
+	p := newPrinter(notationInfix, false)
+
+	tests := map[string]struct {
+		kind familyKind
+		// want has each family's representative and its number of members.
+		want []string
+	}{
+		"skeleton": {
+			kind: familySkeleton,
+			want: []string{"15*7 + 8 x4", "(9 + 4)*7 + 2 x1"},
+		},
+		"intermediate": {
+			kind: familyIntermediate,
+			want: []string{"9*7 + 30 x2", "15*7 + 8 x1", "10*9 + 3 x1", "(9 + 4)*7 + 2 x1"},
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			var got []string
+			for _, f := range groupFamilies(solns, tt.kind) {
+				got = append(got, fmt.Sprintf("%s x%d", p.format(f.Representative), len(f.Members)))
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("groupFamilies() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_expression_factored(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		want  string
+	}{
+		"common factor":     {input: "7*5 + 3*5", want: "(7 + 3)*5"},
+		"already factored":  {input: "(7 + 3)*5", want: "(7 + 3)*5"},
+		"subtracted term":   {input: "9*4 - 2*4", want: "(9 - 2)*4"},
+		"other terms":       {input: "7*5 + 3*5 + 2", want: "(7 + 3)*5 + 2"},
+		"most shared first": {input: "2*3 + 2*5 + 7*5 + 9*5", want: "(9 + 7 + 2)*5 + 3*2"},
+		"no common factor":  {input: "7*5 + 3*2", want: "7*5 + 3*2"},
+		"nested":            {input: "(7*5 + 3*5)*2 + 1", want: "(7 + 3)*5*2 + 1"},
+	}
+	p := newPrinter(notationInfix, false)
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			e, err := parseInfix(tt.input)
+			if err != nil {
+				t.Fatalf("parseInfix() failed unexpectedly: %v", err)
+			}
+			e = normalized(e)
+			got := e.factored()
+			if got.Val != e.Val {
+				t.Errorf("factored() = %s with value %d, want %d", p.format(got), got.Val, e.Val)
+			}
+			if diff := cmp.Diff(tt.want, p.format(got)); diff != "" {
+				t.Errorf("factored() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// normalized returns e fused and canonicalized from the bottom up, like solve's solutions are built.
+func normalized(e expression) expression {
+	if e.Op == opNone {
+		return e
+	}
+	children := make([]*expression, len(e.Children))
+	for i, c := range e.Children {
+		n := normalized(*c)
+		children[i] = &n
+	}
+	e.Children = children
+	return e.fuse().canonicalize()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"math/bits"
+	"time"
+)
+
+// solveFewest finds the solutions for target with the fewest operations, without searching for longer ones.
+// Each operation joins one more digit, so it searches every group of one digit, then every group of two,
+// and so on, stopping after the first size which has at least n solutions between its groups and the smaller ones.
+// It returns every solution of the sizes searched, fewest operations first.
+// The groups share a memo, so each size reuses the sub-searches of the ones before.
+func (s solver) solveFewest(target int, digits []int, n int) []expression {
+	if s.stopped() {
+		return nil
+	}
+	pool, _ := sortDigits(digits)
+	s.pool = pool
+	s.arena = &exprArena{}
+	sub := s.subSolver(pool)
+
+	var solutions []expression
+	var seen expressionSet
+	all := uint(1)<<len(digits) - 1
+	for size := 1; size <= len(digits); size++ {
+		for mask := uint(1); mask <= all; mask++ {
+			// Groups which only swap copies of a repeated digit have the same solutions.
+			if bits.OnesCount(mask) != size || s.canonicalMask(mask) != mask {
+				continue
+			}
+			if s.stopped() {
+				return solutions
+			}
+			var buf [maxDigitCount]int
+			choices := buf[:0]
+			for m := mask; m != 0; m &= m - 1 {
+				choices = append(choices, bits.TrailingZeros(m))
+			}
+			group := s
+			if s.limit > 0 {
+				group.limit = s.limit - len(solutions)
+			}
+			// Solutions with fewer digits were found by an earlier size.
+			for _, e := range group.search(target, mask, choices, sub) {
+				if seen.add(e) {
+					solutions = append(solutions, e)
+				}
+			}
+			if s.limit > 0 && len(solutions) >= s.limit {
+				return solutions
+			}
+		}
+		if len(solutions) >= n {
+			s.debug("Found solutions with the fewest operations", "target", target, "operations", size-1, "found", len(solutions))
+			return solutions
+		}
+	}
+	return solutions
+}
+
+// solveFewestWithin is solveFewest bounded by timeout, if it's positive, like solveWithin.
+// The subsets and splits engines find every solution, so the caller picks the shortest.
+func (s solver) solveFewestWithin(timeout time.Duration, target int, digits []int, n int) ([]expression, bool) {
+	if s.engine != engineSearch {
+		return s.solveWithin(timeout, target, digits)
+	}
+	if timeout > 0 {
+		parent := s.ctx
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+		s.ctx = ctx
+	}
+	solns := s.solveFewest(target, digits, n)
+	return solns, s.stopped()
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_solver_solveFewest(t *testing.T) {
+	tests := map[string]struct {
+		digits  []int
+		target  int
+		n       int
+		wantOps []int
+	}{
+		"single digit":     {digits: []int{5, 7, 9, 10, 15, 25}, target: 25, n: 1, wantOps: []int{0}},
+		"fewest":           {digits: []int{5, 7, 9, 10, 15, 25}, target: 93, n: 1, wantOps: []int{3}},
+		"more than fewest": {digits: []int{5, 7, 9, 10, 15, 25}, target: 93, n: 10, wantOps: []int{3, 4}},
+		"repeated digits":  {digits: []int{2, 2, 3, 3}, target: 12, n: 1, wantOps: []int{2}},
+		"no solution":      {digits: []int{2, 3}, target: 100, n: 1},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := solver{}.solveFewest(tt.target, tt.digits, tt.n)
+			// Solutions should come fewest operations first, and include every one with no more than the most found.
+			most := -1
+			var gotOps []int
+			for i, e := range got {
+				ops := len(e.steps())
+				if i > 0 && ops < len(got[i-1].steps()) {
+					t.Errorf("solveFewest() returned %s after %s, which has more operations", e, got[i-1])
+				}
+				if len(gotOps) == 0 || gotOps[len(gotOps)-1] != ops {
+					gotOps = append(gotOps, ops)
+				}
+				most = ops
+			}
+			if diff := cmp.Diff(tt.wantOps, gotOps); diff != "" {
+				t.Errorf("solveFewest() operation counts mismatch (-want +got):\n%s", diff)
+			}
+			var want []string
+			for _, e := range solve(tt.target, tt.digits) {
+				if len(e.steps()) <= most {
+					want = append(want, e.String())
+				}
+			}
+			var gotStrs []string
+			for _, e := range got {
+				gotStrs = append(gotStrs, e.String())
+			}
+			sort.Strings(want)
+			sort.Strings(gotStrs)
+			if diff := cmp.Diff(want, gotStrs); diff != "" {
+				t.Errorf("solveFewest() mismatch with solve()'s solutions (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
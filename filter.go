@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// solutionFilter restricts which solutions are kept. The zero value keeps every solution.
+type solutionFilter struct {
+	// noDivision drops solutions which divide.
+	noDivision bool
+	// noNegation drops solutions which subtract or negate.
+	noNegation bool
+	// maxOps, if positive, drops solutions needing more binary operations.
+	maxOps int
+	// maxDigits, if positive, drops solutions using more digits.
+	maxDigits int
+	// noTrivial drops solutions with a part which does nothing, like multiplying by something making 1.
+	noTrivial bool
+}
+
+// key describes the filter by its flags, for the keys of the solution cache and range checkpoints.
+// It's spelled out field by field, so renaming a field doesn't change what's already been saved.
+func (f solutionFilter) key() string {
+	return fmt.Sprintf("no_division=%t no_negation=%t max_ops=%d max_digits_used=%d no_trivial=%t",
+		f.noDivision, f.noNegation, f.maxOps, f.maxDigits, f.noTrivial)
+}
+
+func (f solutionFilter) validate() error {
+	if f.maxOps < 0 {
+		return fmt.Errorf("max operations must not be negative, got %d", f.maxOps)
+	}
+	if f.maxDigits < 0 {
+		return fmt.Errorf("max digits must not be negative, got %d", f.maxDigits)
+	}
+	return nil
+}
+
+// keep reports whether e passes every condition of the filter.
+func (f solutionFilter) keep(e expression) bool {
+	if (f.noDivision && e.uses(opDivide)) || (f.noNegation && (e.uses(opNegate) || e.uses(opSubtract))) {
+		return false
+	}
+	if f.maxOps > 0 && len(e.steps()) > f.maxOps {
+		return false
+	}
+	if f.maxDigits > 0 && len(e.digits()) > f.maxDigits {
+		return false
+	}
+	if f.noTrivial && e.trivial() {
+		return false
+	}
+	return true
+}
+
+// trivial reports whether some of the terms of a sum in e add up to 0, or some of the factors of a product
+// make 1, with other terms or factors left for them to change. The values made along the way count, not
+// just the digits, so 5 * (4 - 3), 5 + 3 - 3 and 6 * 3 / 3 are all trivial, as is 5 * 1.
+func (e expression) trivial() bool {
+	switch e.Op {
+	case opNone:
+		return false
+	case opAdd, opSubtract, opNegate:
+		var terms []*expression
+		var values []int
+		var add func(c *expression, negated bool)
+		add = func(c *expression, negated bool) {
+			switch c.Op {
+			case opAdd:
+				for _, gc := range c.Children {
+					add(gc, negated)
+				}
+			case opSubtract:
+				add(c.Children[0], negated)
+				add(c.Children[1], !negated)
+			case opNegate:
+				add(c.Children[0], !negated)
+			default:
+				terms = append(terms, c)
+				if negated {
+					values = append(values, -c.Val)
+				} else {
+					values = append(values, c.Val)
+				}
+			}
+		}
+		add(&e, false)
+		for part := 1; part < 1<<len(values)-1; part++ {
+			total := 0
+			for i, v := range values {
+				if part&(1<<i) != 0 {
+					total += v
+				}
+			}
+			if total == 0 {
+				return true
+			}
+		}
+		return anyTrivial(terms)
+	default:
+		var factors []*expression
+		var divides []bool
+		var add func(c *expression, inverted bool)
+		add = func(c *expression, inverted bool) {
+			switch c.Op {
+			case opMultiply:
+				for _, gc := range c.Children {
+					add(gc, inverted)
+				}
+			case opDivide:
+				add(c.Children[0], inverted)
+				add(c.Children[1], !inverted)
+			default:
+				factors = append(factors, c)
+				divides = append(divides, inverted)
+			}
+		}
+		add(&e, false)
+		one := big.NewRat(1, 1)
+		for part := 1; part < 1<<len(factors)-1; part++ {
+			product := big.NewRat(1, 1)
+			for i, c := range factors {
+				if part&(1<<i) == 0 {
+					continue
+				}
+				if divides[i] {
+					product.Quo(product, big.NewRat(int64(c.Val), 1))
+				} else {
+					product.Mul(product, big.NewRat(int64(c.Val), 1))
+				}
+			}
+			if product.Cmp(one) == 0 {
+				return true
+			}
+		}
+		return anyTrivial(factors)
+	}
+}
+
+func anyTrivial(es []*expression) bool {
+	for _, e := range es {
+		if e.trivial() {
+			return true
+		}
+	}
+	return false
+}
+
+// uses reports whether op appears anywhere in e.
+func (e expression) uses(op operation) bool {
+	if e.Op == op {
+		return true
+	}
+	for _, c := range e.Children {
+		if c.uses(op) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"testing"
+)
+
+func Test_solutionFilter(t *testing.T) {
+	divide := makeAdd(makeDivide(makeConstant(25), makeConstant(5)), makeConstant(4))
+	subtract := makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeNegate(makeConstant(3)))
+	timesOne := makeMultiply(makeConstant(5), makeAdd(makeConstant(4), makeNegate(makeConstant(3))))
+	cancelled := makeAdd(makeAdd(makeConstant(5), makeConstant(3)), makeNegate(makeConstant(3))).fuse()
+	divideOut := makeDivide(makeMultiply(makeConstant(6), makeConstant(3)), makeConstant(3)).fuse()
+	nested := makeAdd(makeConstant(7), makeDivide(makeConstant(9), makeDivide(makeConstant(3), makeConstant(3))))
+	long := makeAdd(makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25)), makeConstant(5)).fuse()
+
+	tests := map[string]struct {
+		filter solutionFilter
+		expr   expression
+		want   bool
+	}{
+		"zero keeps everything": {
+			expr: divide,
+			want: true,
+		},
+		"no division": {
+			filter: solutionFilter{noDivision: true},
+			expr:   divide,
+			want:   false,
+		},
+		"no division allows subtraction": {
+			filter: solutionFilter{noDivision: true},
+			expr:   subtract,
+			want:   true,
+		},
+		"no negation": {
+			filter: solutionFilter{noNegation: true},
+			expr:   subtract,
+			want:   false,
+		},
+		"max ops": {
+			filter: solutionFilter{maxOps: 2},
+			expr:   long,
+			want:   false,
+		},
+		"max ops at limit": {
+			filter: solutionFilter{maxOps: 3},
+			expr:   long,
+			want:   true,
+		},
+		"max digits": {
+			filter: solutionFilter{maxDigits: 3},
+			expr:   long,
+			want:   false,
+		},
+		"no trivial keeps useful parts": {
+			filter: solutionFilter{noTrivial: true},
+			expr:   long,
+			want:   true,
+		},
+		"no trivial multiplying by a derived 1": {
+			filter: solutionFilter{noTrivial: true},
+			expr:   timesOne,
+			want:   false,
+		},
+		"no trivial terms cancelling": {
+			filter: solutionFilter{noTrivial: true},
+			expr:   cancelled,
+			want:   false,
+		},
+		"no trivial factors cancelling": {
+			filter: solutionFilter{noTrivial: true},
+			expr:   divideOut,
+			want:   false,
+		},
+		"no trivial nested": {
+			filter: solutionFilter{noTrivial: true},
+			expr:   nested,
+			want:   false,
+		},
+		"no trivial allows a whole solution making 1": {
+			filter: solutionFilter{noTrivial: true},
+			expr:   makeDivide(makeConstant(3), makeConstant(3)),
+			want:   true,
+		},
+		"combined": {
+			filter: solutionFilter{noDivision: true, maxDigits: 3},
+			expr:   subtract,
+			want:   true,
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			if got := tt.filter.keep(tt.expr); got != tt.want {
+				t.Errorf("keep(%s) = %t, want %t", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// notation controls how expressions are written out.
+type notation int
+
+const (
+	// notationInfix is infix with only the parentheses required by operator precedence.
+	notationInfix notation = iota
+	// notationFull is the fully parenthesized infix form produced by expression.String.
+	notationFull
+	// notationBinary is the fully parenthesized form with n-ary operations expanded into binary steps.
+	notationBinary
+	// notationRPN is reverse Polish (postfix) notation.
+	notationRPN
+	// notationSExpr is a Lisp-style prefix form which can be read back with parseSExpr.
+	notationSExpr
+)
+
+var notationNames = map[string]notation{
+	"infix":  notationInfix,
+	"full":   notationFull,
+	"binary": notationBinary,
+	"rpn":    notationRPN,
+	"sexpr":  notationSExpr,
+}
+
+func parseNotation(s string) (notation, error) {
+	n, ok := notationNames[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown notation %q", s)
+	}
+	return n, nil
+}
+
+// symbols maps operations to the strings used to print them.
+type symbols map[operation]string
+
+// unicodeSymbols uses proper typographic symbols for human-facing output.
+var unicodeSymbols = symbols{
+	opAdd:      "+",
+	opSubtract: "−",
+	opMultiply: "×",
+	opDivide:   "÷",
+	opNegate:   "−",
+}
+
+// printer writes expressions in a particular notation and set of symbols.
+type printer struct {
+	notation notation
+	symbols  symbols
+}
+
+// newPrinter returns a printer for n, using unicode symbols if requested or ASCII otherwise.
+func newPrinter(n notation, unicode bool) printer {
+	if unicode {
+		return printer{notation: n, symbols: unicodeSymbols}
+	}
+	return printer{notation: n, symbols: opStrings}
+}
+
+func (p printer) format(e expression) string {
+	e = e.withSubtraction()
+	switch p.notation {
+	case notationInfix:
+		return formatInfix(e, p.symbols)
+	case notationBinary:
+		return formatFull(e.unfuse(), p.symbols)
+	case notationRPN:
+		return formatRPN(e, p.symbols)
+	case notationSExpr:
+		return formatSExpr(e, p.symbols)
+	}
+	return formatFull(e, p.symbols)
+}
+
+// formatFull writes e with every operation parenthesized, e.g. "((9 * 7) + 25 + 5)".
+func formatFull(e expression, sym symbols) string {
+	if e.Op == opNone {
+		return fmt.Sprintf("%d", e.Val)
+	}
+
+	if e.Op == opNegate {
+		if len(e.Children) != 1 {
+			panic(fmt.Sprintf("want 1 operand for negate, got %d", len(e.Children)))
+		}
+		return fmt.Sprintf("%s%s", sym[opNegate], formatFull(*e.Children[0], sym))
+	}
+
+	children := make([]string, len(e.Children))
+	for i, c := range e.Children {
+		children[i] = formatFull(*c, sym)
+	}
+
+	return fmt.Sprintf("(%s)", strings.Join(children, fmt.Sprintf(" %s ", sym[e.Op])))
+}
+
+// outputFormat controls the overall layout of the results.
+type outputFormat int
+
+const (
+	// outputText is plain text, one solution per line.
+	outputText outputFormat = iota
+	// outputLaTeX is a standalone LaTeX document.
+	outputLaTeX
+	// outputMathML is an HTML fragment with MathML equations.
+	outputMathML
+	// outputMermaid is a Markdown document with a Mermaid flowchart per solution.
+	outputMermaid
+)
+
+var outputFormatNames = map[string]outputFormat{
+	"text":    outputText,
+	"latex":   outputLaTeX,
+	"mathml":  outputMathML,
+	"mermaid": outputMermaid,
+}
+
+func parseOutputFormat(s string) (outputFormat, error) {
+	f, ok := outputFormatNames[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown format %q", s)
+	}
+	return f, nil
+}
+
+func makeBinary(op operation, a, b expression) expression {
+	switch op {
+	case opAdd:
+		return makeAdd(a, b)
+	case opSubtract:
+		return makeSubtract(a, b)
+	case opMultiply:
+		return makeMultiply(a, b)
+	case opDivide:
+		return makeDivide(a, b)
+	}
+	panic(fmt.Sprintf("%v is not a binary operation", op))
+}
+
+// withSubtraction rewrites sums containing negated terms like (a + b + -c + -d) as
+// subtractions ((a + b) - c - d), which is how people write arithmetic by hand.
+func (e expression) withSubtraction() expression {
+	if len(e.Children) == 0 {
+		return e
+	}
+
+	var positives, negatives []*expression
+	for _, c := range e.Children {
+		c := c.withSubtraction()
+		if e.Op == opAdd && c.Op == opNegate {
+			negatives = append(negatives, c.Children[0])
+		} else {
+			positives = append(positives, &c)
+		}
+	}
+
+	if len(negatives) == 0 {
+		return expression{Val: e.Val, Op: e.Op, Children: positives}
+	}
+	if len(positives) == 0 {
+		return makeNegate(expression{Val: -e.Val, Op: opAdd, Children: negatives})
+	}
+
+	head := positives[0]
+	if len(positives) > 1 {
+		sum := expression{Op: opAdd, Children: positives}
+		for _, p := range positives {
+			sum.Val += p.Val
+		}
+		head = &sum
+	}
+	return expression{Val: e.Val, Op: opSubtract, Children: append([]*expression{head}, negatives...)}
+}
+
+// unfuse expands n-ary expressions like (a + b + c) into left-associative binary operations ((a + b) + c).
+func (e expression) unfuse() expression {
+	if len(e.Children) == 0 {
+		return e
+	}
+
+	children := make([]expression, len(e.Children))
+	for i, c := range e.Children {
+		children[i] = c.unfuse()
+	}
+
+	if e.Op == opNegate {
+		return makeNegate(children[0])
+	}
+
+	acc := children[0]
+	for _, c := range children[1:] {
+		acc = makeBinary(e.Op, acc, c)
+	}
+	return acc
+}
+
+// precedence returns the binding strength of e's root operation, higher binds tighter.
+func (e expression) precedence() int {
+	switch e.Op {
+	case opAdd, opSubtract:
+		return 1
+	case opMultiply, opDivide:
+		return 2
+	case opNegate:
+		return 3
+	}
+	return 4
+}
+
+// formatInfix writes e using only the parentheses required by precedence, e.g. "9*7 + 25 + 5".
+// Additive operators are spaced and multiplicative ones are not, which keeps the grouping readable.
+func formatInfix(e expression, sym symbols) string {
+	switch e.Op {
+	case opNone:
+		return fmt.Sprintf("%d", e.Val)
+	case opNegate:
+		c := *e.Children[0]
+		if c.Op != opNone {
+			return fmt.Sprintf("%s(%s)", sym[opNegate], formatInfix(c, sym))
+		}
+		return fmt.Sprintf("%s%s", sym[opNegate], formatInfix(c, sym))
+	}
+
+	sep := sym[e.Op]
+	if e.precedence() == 1 {
+		sep = fmt.Sprintf(" %s ", sep)
+	}
+
+	var b strings.Builder
+	for i, c := range e.Children {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		s := formatInfix(*c, sym)
+		// Operands of lower precedence always need grouping, and so do later operands of
+		// equal precedence under a non-commutative operation: a - (b + c), a / (b * c).
+		if c.precedence() < e.precedence() || (i > 0 && !e.Op.commutative() && c.precedence() == e.precedence()) {
+			s = fmt.Sprintf("(%s)", s)
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// formatRPN writes e in reverse Polish notation, e.g. "9 7 * 25 + 5 +".
+// Negation is written as a postfix "neg" operator.
+func formatRPN(e expression, sym symbols) string {
+	var parts []string
+	var walk func(e expression)
+	walk = func(e expression) {
+		for _, c := range e.Children {
+			walk(*c)
+		}
+		switch e.Op {
+		case opNone:
+			parts = append(parts, fmt.Sprintf("%d", e.Val))
+		case opNegate:
+			parts = append(parts, "neg")
+		default:
+			parts = append(parts, sym[e.Op])
+		}
+	}
+	walk(e.unfuse())
+	return strings.Join(parts, " ")
+}
+
+// formatSExpr writes e as an S-expression, e.g. "(+ (* 9 7) 25 5)".
+// N-ary operations are kept fused and negation is written as "(- x)".
+func formatSExpr(e expression, sym symbols) string {
+	if e.Op == opNone {
+		return fmt.Sprintf("%d", e.Val)
+	}
+
+	parts := make([]string, 0, len(e.Children)+1)
+	parts = append(parts, sym[e.Op])
+	for _, c := range e.Children {
+		parts = append(parts, formatSExpr(*c, sym))
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, " "))
+}
+
+var sexprOps = map[string]operation{
+	"+": opAdd,
+	"-": opSubtract,
+	"−": opSubtract,
+	"*": opMultiply,
+	"×": opMultiply,
+	"/": opDivide,
+	"÷": opDivide,
+}
+
+// parseSExpr reads an expression written by formatSExpr.
+func parseSExpr(s string) (expression, error) {
+	tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(s))
+	if len(tokens) == 0 {
+		return expression{}, fmt.Errorf("empty expression")
+	}
+
+	e, rest, err := readSExpr(tokens)
+	if err != nil {
+		return expression{}, err
+	}
+	if len(rest) != 0 {
+		return expression{}, fmt.Errorf("unexpected trailing input %q", strings.Join(rest, " "))
+	}
+	return e, nil
+}
+
+func readSExpr(tokens []string) (expression, []string, error) {
+	if len(tokens) == 0 {
+		return expression{}, nil, fmt.Errorf("unexpected end of input")
+	}
+
+	tok, tokens := tokens[0], tokens[1:]
+	switch tok {
+	case ")":
+		return expression{}, nil, fmt.Errorf("unexpected %q", tok)
+	case "(":
+	default:
+		v, err := strconv.Atoi(tok)
+		if err != nil {
+			return expression{}, nil, fmt.Errorf("error parsing %q: %v", tok, err)
+		}
+		return makeConstant(v), tokens, nil
+	}
+
+	if len(tokens) == 0 {
+		return expression{}, nil, fmt.Errorf("unexpected end of input")
+	}
+	op, ok := sexprOps[tokens[0]]
+	if !ok {
+		return expression{}, nil, fmt.Errorf("unknown operator %q", tokens[0])
+	}
+	tokens = tokens[1:]
+
+	var children []*expression
+	for len(tokens) > 0 && tokens[0] != ")" {
+		c, rest, err := readSExpr(tokens)
+		if err != nil {
+			return expression{}, nil, err
+		}
+		children = append(children, &c)
+		tokens = rest
+	}
+	if len(tokens) == 0 {
+		return expression{}, nil, fmt.Errorf("missing %q", ")")
+	}
+	tokens = tokens[1:]
+
+	if op == opSubtract && len(children) == 1 {
+		return makeNegate(*children[0]), tokens, nil
+	}
+	if len(children) < 2 {
+		return expression{}, nil, fmt.Errorf("want at least 2 operands for %s, got %d", op, len(children))
+	}
+
+	e := expression{Op: op, Children: children}
+	val, ok := e.eval()
+	if !ok {
+		return expression{}, nil, fmt.Errorf("%s is not a valid expression", e)
+	}
+	e.Val = val
+	return e, tokens, nil
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_formatInfix(t *testing.T) {
+	tests := map[string]struct {
+		expr expression
+		want string
+	}{
+		"constant": {
+			expr: makeConstant(7),
+			want: "7",
+		},
+		"fused": {
+			expr: makeAdd(makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25)), makeConstant(5)).fuse(),
+			want: "9*7 + 25 + 5",
+		},
+		"grouped sum": {
+			expr: makeMultiply(makeAdd(makeConstant(25), makeConstant(11)), makeConstant(9)),
+			want: "(25 + 11)*9",
+		},
+		"negate": {
+			expr: makeAdd(makeConstant(25), makeNegate(makeConstant(9))),
+			want: "25 + -9",
+		},
+		"negate compound": {
+			expr: makeNegate(makeMultiply(makeConstant(9), makeConstant(7))),
+			want: "-(9*7)",
+		},
+		"left-associative subtract": {
+			expr: makeSubtract(makeSubtract(makeConstant(25), makeConstant(10)), makeConstant(5)),
+			want: "25 - 10 - 5",
+		},
+		"right-nested subtract": {
+			expr: makeSubtract(makeConstant(25), makeSubtract(makeConstant(10), makeConstant(5))),
+			want: "25 - (10 - 5)",
+		},
+		"right-nested divide": {
+			expr: makeDivide(makeConstant(100), makeMultiply(makeConstant(5), makeConstant(2))),
+			want: "100/(5*2)",
+		},
+		"divide by sum": {
+			expr: makeAdd(makeDivide(makeAdd(makeConstant(25), makeConstant(10)), makeConstant(7)), makeConstant(5)),
+			want: "(25 + 10)/7 + 5",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := formatInfix(tt.expr, opStrings)
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("formatInfix() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_withSubtraction(t *testing.T) {
+	tests := map[string]struct {
+		expr expression
+		want string
+	}{
+		"constant": {
+			expr: makeConstant(7),
+			want: "7",
+		},
+		"no negation": {
+			expr: makeAdd(makeConstant(25), makeConstant(9)),
+			want: "(25 + 9)",
+		},
+		"single": {
+			expr: makeAdd(makeConstant(25), makeNegate(makeConstant(9))),
+			want: "(25 - 9)",
+		},
+		"mixed": {
+			expr: makeAdd(makeAdd(makeConstant(24), makeNegate(makeConstant(8))), makeAdd(makeConstant(15), makeNegate(makeConstant(10)))).fuse().fuse(),
+			want: "((24 + 15) - 8 - 10)",
+		},
+		"nested": {
+			expr: makeDivide(makeAdd(makeMultiply(makeConstant(25), makeConstant(4)), makeNegate(makeConstant(9))), makeConstant(7)),
+			want: "(((25 * 4) - 9) / 7)",
+		},
+		"all negated": {
+			expr: makeAdd(makeNegate(makeConstant(3)), makeNegate(makeConstant(4))),
+			want: "-(3 + 4)",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := tt.expr.withSubtraction()
+			if !cmp.Equal(got.String(), tt.want) {
+				t.Errorf("withSubtraction() = %q, want %q", got.String(), tt.want)
+			}
+			if got.Val != tt.expr.Val {
+				t.Errorf("withSubtraction().Val = %d, want %d", got.Val, tt.expr.Val)
+			}
+			if val, ok := got.eval(); ok && val != tt.expr.Val {
+				t.Errorf("withSubtraction().eval() = %d, want %d", val, tt.expr.Val)
+			}
+		})
+	}
+}
+
+func Test_unfuse(t *testing.T) {
+	tests := map[string]struct {
+		expr expression
+		want string
+	}{
+		"constant": {
+			expr: makeConstant(7),
+			want: "7",
+		},
+		"fused": {
+			expr: makeAdd(makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25)), makeConstant(5)).fuse(),
+			want: "(((9 * 7) + 25) + 5)",
+		},
+		"nested": {
+			expr: makeMultiply(makeMultiply(makeAdd(makeAdd(makeConstant(15), makeConstant(9)).fuse(), makeConstant(4)).fuse(), makeConstant(3)), makeConstant(2)).fuse(),
+			want: "((((15 + 9) + 4) * 3) * 2)",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := tt.expr.unfuse()
+			if !cmp.Equal(got.String(), tt.want) {
+				t.Errorf("unfuse() = %q, want %q", got.String(), tt.want)
+			}
+			if got.Val != tt.expr.Val {
+				t.Errorf("unfuse().Val = %d, want %d", got.Val, tt.expr.Val)
+			}
+		})
+	}
+}
+
+func Test_formatRPN(t *testing.T) {
+	tests := map[string]struct {
+		expr expression
+		want string
+	}{
+		"constant": {
+			expr: makeConstant(7),
+			want: "7",
+		},
+		"fused": {
+			expr: makeAdd(makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25)), makeConstant(5)).fuse(),
+			want: "9 7 * 25 + 5 +",
+		},
+		"negate": {
+			expr: makeAdd(makeConstant(25), makeNegate(makeConstant(9))),
+			want: "25 9 neg +",
+		},
+		"divide": {
+			expr: makeDivide(makeConstant(25), makeSubtract(makeConstant(10), makeConstant(5))),
+			want: "25 10 5 - /",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := formatRPN(tt.expr, opStrings)
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("formatRPN() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatSExpr(t *testing.T) {
+	tests := map[string]struct {
+		expr expression
+		want string
+	}{
+		"constant": {
+			expr: makeConstant(7),
+			want: "7",
+		},
+		"fused": {
+			expr: makeAdd(makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25)), makeConstant(5)).fuse(),
+			want: "(+ (* 9 7) 25 5)",
+		},
+		"negate": {
+			expr: makeAdd(makeConstant(25), makeNegate(makeConstant(9))),
+			want: "(+ 25 (- 9))",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := formatSExpr(tt.expr, opStrings)
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("formatSExpr() = %q, want %q", got, tt.want)
+			}
+
+			parsed, err := parseSExpr(got)
+			if err != nil {
+				t.Fatalf("parseSExpr(%q) failed unexpectedly: %v", got, err)
+			}
+			if !cmp.Equal(parsed.String(), tt.expr.String()) {
+				t.Errorf("parseSExpr(%q) = %q, want %q", got, parsed.String(), tt.expr.String())
+			}
+			if parsed.Val != tt.expr.Val {
+				t.Errorf("parseSExpr(%q).Val = %d, want %d", got, parsed.Val, tt.expr.Val)
+			}
+		})
+	}
+}
+
+func Test_parseSExpr_errors(t *testing.T) {
+	tests := map[string]string{
+		"empty":          "",
+		"unbalanced":     "(+ 1 2",
+		"trailing":       "(+ 1 2) 3",
+		"unknown op":     "(% 1 2)",
+		"single operand": "(* 1)",
+		"inexact divide": "(/ 7 2)",
+		"bad number":     "(+ 1 x)",
+	}
+	for tn, s := range tests {
+		t.Run(tn, func(t *testing.T) {
+			if _, err := parseSExpr(s); err == nil {
+				t.Errorf("parseSExpr(%q) succeeded unexpectedly", s)
+			}
+		})
+	}
+}
+
+func Test_printer_unicode(t *testing.T) {
+	expr := makeAdd(makeDivide(makeMultiply(makeConstant(25), makeConstant(4)), makeConstant(5)), makeNegate(makeConstant(3)))
+
+	tests := map[notation]string{
+		notationInfix:  "25×4÷5 − 3",
+		notationFull:   "(((25 × 4) ÷ 5) − 3)",
+		notationBinary: "(((25 × 4) ÷ 5) − 3)",
+		notationRPN:    "25 4 × 5 ÷ 3 −",
+		notationSExpr:  "(− (÷ (× 25 4) 5) 3)",
+	}
+	for n, want := range tests {
+		p := newPrinter(n, true)
+		got := p.format(expr)
+		if !cmp.Equal(got, want) {
+			t.Errorf("printer{%d}.format() = %q, want %q", n, got, want)
+		}
+	}
+
+	parsed, err := parseSExpr(tests[notationSExpr])
+	if err != nil {
+		t.Fatalf("parseSExpr(%q) failed unexpectedly: %v", tests[notationSExpr], err)
+	}
+	if parsed.Val != expr.Val {
+		t.Errorf("parseSExpr(%q).Val = %d, want %d", tests[notationSExpr], parsed.Val, expr.Val)
+	}
+}
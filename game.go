@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+)
+
+// game is a puzzle being played by combining two numbers at a time, until one of them equals the target.
+type game struct {
+	puzzle puzzle
+	// numbers are the numbers left to play with. Each is a digit or the expression which made it.
+	numbers []expression
+	// history holds the earlier states of numbers, for undo.
+	history [][]expression
+	// steps are the combinations played so far, in order.
+	steps []step
+}
+
+func newGame(p puzzle) *game {
+	g := &game{puzzle: p}
+	for _, d := range p.Digits {
+		g.numbers = append(g.numbers, makeConstant(d))
+	}
+	return g
+}
+
+// combine replaces numbers i and j with the result of "i op j".
+func (g *game) combine(i, j int, op operation) error {
+	if i == j || i < 0 || j < 0 || i >= len(g.numbers) || j >= len(g.numbers) {
+		return fmt.Errorf("choose two different numbers")
+	}
+	a, b := g.numbers[i], g.numbers[j]
+	val, ok := op.evalBinary(a.Val, b.Val)
+	if !ok || val <= 0 {
+		return fmt.Errorf("%d %s %d doesn't give a positive whole number", a.Val, op, b.Val)
+	}
+
+	var e expression
+	if op == opDivide {
+		e = expression{Val: val, Op: opDivide, Children: []*expression{&a, &b}}
+	} else {
+		e = makeBinary(op, a, b)
+	}
+
+	g.history = append(g.history, g.numbers)
+	var next []expression
+	for k, n := range g.numbers {
+		if k != i && k != j {
+			next = append(next, n)
+		}
+	}
+	// Put the result where the first number was, so it stays under the cursor.
+	pos := i
+	if j < i {
+		pos--
+	}
+	next = append(next[:pos], append([]expression{e}, next[pos:]...)...)
+	g.numbers = next
+	g.steps = append(g.steps, step{Op: op, A: a.Val, B: b.Val, Result: val, AStep: -1, BStep: -1})
+	return nil
+}
+
+// undo reverts the last combination, reporting whether there was one.
+func (g *game) undo() bool {
+	if len(g.history) == 0 {
+		return false
+	}
+	g.numbers = g.history[len(g.history)-1]
+	g.history = g.history[:len(g.history)-1]
+	g.steps = g.steps[:len(g.steps)-1]
+	return true
+}
+
+// solution returns the number which equals the target, if any.
+func (g *game) solution() (expression, bool) {
+	for _, n := range g.numbers {
+		if n.Val == g.puzzle.Target {
+			return n, true
+		}
+	}
+	return expression{}, false
+}
+
+// hint suggests the next combination, found by solving from the numbers left.
+func (g *game) hint(sym symbols) string {
+	if _, ok := g.solution(); ok {
+		return "You've already made the target!"
+	}
+	values := make([]int, len(g.numbers))
+	for i, n := range g.numbers {
+		values[i] = n.Val
+	}
+	best, err := shortest(solver{}.solveFewest(g.puzzle.Target, values, 1))
+	if err != nil {
+		return "There's no solution from here, try undoing"
+	}
+	steps := best.steps()
+	return fmt.Sprintf("Try %s", steps[0].format(sym))
+}
+
+// closest returns the number nearest the target, preferring the earliest on a tie.
+func (g *game) closest() expression {
+	best := g.numbers[0]
+	for _, n := range g.numbers[1:] {
+		if abs(n.Val-g.puzzle.Target) < abs(best.Val-g.puzzle.Target) {
+			best = n
+		}
+	}
+	return best
+}
+
+// score returns the points for answer, using the TV game's rules:
+// 10 for the target, 7 for within 5 of it, 5 for within 10 and nothing otherwise.
+func score(target, answer int) int {
+	switch d := abs(target - answer); {
+	case d == 0:
+		return 10
+	case d <= 5:
+		return 7
+	case d <= 10:
+		return 5
+	}
+	return 0
+}
+
+// bestSolutions returns up to n of the shortest solutions to p.
+// If it can't be solved they're solutions for the closest value which can be made.
+func bestSolutions(p puzzle, n int) []expression {
+	target := p.Target
+	solns := solve(target, p.Digits)
+	if len(solns) == 0 {
+		if target = closestValue(reachableValues(p.Digits), target); target == 0 {
+			return nil
+		}
+		solns = solve(target, p.Digits)
+	}
+	return shortestN(solns, n)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func gameValues(g *game) []int {
+	var out []int
+	for _, n := range g.numbers {
+		out = append(out, n.Val)
+	}
+	return out
+}
+
+func Test_game(t *testing.T) {
+	g := newGame(puzzle{Digits: []int{5, 7, 9, 25}, Target: 93})
+
+	if err := g.combine(2, 1, opMultiply); err != nil {
+		t.Fatalf("combine(9 * 7) failed unexpectedly: %v", err)
+	}
+	if diff := cmp.Diff([]int{5, 63, 25}, gameValues(g)); diff != "" {
+		t.Errorf("numbers after 9 * 7 mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := g.combine(0, 2, opSubtract); err == nil {
+		t.Errorf("combine(5 - 25) succeeded unexpectedly")
+	}
+	if err := g.combine(2, 0, opDivide); err != nil {
+		t.Fatalf("combine(25 / 5) failed unexpectedly: %v", err)
+	}
+	if diff := cmp.Diff([]int{63, 5}, gameValues(g)); diff != "" {
+		t.Errorf("numbers after 25 / 5 mismatch (-want +got):\n%s", diff)
+	}
+
+	if !g.undo() {
+		t.Fatalf("undo() = false, want true")
+	}
+	if diff := cmp.Diff([]int{5, 63, 25}, gameValues(g)); diff != "" {
+		t.Errorf("numbers after undo mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := g.combine(1, 2, opAdd); err != nil {
+		t.Fatalf("combine(63 + 25) failed unexpectedly: %v", err)
+	}
+	if _, ok := g.solution(); ok {
+		t.Errorf("solution() found before the target was made")
+	}
+	if err := g.combine(1, 0, opAdd); err != nil {
+		t.Fatalf("combine(88 + 5) failed unexpectedly: %v", err)
+	}
+	soln, ok := g.solution()
+	if !ok {
+		t.Fatalf("solution() not found after making the target")
+	}
+	if got, want := formatInfix(soln, opStrings), "9*7 + 25 + 5"; got != want {
+		t.Errorf("solution() = %q, want %q", got, want)
+	}
+}
+
+func Test_game_hint(t *testing.T) {
+	g := newGame(puzzle{Digits: []int{5, 7, 9, 25}, Target: 93})
+	if got, want := g.hint(opStrings), "Try 9 * 7 = 63"; got != want {
+		t.Errorf("hint() = %q, want %q", got, want)
+	}
+
+	if err := g.combine(3, 0, opDivide); err != nil {
+		t.Fatalf("combine(25 / 5) failed unexpectedly: %v", err)
+	}
+	if err := g.combine(0, 1, opMultiply); err != nil {
+		t.Fatalf("combine(5 * 7) failed unexpectedly: %v", err)
+	}
+	if got, want := g.hint(opStrings), "There's no solution from here, try undoing"; got != want {
+		t.Errorf("hint() = %q, want %q", got, want)
+	}
+}
+
+func Test_score(t *testing.T) {
+	tests := map[string]struct {
+		answer int
+		want   int
+	}{
+		"exact":       {answer: 500, want: 10},
+		"5 under":     {answer: 495, want: 7},
+		"5 over":      {answer: 505, want: 7},
+		"10 away":     {answer: 510, want: 5},
+		"11 away":     {answer: 489, want: 0},
+		"far too big": {answer: 900, want: 0},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			if got := score(500, tt.answer); got != tt.want {
+				t.Errorf("score(500, %d) = %d, want %d", tt.answer, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_bestSolutions(t *testing.T) {
+	tests := map[string]struct {
+		p    puzzle
+		want []string
+	}{
+		"solvable": {
+			p:    puzzle{Digits: []int{5, 7, 9, 25}, Target: 93},
+			want: []string{"9*7 + 25 + 5"},
+		},
+		"closest": {
+			p:    puzzle{Digits: []int{2, 3}, Target: 8},
+			want: []string{"3*2"},
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			var got []string
+			for _, e := range bestSolutions(tt.p, 1) {
+				got = append(got, formatInfix(e.withSubtraction(), opStrings))
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("bestSolutions() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Number pools for Countdown-style puzzles.
+var (
+	largeNumbers = []int{25, 50, 75, 100}
+	smallNumbers = []int{1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 6, 7, 7, 8, 8, 9, 9, 10, 10}
+)
+
+// poolRepeats returns a note for each number in digits which is repeated more often
+// than the Countdown pools allow, e.g. "25 used twice, but the Countdown pool only has it once".
+// Numbers which aren't in the pools at all are allowed, as plenty of variants use them.
+func poolRepeats(digits []int) []string {
+	pool := make(map[int]int)
+	for _, numbers := range [][]int{largeNumbers, smallNumbers} {
+		for _, d := range numbers {
+			pool[d]++
+		}
+	}
+	counts := make(map[int]int)
+	var order []int
+	for _, d := range digits {
+		if counts[d] == 0 {
+			order = append(order, d)
+		}
+		counts[d]++
+	}
+
+	var notes []string
+	for _, d := range order {
+		if n := pool[d]; n > 0 && counts[d] > n {
+			notes = append(notes, fmt.Sprintf("%d used %s, but the Countdown pool only has it %s", d, times(counts[d]), times(n)))
+		}
+	}
+	return notes
+}
+
+// puzzle is a set of digits and a target to make from them.
+type puzzle struct {
+	Digits []int
+	Target int
+}
+
+func (p puzzle) String() string {
+	return fmt.Sprintf("make %d from %s", p.Target, formatDigits(p.Digits))
+}
+
+// validate checks p can be solved: it needs at least one digit, no more than can be searched,
+// and positive numbers throughout.
+func (p puzzle) validate() error {
+	if len(p.Digits) == 0 {
+		return fmt.Errorf("no digits")
+	}
+	if len(p.Digits) > maxDigitCount {
+		return fmt.Errorf("too many digits: got %d, but at most %d can be searched", len(p.Digits), maxDigitCount)
+	}
+	for _, d := range p.Digits {
+		if d <= 0 {
+			return fmt.Errorf("digits must be positive, got %d", d)
+		}
+	}
+	return validateTarget(p.Target)
+}
+
+// validateTarget reports an error if target can't be a puzzle's.
+func validateTarget(target int) error {
+	if target <= 0 {
+		return fmt.Errorf("target must be positive, got %d", target)
+	}
+	return nil
+}
+
+type generatorOptions struct {
+	// Count is the number of digits to draw.
+	Count int
+	// Large is how many digits to draw from the large numbers, or -1 to choose at random.
+	Large int
+	// MinTarget and MaxTarget bound the target (inclusive).
+	MinTarget, MaxTarget int
+	// MinRating and MaxRating, if positive, bound the puzzle's rating from ratePuzzle (inclusive).
+	MinRating, MaxRating int
+}
+
+var defaultGeneratorOptions = generatorOptions{
+	Count:     6,
+	Large:     -1,
+	MinTarget: 101,
+	MaxTarget: 999,
+}
+
+func (o generatorOptions) validate() error {
+	if o.Count <= 0 {
+		return fmt.Errorf("digit count must be positive, got %d", o.Count)
+	}
+	if o.Large > len(largeNumbers) || o.Large > o.Count {
+		return fmt.Errorf("can't draw %d large numbers for %d digits from %v", o.Large, o.Count, largeNumbers)
+	}
+	if o.Count-max0(o.Large) > len(smallNumbers) {
+		return fmt.Errorf("can't draw %d small numbers from %v", o.Count-max0(o.Large), smallNumbers)
+	}
+	if o.MinTarget <= 0 || o.MaxTarget < o.MinTarget {
+		return fmt.Errorf("invalid target range %d-%d", o.MinTarget, o.MaxTarget)
+	}
+	if o.MinRating < 0 || o.MinRating > 5 || o.MaxRating < 0 || o.MaxRating > 5 || (o.MaxRating > 0 && o.MaxRating < o.MinRating) {
+		return fmt.Errorf("invalid rating range %d-%d, want ratings from 1 to 5", o.MinRating, o.MaxRating)
+	}
+	return nil
+}
+
+// rated reports whether o bounds the puzzles' ratings.
+func (o generatorOptions) rated() bool {
+	return o.MinRating > 0 || o.MaxRating > 0
+}
+
+// allowsRating reports whether a puzzle rated r is within o's bounds.
+func (o generatorOptions) allowsRating(r int) bool {
+	return r >= o.MinRating && (o.MaxRating == 0 || r <= o.MaxRating)
+}
+
+func max0(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// newRand returns a random source for seed, or for a seed from the clock if it's zero.
+func newRand(seed int64) *rand.Rand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+		slog.Debug("Using a random seed", "seed", seed)
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// solvedPuzzle is a generated puzzle along with the solution to show for it.
+type solvedPuzzle struct {
+	Puzzle   puzzle
+	Solution expression
+}
+
+// generateSolvedPuzzles generates n puzzles, each paired with its shortest solution, stopping early with
+// ctx's error if it's done.
+func generateSolvedPuzzles(ctx context.Context, rng *rand.Rand, opts generatorOptions, n int) ([]solvedPuzzle, error) {
+	var items []solvedPuzzle
+	for i := 0; i < n; i++ {
+		g, err := generatePuzzle(ctx, rng, opts, nil)
+		if err != nil {
+			return nil, err
+		}
+		s, err := shortest(g.Solutions)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, solvedPuzzle{Puzzle: g.Puzzle, Solution: s})
+	}
+	return items, nil
+}
+
+// maxGenerateAttempts bounds how many draws generatePuzzle makes before giving up on finding a solvable puzzle.
+const maxGenerateAttempts = 1000
+
+// generatedPuzzle is a puzzle generatePuzzle found, with its solutions.
+type generatedPuzzle struct {
+	Puzzle    puzzle
+	Solutions []expression
+	// Rating is only set if the options bound the rating, as it's worked out to check it.
+	Rating *puzzleRating
+}
+
+// generatePuzzle draws random digits and a target until it finds a solvable puzzle, with a rating
+// in opts' bounds if it has any. The draws share budget, which is unlimited if it's nil, and
+// generating stops early with ctx's error if it's done, or errBudgetExhausted if budget runs out.
+func generatePuzzle(ctx context.Context, rng *rand.Rand, opts generatorOptions, budget *nodeBudget) (generatedPuzzle, error) {
+	if err := opts.validate(); err != nil {
+		return generatedPuzzle{}, err
+	}
+
+	s := solver{ctx: ctx, budget: budget}
+	for i := 0; i < maxGenerateAttempts; i++ {
+		p := drawPuzzle(rng, opts)
+		solns := s.solve(p.Target, p.Digits)
+		if err := stopReason(ctx, budget); err != nil {
+			return generatedPuzzle{}, err
+		}
+		if len(solns) == 0 {
+			continue
+		}
+		g := generatedPuzzle{Puzzle: p, Solutions: solns}
+		if opts.rated() {
+			r, err := ratePuzzle(ctx, budget, p)
+			if err := stopReason(ctx, budget); err != nil {
+				return generatedPuzzle{}, err
+			}
+			if err != nil || !opts.allowsRating(r.Rating) {
+				continue
+			}
+			g.Rating = &r
+		}
+		return g, nil
+	}
+	return generatedPuzzle{}, fmt.Errorf("no solvable puzzle found after %d attempts", maxGenerateAttempts)
+}
+
+func drawPuzzle(rng *rand.Rand, opts generatorOptions) puzzle {
+	large := opts.Large
+	if large < 0 {
+		maxLarge := len(largeNumbers)
+		if opts.Count < maxLarge {
+			maxLarge = opts.Count
+		}
+		large = rng.Intn(maxLarge + 1)
+	}
+
+	var digits []int
+	for _, i := range rng.Perm(len(largeNumbers))[:large] {
+		digits = append(digits, largeNumbers[i])
+	}
+	for _, i := range rng.Perm(len(smallNumbers))[:opts.Count-large] {
+		digits = append(digits, smallNumbers[i])
+	}
+	sort.Ints(digits)
+
+	return puzzle{
+		Digits: digits,
+		Target: opts.MinTarget + rng.Intn(opts.MaxTarget-opts.MinTarget+1),
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_generatePuzzle(t *testing.T) {
+	tests := map[string]generatorOptions{
+		"default":  defaultGeneratorOptions,
+		"no large": {Count: 6, Large: 0, MinTarget: 101, MaxTarget: 999},
+		"4 large":  {Count: 6, Large: 4, MinTarget: 101, MaxTarget: 999},
+		"small":    {Count: 4, Large: 1, MinTarget: 10, MaxTarget: 99},
+		"hard":     {Count: 6, Large: -1, MinTarget: 101, MaxTarget: 999, MinRating: 4},
+		"easy":     {Count: 6, Large: -1, MinTarget: 101, MaxTarget: 999, MaxRating: 2},
+	}
+	for tn, opts := range tests {
+		t.Run(tn, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			for i := 0; i < 5; i++ {
+				g, err := generatePuzzle(context.Background(), rng, opts, nil)
+				if err != nil {
+					t.Fatalf("generatePuzzle() failed unexpectedly: %v", err)
+				}
+				p := g.Puzzle
+				if len(p.Digits) != opts.Count {
+					t.Errorf("generatePuzzle() = %v, want %d digits", p, opts.Count)
+				}
+				if p.Target < opts.MinTarget || p.Target > opts.MaxTarget {
+					t.Errorf("generatePuzzle() = %v, want target in [%d, %d]", p, opts.MinTarget, opts.MaxTarget)
+				}
+				large := 0
+				for _, d := range p.Digits {
+					if d > 10 {
+						large++
+					}
+				}
+				if opts.Large >= 0 && large != opts.Large {
+					t.Errorf("generatePuzzle() = %v, want %d large numbers", p, opts.Large)
+				}
+				if len(g.Solutions) == 0 {
+					t.Errorf("generatePuzzle() = %v, want a solvable puzzle", p)
+				}
+				if opts.rated() {
+					r, err := ratePuzzle(context.Background(), nil, p)
+					if err != nil || !opts.allowsRating(r.Rating) {
+						t.Errorf("generatePuzzle() = %v rated %d (%v), want a rating in [%d, %d]", p, r.Rating, err, opts.MinRating, opts.MaxRating)
+					}
+					if diff := cmp.Diff(&r, g.Rating); diff != "" {
+						t.Errorf("generatePuzzle() rating mismatch (-want +got):\n%s", diff)
+					}
+				} else if g.Rating != nil {
+					t.Errorf("generatePuzzle() rating = %+v, want none without rating bounds", g.Rating)
+				}
+			}
+		})
+	}
+}
+
+func Test_generatePuzzle_invalid(t *testing.T) {
+	tests := map[string]generatorOptions{
+		"too many large": {Count: 6, Large: 5, MinTarget: 101, MaxTarget: 999},
+		"no digits":      {Count: 0, Large: 0, MinTarget: 101, MaxTarget: 999},
+		"bad range":      {Count: 6, Large: 1, MinTarget: 999, MaxTarget: 101},
+		"bad ratings":    {Count: 6, Large: 1, MinTarget: 101, MaxTarget: 999, MinRating: 4, MaxRating: 2},
+	}
+	for tn, opts := range tests {
+		t.Run(tn, func(t *testing.T) {
+			if _, err := generatePuzzle(context.Background(), rand.New(rand.NewSource(1)), opts, nil); err == nil {
+				t.Errorf("generatePuzzle(%+v) succeeded unexpectedly", opts)
+			}
+		})
+	}
+}
+
+func Test_generatePuzzle_stopped(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	hard := defaultGeneratorOptions
+	hard.MinRating = 5
+	tests := map[string]struct {
+		ctx    context.Context
+		budget *nodeBudget
+		want   error
+	}{
+		"cancelled":        {ctx: cancelled, want: context.Canceled},
+		"budget exhausted": {ctx: context.Background(), budget: newNodeBudget(100), want: errBudgetExhausted},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			if _, err := generatePuzzle(tt.ctx, rand.New(rand.NewSource(1)), hard, tt.budget); !errors.Is(err, tt.want) {
+				t.Errorf("generatePuzzle() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func Test_generateSolvedPuzzles_seeded(t *testing.T) {
+	generate := func(seed int64) []solvedPuzzle {
+		items, err := generateSolvedPuzzles(context.Background(), newRand(seed), defaultGeneratorOptions, 3)
+		if err != nil {
+			t.Fatalf("generateSolvedPuzzles() failed unexpectedly: %v", err)
+		}
+		return items
+	}
+
+	a, b := generate(42), generate(42)
+	for i := range a {
+		if a[i].Puzzle.String() != b[i].Puzzle.String() || a[i].Solution.String() != b[i].Solution.String() {
+			t.Errorf("puzzle %d with the same seed = %v and %v, want the same", i, a[i], b[i])
+		}
+	}
+	if c := generate(43); c[0].Puzzle.String() == a[0].Puzzle.String() && c[1].Puzzle.String() == a[1].Puzzle.String() {
+		t.Errorf("puzzles with different seeds are the same: %v", c)
+	}
+}
+
+func Test_poolRepeats(t *testing.T) {
+	tests := map[string]struct {
+		digits []int
+		want   []string
+	}{
+		"allowed": {
+			digits: []int{5, 5, 7, 9, 25, 100},
+		},
+		"not in the pool": {
+			digits: []int{15, 15, 15},
+		},
+		"repeats": {
+			digits: []int{25, 3, 3, 25, 3},
+			want: []string{
+				"25 used twice, but the Countdown pool only has it once",
+				"3 used 3 times, but the Countdown pool only has it twice",
+			},
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := poolRepeats(tt.digits)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("poolRepeats(%v) mismatch (-want +got):\n%s", tt.digits, diff)
+			}
+		})
+	}
+}
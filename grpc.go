@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hulkholden/digits/digitspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcServer answers the gRPC API defined in digitspb/digits.proto, with the same limits and handling as srv's HTTP API.
+type grpcServer struct {
+	digitspb.UnimplementedDigitsServer
+	srv *server
+}
+
+// Solve streams the solutions for req as the search finds them, rather than waiting for them all like POST /v1/solve,
+// then a done event saying whether the search finished.
+func (g *grpcServer) Solve(req *digitspb.SolveRequest, stream grpc.ServerStreamingServer[digitspb.SolveEvent]) error {
+	s, p, pz, err := g.srv.streamSolver(streamRequest{
+		Digits:        fromInt64s(req.Digits),
+		Target:        int(req.Target),
+		Notation:      req.Notation,
+		Unicode:       req.Unicode,
+		MaxResults:    int(req.MaxResults),
+		NoDivision:    req.NoDivision,
+		NoNegation:    req.NoNegation,
+		NoTrivial:     req.NoTrivial,
+		MaxOps:        int(req.MaxOps),
+		MaxDigitsUsed: int(req.MaxDigitsUsed),
+	})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	ctx := stream.Context()
+	s.ctx = ctx
+	sent := 0
+	var sendErr error
+	stopped := s.streamSolutionsWithin(g.srv.timeout, pz.Target, pz.Digits, newRecentSet(serverStreamMemory), func(e expression) bool {
+		soln := makeSolutionProto(makeSolutionJSON(makeSolution(e, p), p))
+		if sendErr = stream.Send(&digitspb.SolveEvent{Event: &digitspb.SolveEvent_Solution{Solution: soln}}); sendErr != nil {
+			return false
+		}
+		sent++
+		return req.MaxResults == 0 || sent < int(req.MaxResults)
+	})
+	full := req.MaxResults > 0 && sent >= int(req.MaxResults)
+	done := &digitspb.SolveDone{Solutions: int64(sent)}
+	switch {
+	case full:
+	case s.budget.exhausted():
+		done.Incomplete, done.Reason = true, "max_nodes"
+	case stopped:
+		done.Incomplete, done.Reason = true, "timeout"
+	}
+	g.srv.log.Info("Streamed solutions", "digits", formatDigits(pz.Digits), "target", pz.Target, "solutions", sent, "reason", done.Reason)
+	switch {
+	case sendErr != nil:
+		return sendErr
+	case ctx.Err() != nil:
+		// The client has gone, or given up, so there's no one to tell.
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	return stream.Send(&digitspb.SolveEvent{Event: &digitspb.SolveEvent_Done{Done: done}})
+}
+
+// Generate is POST /v1/generate.
+func (g *grpcServer) Generate(ctx context.Context, req *digitspb.GenerateRequest) (*digitspb.GenerateResponse, error) {
+	opts, err := g.srv.generatorOptions(generateRequest{
+		Preset:     req.Preset,
+		Difficulty: int(req.Difficulty),
+		Large:      fromOptionalInt32(req.Large),
+		MinRating:  fromOptionalInt32(req.MinRating),
+		MaxRating:  fromOptionalInt32(req.MaxRating),
+	})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp, err := g.srv.generate(ctx, newRand(req.Seed), opts)
+	if err != nil {
+		code := codes.Internal
+		switch generateErrorStatus(err) {
+		case http.StatusServiceUnavailable:
+			code = codes.ResourceExhausted
+		case http.StatusGatewayTimeout:
+			code = codes.DeadlineExceeded
+		}
+		return nil, status.Errorf(code, "failed to generate a puzzle: %v", err)
+	}
+	return &digitspb.GenerateResponse{
+		Digits:   toInt64s(resp.Digits),
+		Target:   int64(resp.Target),
+		Rating:   int32(resp.Rating.Rating),
+		Solution: makeSolutionProto(&resp.Solution),
+		Hints:    resp.Hints,
+	}, nil
+}
+
+// Verify is POST /v1/verify.
+func (g *grpcServer) Verify(ctx context.Context, req *digitspb.VerifyRequest) (*digitspb.VerifyResponse, error) {
+	resp, err := g.srv.verify(verifyRequest{Digits: fromInt64s(req.Digits), Target: int(req.Target), Answer: req.Answer})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	out := &digitspb.VerifyResponse{Valid: resp.Valid, Violations: resp.Violations}
+	if resp.Value != nil {
+		v := int64(*resp.Value)
+		out.Value = &v
+	}
+	return out, nil
+}
+
+func makeSolutionProto(s *solutionJSON) *digitspb.Solution {
+	return &digitspb.Solution{Text: s.Text, Value: int64(s.Value), Ops: int32(s.Ops), Digits: toInt64s(s.Digits), Steps: s.Steps}
+}
+
+func toInt64s(ns []int) []int64 {
+	out := make([]int64, len(ns))
+	for i, n := range ns {
+		out[i] = int64(n)
+	}
+	return out
+}
+
+func fromInt64s(ns []int64) []int {
+	out := make([]int, len(ns))
+	for i, n := range ns {
+		out[i] = int(n)
+	}
+	return out
+}
+
+func fromOptionalInt32(n *int32) *int {
+	if n == nil {
+		return nil
+	}
+	v := int(*n)
+	return &v
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hulkholden/digits/digitspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// newTestGRPCClient serves g over an in-memory connection, and returns a client for it.
+func newTestGRPCClient(t *testing.T, g *grpcServer) digitspb.DigitsClient {
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	digitspb.RegisterDigitsServer(gs, g)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient() failed unexpectedly: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return digitspb.NewDigitsClient(conn)
+}
+
+func Test_grpcServer_Solve(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := newTestGRPCClient(t, &grpcServer{srv: &server{log: log}})
+	limited := newTestGRPCClient(t, &grpcServer{srv: &server{log: log, maxNodes: 1}})
+
+	tests := map[string]struct {
+		client   digitspb.DigitsClient
+		req      *digitspb.SolveRequest
+		want     []string
+		wantDone *digitspb.SolveDone
+		wantCode codes.Code
+	}{
+		"solved": {
+			req:      &digitspb.SolveRequest{Digits: []int64{2, 3, 7}, Target: 13},
+			want:     []string{"7 + 3*2"},
+			wantDone: &digitspb.SolveDone{Solutions: 1},
+		},
+		"options": {
+			req:      &digitspb.SolveRequest{Digits: []int64{2, 3, 7}, Target: 1, Notation: "rpn", NoDivision: true},
+			want:     []string{"3 2 -", "7 3 2 * -"},
+			wantDone: &digitspb.SolveDone{Solutions: 2},
+		},
+		"max results": {
+			req:      &digitspb.SolveRequest{Digits: []int64{2, 3, 7}, Target: 1, NoDivision: true, MaxResults: 1},
+			want:     []string{"3 - 2"},
+			wantDone: &digitspb.SolveDone{Solutions: 1},
+		},
+		"no solution": {
+			req:      &digitspb.SolveRequest{Digits: []int64{2, 3}, Target: 100},
+			wantDone: &digitspb.SolveDone{},
+		},
+		"node budget": {
+			client:   limited,
+			req:      &digitspb.SolveRequest{Digits: []int64{5, 7, 9, 25}, Target: 93},
+			wantDone: &digitspb.SolveDone{Incomplete: true, Reason: "max_nodes"},
+		},
+		"invalid target": {
+			req:      &digitspb.SolveRequest{Digits: []int64{2, 3}},
+			wantCode: codes.InvalidArgument,
+		},
+		"unknown notation": {
+			req:      &digitspb.SolveRequest{Digits: []int64{2, 3}, Target: 5, Notation: "roman"},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			c := client
+			if tt.client != nil {
+				c = tt.client
+			}
+			stream, err := c.Solve(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("Solve() failed unexpectedly: %v", err)
+			}
+			var got []string
+			var gotDone *digitspb.SolveDone
+			for {
+				ev, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					if code := status.Code(err); code != tt.wantCode {
+						t.Fatalf("Recv() code = %v, want %v: %v", code, tt.wantCode, err)
+					}
+					return
+				}
+				if gotDone != nil {
+					t.Fatalf("Recv() = %v after the done event", ev)
+				}
+				if soln := ev.GetSolution(); soln != nil {
+					got = append(got, soln.Text)
+				}
+				gotDone = ev.GetDone()
+			}
+			if tt.wantCode != codes.OK {
+				t.Fatalf("Solve() succeeded, want code %v", tt.wantCode)
+			}
+			// The node budget may run out after some solutions have been found.
+			if tt.wantDone.Incomplete {
+				tt.want, tt.wantDone.Solutions = got, int64(len(got))
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("solutions mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantDone, gotDone, protocmp.Transform()); diff != "" {
+				t.Errorf("done event mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_grpcServer_Generate(t *testing.T) {
+	client := newTestGRPCClient(t, &grpcServer{srv: &server{log: slog.New(slog.NewTextHandler(io.Discard, nil))}})
+
+	large := int32(1)
+	resp, err := client.Generate(context.Background(), &digitspb.GenerateRequest{Difficulty: 2, Large: &large, Seed: 1})
+	if err != nil {
+		t.Fatalf("Generate() failed unexpectedly: %v", err)
+	}
+	if resp.Rating != 2 || resp.Solution.GetValue() != resp.Target {
+		t.Errorf("Generate() = %v, want a puzzle rated 2 with a solution making its target", resp)
+	}
+	if len(resp.Hints) == 0 {
+		t.Errorf("Generate() = %v, want hints", resp)
+	}
+	if got := len(resp.Digits) - len(poolSmall(fromInt64s(resp.Digits))); got != 1 {
+		t.Errorf("Generate() digits = %v, want 1 large number", resp.Digits)
+	}
+
+	if _, err := client.Generate(context.Background(), &digitspb.GenerateRequest{Difficulty: 6}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Generate(difficulty 6) error = %v, want InvalidArgument", err)
+	}
+}
+
+func Test_grpcServer_Verify(t *testing.T) {
+	client := newTestGRPCClient(t, &grpcServer{srv: &server{log: slog.New(slog.NewTextHandler(io.Discard, nil))}})
+
+	value := func(v int64) *int64 { return &v }
+	tests := map[string]struct {
+		req      *digitspb.VerifyRequest
+		want     *digitspb.VerifyResponse
+		wantCode codes.Code
+	}{
+		"valid": {
+			req:  &digitspb.VerifyRequest{Digits: []int64{5, 7, 9, 25}, Target: 93, Answer: "(9*7) + 25 + 5"},
+			want: &digitspb.VerifyResponse{Valid: true, Value: value(93)},
+		},
+		"violations": {
+			req:  &digitspb.VerifyRequest{Digits: []int64{5, 7, 9, 25}, Target: 93, Answer: "9*9 + 7"},
+			want: &digitspb.VerifyResponse{Value: value(88), Violations: []string{"digit 9 used twice", "9*9 + 7 = 88, not 93"}},
+		},
+		"past int32": {
+			req:  &digitspb.VerifyRequest{Digits: []int64{65536, 65536}, Target: 1 << 32, Answer: "65536*65536"},
+			want: &digitspb.VerifyResponse{Valid: true, Value: value(1 << 32)},
+		},
+		"invalid puzzle": {
+			req:      &digitspb.VerifyRequest{Target: 93, Answer: "93"},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got, err := client.Verify(context.Background(), tt.req)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("Verify() code = %v, want %v: %v", code, tt.wantCode, err)
+			}
+			if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Verify() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
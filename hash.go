@@ -0,0 +1,63 @@
+package main
+
+// expressionSet is a set of expressions compared by their structure, so finding duplicates
+// doesn't need to write out every solution as a string. The zero value is an empty set.
+type expressionSet struct {
+	// buckets holds the expressions by hash, as different expressions can share one.
+	buckets map[uint64][]expression
+}
+
+// add adds e to the set, and reports whether it wasn't already there.
+func (s *expressionSet) add(e expression) bool {
+	h := e.hash()
+	for _, other := range s.buckets[h] {
+		if e.equal(other) {
+			return false
+		}
+	}
+	if s.buckets == nil {
+		s.buckets = make(map[uint64][]expression)
+	}
+	s.buckets[h] = append(s.buckets[h], e)
+	return true
+}
+
+// FNV-1a constants, used to mix each node into the hash.
+const (
+	hashOffset = 14695981039346656037
+	hashPrime  = 1099511628211
+)
+
+// hash returns a hash of e's structure: expressions which are equal have the same hash.
+func (e expression) hash() uint64 {
+	h := uint64(hashOffset)
+	mix := func(v uint64) {
+		h ^= v
+		h *= hashPrime
+	}
+	mix(uint64(e.Op))
+	if e.Op == opNone {
+		mix(uint64(e.Val))
+	}
+	for _, c := range e.Children {
+		mix(c.hash())
+	}
+	mix(uint64(len(e.Children)))
+	return h
+}
+
+// equal reports whether e and other have the same structure, i.e. whether they're written the same way.
+func (e expression) equal(other expression) bool {
+	if e.Op != other.Op || len(e.Children) != len(other.Children) {
+		return false
+	}
+	if e.Op == opNone {
+		return e.Val == other.Val
+	}
+	for i, c := range e.Children {
+		if !c.equal(*other.Children[i]) {
+			return false
+		}
+	}
+	return true
+}
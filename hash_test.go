@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func Test_expressionSet(t *testing.T) {
+	var s expressionSet
+	seen := make(map[string]bool)
+	// Every expression the search finds along the way, with its written form to compare against.
+	for _, target := range []int{93, 100, 250, 500} {
+		for _, e := range solve(target, []int{2, 2, 5, 10, 25}) {
+			for _, c := range append([]expression{e}, childExpressions(e)...) {
+				want := !seen[c.String()]
+				seen[c.String()] = true
+				if got := s.add(c); got != want {
+					t.Errorf("add(%s) = %t, want %t", c, got, want)
+				}
+			}
+		}
+	}
+}
+
+func Test_expression_equal(t *testing.T) {
+	a := makeAdd(makeConstant(10), makeMultiply(makeConstant(5), makeConstant(2)))
+	tests := map[string]struct {
+		other expression
+		want  bool
+	}{
+		"same":           {other: makeAdd(makeConstant(10), makeMultiply(makeConstant(5), makeConstant(2))), want: true},
+		"swapped":        {other: makeAdd(makeMultiply(makeConstant(5), makeConstant(2)), makeConstant(10))},
+		"same value":     {other: makeAdd(makeConstant(10), makeConstant(10))},
+		"different op":   {other: makeAdd(makeConstant(10), makeAdd(makeConstant(5), makeConstant(2)))},
+		"fewer children": {other: makeConstant(20)},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			if got := a.equal(tt.other); got != tt.want {
+				t.Errorf("%s.equal(%s) = %t, want %t", a, tt.other, got, tt.want)
+			}
+			if tt.want && a.hash() != tt.other.hash() {
+				t.Errorf("%s.hash() != %s.hash()", a, tt.other)
+			}
+		})
+	}
+}
+
+// childExpressions returns every sub-expression of e.
+func childExpressions(e expression) []expression {
+	var out []expression
+	for _, c := range e.Children {
+		out = append(out, *c)
+		out = append(out, childExpressions(*c)...)
+	}
+	return out
+}
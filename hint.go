@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+)
+
+// hints returns graduated hints towards e, each giving away more than the last.
+// Each step of the solution gets three: its operation, then the numbers it uses, then the step itself.
+// The last hint is the whole solution.
+func hints(e expression, sym symbols) []string {
+	var out []string
+	for i, s := range e.steps() {
+		start := "Next, do"
+		numbers := "Next, try"
+		if i == 0 {
+			start = "Start with"
+			numbers = "Start by"
+		}
+		out = append(out,
+			fmt.Sprintf("%s %s", start, operationNoun(s.Op)),
+			fmt.Sprintf("%s %s", numbers, stepGerund(s)),
+			s.format(sym))
+	}
+	return append(out, fmt.Sprintf("%d = %s", e.Val, formatInfix(e.withSubtraction(), sym)))
+}
+
+func operationNoun(op operation) string {
+	switch op {
+	case opAdd:
+		return "an addition"
+	case opSubtract:
+		return "a subtraction"
+	case opMultiply:
+		return "a multiplication"
+	case opDivide:
+		return "a division"
+	}
+	return op.String()
+}
+
+// stepGerund describes which numbers s uses, e.g. "multiplying 9 and 7", without giving its result.
+func stepGerund(s step) string {
+	switch s.Op {
+	case opAdd:
+		return fmt.Sprintf("adding %d and %d", s.A, s.B)
+	case opSubtract:
+		return fmt.Sprintf("subtracting %d from %d", s.B, s.A)
+	case opMultiply:
+		return fmt.Sprintf("multiplying %d and %d", s.A, s.B)
+	case opDivide:
+		return fmt.Sprintf("dividing %d by %d", s.A, s.B)
+	}
+	return s.String()
+}
+
+// hintResult is a single hint, as written by digits hint --json.
+type hintResult struct {
+	Level  int    `json:"level"`
+	Levels int    `json:"levels"`
+	Hint   string `json:"hint"`
+}
+
+// hintAt returns the hint at level, counting from 1.
+func hintAt(e expression, sym symbols, level int) (hintResult, error) {
+	all := hints(e, sym)
+	if level < 1 || level > len(all) {
+		return hintResult{}, fmt.Errorf("level must be between 1 and %d, got %d", len(all), level)
+	}
+	return hintResult{Level: level, Levels: len(all), Hint: all[level-1]}, nil
+}
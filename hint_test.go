@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_hints(t *testing.T) {
+	tests := map[string]struct {
+		expr expression
+		want []string
+	}{
+		"constant": {
+			expr: makeConstant(7),
+			want: []string{"7 = 7"},
+		},
+		"chain": {
+			expr: makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25)),
+			want: []string{
+				"Start with a multiplication",
+				"Start by multiplying 9 and 7",
+				"9 * 7 = 63",
+				"Next, do an addition",
+				"Next, try adding 63 and 25",
+				"63 + 25 = 88",
+				"88 = 9*7 + 25",
+			},
+		},
+		"subtraction": {
+			expr: makeDivide(makeAdd(makeConstant(100), makeNegate(makeConstant(9))), makeConstant(7)),
+			want: []string{
+				"Start with a subtraction",
+				"Start by subtracting 9 from 100",
+				"100 - 9 = 91",
+				"Next, do a division",
+				"Next, try dividing 91 by 7",
+				"91 / 7 = 13",
+				"13 = (100 - 9)/7",
+			},
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := hints(tt.expr, opStrings)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("hints() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_hintAt(t *testing.T) {
+	expr := makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25))
+
+	got, err := hintAt(expr, opStrings, 2)
+	if err != nil {
+		t.Fatalf("hintAt(2) failed unexpectedly: %v", err)
+	}
+	want := hintResult{Level: 2, Levels: 7, Hint: "Start by multiplying 9 and 7"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("hintAt(2) mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, level := range []int{0, 8} {
+		if _, err := hintAt(expr, opStrings, level); err == nil {
+			t.Errorf("hintAt(%d) succeeded unexpectedly", level)
+		}
+	}
+}
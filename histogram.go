@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// histogramWidth is the length of the longest bar drawn by formatHistogram.
+const histogramWidth = 50
+
+// countBucket is a range of solution counts (inclusive) and the number of targets whose count falls in it.
+type countBucket struct {
+	Lo, Hi  int
+	Targets int
+}
+
+// bucketCounts groups solution counts into buckets 0, 1, 2-3, 4-7, 8-15... up to the largest count.
+func bucketCounts(counts []int) []countBucket {
+	maxCount := 0
+	for _, c := range counts {
+		if c > maxCount {
+			maxCount = c
+		}
+	}
+
+	buckets := []countBucket{{Lo: 0, Hi: 0}}
+	for lo := 1; lo <= maxCount; lo *= 2 {
+		buckets = append(buckets, countBucket{Lo: lo, Hi: 2*lo - 1})
+	}
+
+	for _, c := range counts {
+		for i := range buckets {
+			if c >= buckets[i].Lo && c <= buckets[i].Hi {
+				buckets[i].Targets++
+				break
+			}
+		}
+	}
+	return buckets
+}
+
+// countSummary returns the minimum, median and maximum of counts.
+func countSummary(counts []int) (int, float64, int) {
+	if len(counts) == 0 {
+		return 0, 0, 0
+	}
+
+	sorted := append([]int{}, counts...)
+	sort.Ints(sorted)
+
+	n := len(sorted)
+	median := float64(sorted[n/2])
+	if n%2 == 0 {
+		median = float64(sorted[n/2-1]+sorted[n/2]) / 2
+	}
+	return sorted[0], median, sorted[n-1]
+}
+
+// formatHistogram draws an ASCII histogram of how many targets had each number of solutions.
+func formatHistogram(counts []int) string {
+	buckets := bucketCounts(counts)
+
+	most := 0
+	for _, bk := range buckets {
+		if bk.Targets > most {
+			most = bk.Targets
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("Solutions per target:\n")
+	for _, bk := range buckets {
+		label := fmt.Sprintf("%d", bk.Lo)
+		if bk.Hi != bk.Lo {
+			label = fmt.Sprintf("%d-%d", bk.Lo, bk.Hi)
+		}
+		bar := 0
+		if most > 0 {
+			bar = (bk.Targets*histogramWidth + most - 1) / most
+		}
+		fmt.Fprintf(&b, "%12s | %s %d\n", label, strings.Repeat("#", bar), bk.Targets)
+	}
+
+	min, median, max := countSummary(counts)
+	fmt.Fprintf(&b, "min: %d, median: %g, max: %d\n", min, median, max)
+	return b.String()
+}
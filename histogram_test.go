@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_bucketCounts(t *testing.T) {
+	got := bucketCounts([]int{0, 0, 1, 2, 3, 5, 9})
+	want := []countBucket{
+		{Lo: 0, Hi: 0, Targets: 2},
+		{Lo: 1, Hi: 1, Targets: 1},
+		{Lo: 2, Hi: 3, Targets: 2},
+		{Lo: 4, Hi: 7, Targets: 1},
+		{Lo: 8, Hi: 15, Targets: 1},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("bucketCounts() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_countSummary(t *testing.T) {
+	tests := map[string]struct {
+		counts     []int
+		wantMin    int
+		wantMedian float64
+		wantMax    int
+	}{
+		"empty": {},
+		"odd": {
+			counts:     []int{9, 0, 3},
+			wantMin:    0,
+			wantMedian: 3,
+			wantMax:    9,
+		},
+		"even": {
+			counts:     []int{4, 1, 2, 8},
+			wantMin:    1,
+			wantMedian: 3,
+			wantMax:    8,
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			min, median, max := countSummary(tt.counts)
+			if min != tt.wantMin || median != tt.wantMedian || max != tt.wantMax {
+				t.Errorf("countSummary() = %d, %g, %d, want %d, %g, %d", min, median, max, tt.wantMin, tt.wantMedian, tt.wantMax)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+// infixOps maps the operator characters accepted by parseInfix to operations.
+var infixOps = map[rune]operation{
+	'+': opAdd,
+	'-': opSubtract,
+	'−': opSubtract,
+	'*': opMultiply,
+	'×': opMultiply,
+	'x': opMultiply,
+	'/': opDivide,
+	'÷': opDivide,
+}
+
+type infixToken struct {
+	// pos is the 1-based character position of the token, for error messages.
+	pos   int
+	op    operation
+	num   int
+	paren rune
+}
+
+func tokenizeInfix(s string) ([]infixToken, error) {
+	var tokens []infixToken
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+		case r == '(' || r == ')':
+			tokens = append(tokens, infixToken{pos: i + 1, paren: r})
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && unicode.IsDigit(runes[j]) {
+				j++
+			}
+			v, err := strconv.Atoi(string(runes[i:j]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", string(runes[i:j]), i+1)
+			}
+			tokens = append(tokens, infixToken{pos: i + 1, num: v})
+			i = j - 1
+		default:
+			op, ok := infixOps[r]
+			if !ok {
+				return nil, fmt.Errorf("unexpected %q at position %d", r, i+1)
+			}
+			tokens = append(tokens, infixToken{pos: i + 1, op: op})
+		}
+	}
+	return tokens, nil
+}
+
+// parseInfix reads an arithmetic expression as a person would write it, e.g. "(9*7) + 25 + 5".
+// Operators bind with the usual precedence and associate to the left, and a leading minus negates.
+// The expression's values are not checked for legality; use eval for that.
+func parseInfix(s string) (expression, error) {
+	tokens, err := tokenizeInfix(s)
+	if err != nil {
+		return expression{}, err
+	}
+	if len(tokens) == 0 {
+		return expression{}, fmt.Errorf("empty expression")
+	}
+
+	p := infixParser{tokens: tokens}
+	e, err := p.sum()
+	if err != nil {
+		return expression{}, err
+	}
+	if t, ok := p.peek(); ok {
+		return expression{}, fmt.Errorf("unexpected %s at position %d", t, t.pos)
+	}
+	return e, nil
+}
+
+func (t infixToken) String() string {
+	switch {
+	case t.paren != 0:
+		return fmt.Sprintf("%q", t.paren)
+	case t.op != opNone:
+		return fmt.Sprintf("%q", t.op.String())
+	}
+	return fmt.Sprintf("%d", t.num)
+}
+
+type infixParser struct {
+	tokens []infixToken
+	next   int
+}
+
+func (p *infixParser) peek() (infixToken, bool) {
+	if p.next >= len(p.tokens) {
+		return infixToken{}, false
+	}
+	return p.tokens[p.next], true
+}
+
+// sum parses terms joined by + and -.
+func (p *infixParser) sum() (expression, error) {
+	e, err := p.product()
+	if err != nil {
+		return expression{}, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || (t.op != opAdd && t.op != opSubtract) {
+			return e, nil
+		}
+		p.next++
+		rhs, err := p.product()
+		if err != nil {
+			return expression{}, err
+		}
+		e = makeInfixBinary(t.op, e, rhs)
+	}
+}
+
+// product parses factors joined by * and /.
+func (p *infixParser) product() (expression, error) {
+	e, err := p.factor()
+	if err != nil {
+		return expression{}, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || (t.op != opMultiply && t.op != opDivide) {
+			return e, nil
+		}
+		p.next++
+		rhs, err := p.factor()
+		if err != nil {
+			return expression{}, err
+		}
+		e = makeInfixBinary(t.op, e, rhs)
+	}
+}
+
+// factor parses a number, a parenthesized expression, or a negated factor.
+func (p *infixParser) factor() (expression, error) {
+	t, ok := p.peek()
+	if !ok {
+		return expression{}, fmt.Errorf("unexpected end of expression")
+	}
+	p.next++
+	switch {
+	case t.op == opSubtract:
+		e, err := p.factor()
+		if err != nil {
+			return expression{}, err
+		}
+		return makeNegate(e), nil
+	case t.paren == '(':
+		e, err := p.sum()
+		if err != nil {
+			return expression{}, err
+		}
+		if c, ok := p.peek(); !ok || c.paren != ')' {
+			return expression{}, fmt.Errorf("missing %q for %q at position %d", ')', '(', t.pos)
+		}
+		p.next++
+		return e, nil
+	case t.paren == 0 && t.op == opNone:
+		return makeConstant(t.num), nil
+	}
+	return expression{}, fmt.Errorf("unexpected %s at position %d", t, t.pos)
+}
+
+// makeInfixBinary is makeBinary, except that division by zero and inexact division are left for eval to reject.
+func makeInfixBinary(op operation, a, b expression) expression {
+	if op == opDivide && b.Val == 0 {
+		return expression{Op: opDivide, Children: []*expression{&a, &b}}
+	}
+	return makeBinary(op, a, b)
+}
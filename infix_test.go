@@ -0,0 +1,108 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseInfix(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		want    string
+		wantVal int
+	}{
+		"constant": {
+			input:   "7",
+			want:    "7",
+			wantVal: 7,
+		},
+		"precedence": {
+			input:   "9*7 + 25 + 5",
+			want:    "(((9 * 7) + 25) + 5)",
+			wantVal: 93,
+		},
+		"parentheses": {
+			input:   "(9*7)+25+5",
+			want:    "(((9 * 7) + 25) + 5)",
+			wantVal: 93,
+		},
+		"left-associative": {
+			input:   "25 - 10 - 5",
+			want:    "((25 - 10) - 5)",
+			wantVal: 10,
+		},
+		"grouped": {
+			input:   "100 / (5 * 2)",
+			want:    "(100 / (5 * 2))",
+			wantVal: 10,
+		},
+		"unicode": {
+			input:   "25×4 ÷ 5 − 3",
+			want:    "(((25 * 4) / 5) - 3)",
+			wantVal: 17,
+		},
+		"negation": {
+			input:   "-3 + 10",
+			want:    "(-3 + 10)",
+			wantVal: 7,
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got, err := parseInfix(tt.input)
+			if err != nil {
+				t.Fatalf("parseInfix(%q) failed unexpectedly: %v", tt.input, err)
+			}
+			if !cmp.Equal(got.String(), tt.want) {
+				t.Errorf("parseInfix(%q) = %q, want %q", tt.input, got.String(), tt.want)
+			}
+			if got.Val != tt.wantVal {
+				t.Errorf("parseInfix(%q).Val = %d, want %d", tt.input, got.Val, tt.wantVal)
+			}
+		})
+	}
+}
+
+func Test_parseInfix_errors(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		want  string
+	}{
+		"empty": {
+			input: " ",
+			want:  "empty expression",
+		},
+		"unknown character": {
+			input: "3 % 2",
+			want:  `unexpected '%' at position 3`,
+		},
+		"unbalanced": {
+			input: "(3 + 2",
+			want:  `missing ')' for '(' at position 1`,
+		},
+		"trailing": {
+			input: "3 + 2)",
+			want:  `unexpected ')' at position 6`,
+		},
+		"missing operand": {
+			input: "3 +",
+			want:  "unexpected end of expression",
+		},
+		"adjacent numbers": {
+			input: "3 4",
+			want:  "unexpected 4 at position 3",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			_, err := parseInfix(tt.input)
+			if err == nil {
+				t.Fatalf("parseInfix(%q) succeeded unexpectedly", tt.input)
+			}
+			if !cmp.Equal(err.Error(), tt.want) {
+				t.Errorf("parseInfix(%q) error = %q, want %q", tt.input, err.Error(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// latexFormatter writes expressions as LaTeX math.
+type latexFormatter struct {
+	// multiply is the command used for multiplication, e.g. `\times`.
+	multiply string
+}
+
+var latexMultiplyCommands = map[string]string{
+	"times": `\times`,
+	"cdot":  `\cdot`,
+}
+
+func newLaTeXFormatter(multiply string) (latexFormatter, error) {
+	cmd, ok := latexMultiplyCommands[multiply]
+	if !ok {
+		return latexFormatter{}, fmt.Errorf("unknown multiplication symbol %q", multiply)
+	}
+	return latexFormatter{multiply: cmd}, nil
+}
+
+// format writes e as LaTeX math (without the surrounding $...$).
+// Division is typeset with \frac, so fractions never need parentheses.
+func (f latexFormatter) format(e expression) string {
+	return f.formatNode(e.withSubtraction())
+}
+
+func (f latexFormatter) formatNode(e expression) string {
+	switch e.Op {
+	case opNone:
+		return fmt.Sprintf("%d", e.Val)
+	case opNegate:
+		c := *e.Children[0]
+		if c.Op != opNone {
+			return fmt.Sprintf(`-\left(%s\right)`, f.formatNode(c))
+		}
+		return fmt.Sprintf("-%s", f.formatNode(c))
+	case opDivide:
+		// Nested divisions become nested fractions: a / b / c is \frac{\frac{a}{b}}{c}.
+		acc := f.formatNode(*e.Children[0])
+		for _, c := range e.Children[1:] {
+			acc = fmt.Sprintf(`\frac{%s}{%s}`, acc, f.formatNode(*c))
+		}
+		return acc
+	}
+
+	sep := fmt.Sprintf(" %s ", e.Op)
+	if e.Op == opMultiply {
+		sep = fmt.Sprintf(" %s ", f.multiply)
+	}
+
+	parts := make([]string, len(e.Children))
+	for i, c := range e.Children {
+		s := f.formatNode(*c)
+		if c.Op != opDivide && (c.precedence() < e.precedence() || (i > 0 && !e.Op.commutative() && c.precedence() == e.precedence())) {
+			s = fmt.Sprintf(`\left(%s\right)`, s)
+		}
+		parts[i] = s
+	}
+	return strings.Join(parts, sep)
+}
+
+// writeDocument writes a standalone LaTeX document listing solns.
+func (f latexFormatter) writeDocument(w io.Writer, target int, digits []int, solns []expression, shortest expression) error {
+	digitStrs := make([]string, len(digits))
+	for i, d := range digits {
+		digitStrs[i] = fmt.Sprintf("%d", d)
+	}
+
+	var b strings.Builder
+	b.WriteString("\\documentclass{article}\n")
+	b.WriteString("\\usepackage{amsmath}\n")
+	b.WriteString("\\begin{document}\n")
+	fmt.Fprintf(&b, "\\section*{Making %d from %s}\n", target, strings.Join(digitStrs, ", "))
+	fmt.Fprintf(&b, "Shortest solution: $%d = %s$\n", target, f.format(shortest))
+	b.WriteString("\\begin{enumerate}\n")
+	for _, soln := range solns {
+		fmt.Fprintf(&b, "\\item $%d = %s$\n", target, f.format(soln))
+	}
+	b.WriteString("\\end{enumerate}\n")
+	b.WriteString("\\end{document}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_latexFormatter(t *testing.T) {
+	tests := map[string]struct {
+		multiply string
+		expr     expression
+		want     string
+	}{
+		"fused": {
+			multiply: "times",
+			expr:     makeAdd(makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25)), makeConstant(5)).fuse(),
+			want:     `9 \times 7 + 25 + 5`,
+		},
+		"cdot": {
+			multiply: "cdot",
+			expr:     makeMultiply(makeAdd(makeConstant(25), makeConstant(11)), makeConstant(9)),
+			want:     `\left(25 + 11\right) \cdot 9`,
+		},
+		"frac": {
+			multiply: "times",
+			expr:     makeAdd(makeDivide(makeAdd(makeConstant(25), makeNegate(makeConstant(4))), makeConstant(7)), makeConstant(5)),
+			want:     `\frac{25 - 4}{7} + 5`,
+		},
+		"product of fraction": {
+			multiply: "times",
+			expr:     makeMultiply(makeDivide(makeConstant(25), makeConstant(5)), makeConstant(3)),
+			want:     `\frac{25}{5} \times 3`,
+		},
+		"nested frac": {
+			multiply: "times",
+			expr:     makeDivide(makeDivide(makeConstant(100), makeConstant(5)), makeConstant(4)),
+			want:     `\frac{\frac{100}{5}}{4}`,
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			f, err := newLaTeXFormatter(tt.multiply)
+			if err != nil {
+				t.Fatalf("newLaTeXFormatter(%q) failed unexpectedly: %v", tt.multiply, err)
+			}
+			got := f.format(tt.expr)
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
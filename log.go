@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// logFlags are the --verbose and --quiet flags, registered on every command by newFlagSet.
+var logFlags struct {
+	verbose bool
+	quiet   bool
+}
+
+func addLogFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&logFlags.verbose, "verbose", false, "Log debugging details to stderr, including the solver's pruning decisions")
+	fs.BoolVar(&logFlags.quiet, "quiet", false, "Only log errors")
+}
+
+// configureLogging sends logs to stderr, keeping stdout for results, at the level chosen by the log flags.
+func configureLogging() {
+	level := slog.LevelInfo
+	switch {
+	case logFlags.quiet:
+		level = slog.LevelError
+	case logFlags.verbose:
+		level = slog.LevelDebug
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+}
+
+// Exit statuses, so scripts can tell an unsolvable puzzle from a mistyped flag.
+const (
+	exitSolved = 0
+	// exitNoSolution is also used when an answer is wrong, or only an approximate answer was found.
+	exitNoSolution = 1
+	// exitInvalidInput matches the status the flag package uses for bad flags.
+	exitInvalidInput = 2
+	// exitIncomplete means a search timed out, so the results may be partial.
+	exitIncomplete = 3
+	// exitFailed is for anything else going wrong, like failing to write the results.
+	exitFailed = 4
+	// exitInterrupted is the conventional status for a process stopped by SIGINT.
+	exitInterrupted = 130
+)
+
+// fatalf logs an error and exits with exitFailed.
+func fatalf(format string, args ...any) {
+	slog.Error(fmt.Sprintf(format, args...))
+	exit(exitFailed)
+}
+
+// usagef logs an error about the command's input and exits with exitInvalidInput.
+func usagef(format string, args ...any) {
+	slog.Error(fmt.Sprintf(format, args...))
+	exit(exitInvalidInput)
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"runtime"
+	"text/template"
+	"time"
+)
+
+// command is a subcommand of the digits binary, e.g. "digits solve".
+type command struct {
+	name    string
+	summary string
+	// run runs the command and returns its exit status.
+	// ctx is cancelled on Ctrl-C, so long runs can stop early and write what they have.
+	run func(ctx context.Context, args []string) int
+}
+
+var commands = []command{
+	{"solve", "Find every way to make a target from the digits", runSolve},
+	{"range", "Count the solutions for each target in a range", runRange},
+	{"merge", "Combine the results of a range run split up with --shard", runMerge},
+	{"batch", "Solve puzzles read from stdin, one digits:target per line", runBatch},
+	{"repl", "Answer queries about a set of digits interactively", runREPL},
+	{"pipe", "Answer targets read from stdin, one per line, for another program to drive", runPipe},
+	{"generate", "Generate random solvable puzzles, worksheets and flashcards", runGenerate},
+	{"play", "Play a puzzle interactively in the terminal", runPlay},
+	{"hint", "Give a hint towards a solution, each level giving away more", runHint},
+	{"verify", "Check an answer uses the digits legally and makes the target", runVerify},
+	{"analyze", "Analyze which targets in a range some digits can make, for plotting", runAnalyze},
+	{"rate", "Rate how hard a puzzle is, from 1 to 5", runRate},
+	{"serve", "Serve the HTTP JSON API, and a web UI for solving and playing puzzles", runServe},
+	{"precompute", "Solve every Countdown selection into --cache_dir, so their queries are lookups", runPrecompute},
+	{"selftest", "Check the solvers' invariants on random puzzles, and against a brute-force solver on small ones", runSelfTest},
+	{"trace", "Summarize where a search went, from a trace written by solve --search_trace", runTrace},
+	{"bench", "Time a standard suite of workloads, to compare performance between versions", runBench},
+	{"cache", "Show the stats of, or purge, the solutions kept by --cache_dir", runCache},
+}
+
+// runUntilInterrupted holds the commands which Ctrl-C is the usual way to stop, so their exit status is
+// the one they return after stopping cleanly, rather than 130.
+var runUntilInterrupted = map[string]bool{"serve": true}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: digits <command> [flags]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun 'digits <command> -h' to see the flags for a command.\n")
+	fmt.Fprintf(os.Stderr, "\nExit status: 0 solved, 1 no solution, 2 invalid input, 3 timed out with partial results,\n")
+	fmt.Fprintf(os.Stderr, "4 any other failure, 130 interrupted.\n")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(exitInvalidInput)
+	}
+
+	name := os.Args[1]
+	for _, c := range commands {
+		if c.name == name {
+			// stop isn't deferred: cancelling ctx on return would look like an interrupt
+			// to commands which watch it to exit early.
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			go func() {
+				// Restore the default behaviour so a second Ctrl-C exits immediately.
+				<-ctx.Done()
+				stop()
+			}()
+			code := c.run(ctx, os.Args[2:])
+			if ctx.Err() != nil && !runUntilInterrupted[c.name] {
+				code = exitInterrupted
+			}
+			exit(code)
+		}
+	}
+
+	switch name {
+	case "help", "-h", "-help", "--help":
+		usage()
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+	usage()
+	os.Exit(exitInvalidInput)
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("digits "+name, flag.ExitOnError)
+	addLogFlags(fs)
+	addConfigFlags(fs)
+	addProfileFlags(fs)
+	return fs
+}
+
+// parseArgs parses the flags in args, which may be interleaved with positional arguments,
+// and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			if err := applyConfig(fs); err != nil {
+				// Logging isn't configured yet, but errors are always logged.
+				usagef("Invalid config: %v", err)
+			}
+			configureLogging()
+			startProfiling()
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// mustParseDigits returns the digits given by a --digits flag or positional arguments,
+// exiting if they're missing or invalid, and warning if they couldn't come up in Countdown.
+func mustParseDigits(flagValue string, args []string) []int {
+	digits, err := parseDigitArgs(flagValue, args)
+	if err != nil {
+		usagef("Invalid digits: %v", err)
+	}
+	for _, note := range poolRepeats(digits) {
+		slog.Warn("Digits break the Countdown rules: " + note)
+	}
+	return digits
+}
+
+// addFilterFlags registers the flags which restrict the solutions found.
+func addFilterFlags(fs *flag.FlagSet) *solutionFilter {
+	f := &solutionFilter{}
+	fs.BoolVar(&f.noDivision, "no_division", false, "Only find solutions without division")
+	fs.BoolVar(&f.noNegation, "no_negation", false, "Only find solutions without subtraction")
+	fs.IntVar(&f.maxOps, "max_ops", 0, "If positive, only find solutions with at most this many operations")
+	fs.IntVar(&f.maxDigits, "max_digits_used", 0, "If positive, only find solutions using at most this many digits")
+	fs.BoolVar(&f.noTrivial, "no_trivial", false, "Only find solutions without a part which does nothing, like multiplying or dividing by something making 1, or adding or subtracting something making 0")
+	return f
+}
+
+// addGeneratorFlags registers the flags choosing what puzzles are generated, which default to defaultGeneratorOptions.
+func addGeneratorFlags(fs *flag.FlagSet) *generatorOptions {
+	o := defaultGeneratorOptions
+	fs.IntVar(&o.Large, "large", o.Large, "How many large numbers (25, 50, 75, 100) each puzzle uses, or -1 for a random number")
+	fs.IntVar(&o.MinRating, "min_rating", o.MinRating, "If positive, only generate puzzles rated at least this hard, from 1 to 5; see digits rate")
+	fs.IntVar(&o.MaxRating, "max_rating", o.MaxRating, "If positive, only generate puzzles rated at most this hard, from 1 to 5")
+	return &o
+}
+
+// addTimeoutFlag registers the --timeout flag bounding each search.
+func addTimeoutFlag(fs *flag.FlagSet) *time.Duration {
+	return fs.Duration("timeout", 0, "If positive, stop each search after this long, e.g. 2s, and report the solutions found so far as incomplete")
+}
+
+// addMaxNodesFlag registers the --max_nodes flag bounding how much work each search does.
+func addMaxNodesFlag(fs *flag.FlagSet) *int64 {
+	return fs.Int64("max_nodes", 0, "If positive, stop each search after trying this many sub-problems, and report the solutions found so far as incomplete; unlike --timeout, the same budget gives the same results on any machine")
+}
+
+// addCacheFlag registers the --cache_dir flag choosing where solutions are kept between runs.
+func addCacheFlag(fs *flag.FlagSet) *string {
+	return fs.String("cache_dir", "", "If set, keep the solutions found in this directory, so repeat queries are answered straight away, even by later runs")
+}
+
+// mustValidateTarget exits with a usage error if target can't be a puzzle's.
+func mustValidateTarget(target int) {
+	if err := validateTarget(target); err != nil {
+		usagef("--target invalid: %v", err)
+	}
+}
+
+// mustOpenCache opens the cache in dir, or returns nil if dir is empty.
+func mustOpenCache(dir string) *diskCache {
+	if dir == "" {
+		return nil
+	}
+	c, err := openDiskCache(dir)
+	if err != nil {
+		fatalf("Failed to open the solution cache: %v", err)
+	}
+	return c
+}
+
+// addSeedFlag registers the --seed flag for commands which make random choices.
+func addSeedFlag(fs *flag.FlagSet) *int64 {
+	return fs.Int64("seed", 0, "If non-zero, make the same random choices each run with this seed, e.g. to share puzzles; otherwise the seed is random and logged with --verbose")
+}
+
+// addEngineFlag registers the --engine flag choosing how solutions are found.
+func addEngineFlag(fs *flag.FlagSet) *string {
+	return fs.String("engine", "search", "How to find solutions: search (every solution), subsets (one solution per target, from any split of the digits, and much faster for ranges) or splits (every solution, from any split of the digits), or quick (one solution, found fast by trying the most promising steps first, but it gives up rather than search every step, so it can miss solutions)")
+}
+
+// addParallelismFlag registers the --parallelism flag for commands which can solve on several goroutines.
+// Results are identical whatever it's set to, so runs can be diffed.
+func addParallelismFlag(fs *flag.FlagSet) *int {
+	return fs.Int("parallelism", runtime.GOMAXPROCS(0), "How many goroutines to solve with: solve searches this many digit choices at once, and range solves this many targets at once")
+}
+
+// progressInterval is how often --progress updates its status line.
+const progressInterval = 250 * time.Millisecond
+
+// printerFlags are the flags controlling how solutions are written out.
+type printerFlags struct {
+	notation string
+	unicode  bool
+}
+
+func addPrinterFlags(fs *flag.FlagSet) *printerFlags {
+	p := &printerFlags{}
+	fs.StringVar(&p.notation, "notation", "infix", "How to write solutions: infix, full (fully parenthesized), binary (one operation per step), rpn or sexpr")
+	fs.BoolVar(&p.unicode, "unicode", false, "Print operators as ×, ÷ and − rather than ASCII")
+	return p
+}
+
+func (p *printerFlags) printer() printer {
+	n, err := parseNotation(p.notation)
+	if err != nil {
+		usagef("--notation invalid: %v", err)
+	}
+	return newPrinter(n, p.unicode)
+}
+
+// outputFlags are the flags controlling where and how solutions are written, shared by all commands which write results.
+type outputFlags struct {
+	*printerFlags
+	out      string
+	template string
+}
+
+func addOutputFlags(fs *flag.FlagSet) *outputFlags {
+	o := &outputFlags{printerFlags: addPrinterFlags(fs)}
+	fs.StringVar(&o.out, "out", "", "If set, write results to this path instead of stdout; the file is only replaced once complete")
+	fs.StringVar(&o.template, "template", "", "If set, write each result using this text/template, e.g. '{{.Target}} = {{.Shortest}}'")
+	return o
+}
+
+// outputTemplate returns the parsed --template, or nil if it isn't set.
+func (o *outputFlags) outputTemplate() *template.Template {
+	if o.template == "" {
+		return nil
+	}
+	t, err := parseOutputTemplate(o.template)
+	if err != nil {
+		usagef("--template invalid: %v", err)
+	}
+	return t
+}
+
+// addOutFlag registers the --out flag of a command writing what, and returns a function opening it,
+// like outputFlags.open, for commands without the rest of the output flags.
+func addOutFlag(fs *flag.FlagSet, what string) func() (io.Writer, func()) {
+	outPath := fs.String("out", "", fmt.Sprintf("If set, write the %s to this path instead of stdout; the file is only replaced once complete", what))
+	return func() (io.Writer, func()) {
+		out, closeOut, err := openOutput(*outPath)
+		if err != nil {
+			usagef("--out invalid: %v", err)
+		}
+		return out, func() {
+			if err := closeOut(); err != nil {
+				fatalf("Failed to write %s: %v", *outPath, err)
+			}
+		}
+	}
+}
+
+// open returns the writer for results, and a function to call once they've all been written.
+func (o *outputFlags) open() (io.Writer, func()) {
+	out, closeOut, err := openOutput(o.out)
+	if err != nil {
+		usagef("--out invalid: %v", err)
+	}
+	return out, func() {
+		if err := closeOut(); err != nil {
+			fatalf("Failed to write %s: %v", o.out, err)
+		}
+	}
+}
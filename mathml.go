@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+var mathMLOps = map[operation]string{
+	opAdd:      "+",
+	opSubtract: "&minus;",
+	opMultiply: "&times;",
+	opNegate:   "&minus;",
+}
+
+// formatMathML writes e as a MathML <math> element.
+// Division is rendered as <mfrac>, so fractions never need parentheses.
+func formatMathML(e expression) string {
+	return fmt.Sprintf("<math><mrow>%s</mrow></math>", formatMathMLNode(e.withSubtraction()))
+}
+
+func formatMathMLNode(e expression) string {
+	switch e.Op {
+	case opNone:
+		return fmt.Sprintf("<mn>%d</mn>", e.Val)
+	case opNegate:
+		c := *e.Children[0]
+		s := formatMathMLNode(c)
+		if c.Op != opNone {
+			s = mathMLParens(s)
+		}
+		return fmt.Sprintf("<mo>%s</mo>%s", mathMLOps[opNegate], s)
+	case opDivide:
+		acc := formatMathMLNode(*e.Children[0])
+		for _, c := range e.Children[1:] {
+			acc = fmt.Sprintf("<mfrac><mrow>%s</mrow><mrow>%s</mrow></mfrac>", acc, formatMathMLNode(*c))
+		}
+		return acc
+	}
+
+	var b strings.Builder
+	for i, c := range e.Children {
+		if i > 0 {
+			fmt.Fprintf(&b, "<mo>%s</mo>", mathMLOps[e.Op])
+		}
+		s := formatMathMLNode(*c)
+		if c.Op != opDivide && (c.precedence() < e.precedence() || (i > 0 && !e.Op.commutative() && c.precedence() == e.precedence())) {
+			s = mathMLParens(s)
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+func mathMLParens(s string) string {
+	return fmt.Sprintf("<mrow><mo>(</mo>%s<mo>)</mo></mrow>", s)
+}
+
+// writeMathML writes an HTML fragment listing solns as MathML.
+func writeMathML(w io.Writer, target int, solns []expression, shortest expression) error {
+	equation := func(e expression) string {
+		m := formatMathML(e)
+		return strings.Replace(m, "<mrow>", fmt.Sprintf("<mrow><mn>%d</mn><mo>=</mo>", target), 1)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<p>Shortest solution: %s</p>\n", equation(shortest))
+	b.WriteString("<ol>\n")
+	for _, soln := range solns {
+		fmt.Fprintf(&b, "<li>%s</li>\n", equation(soln))
+	}
+	b.WriteString("</ol>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_formatMathML(t *testing.T) {
+	tests := map[string]struct {
+		expr expression
+		want string
+	}{
+		"constant": {
+			expr: makeConstant(7),
+			want: "<math><mrow><mn>7</mn></mrow></math>",
+		},
+		"product": {
+			expr: makeMultiply(makeAdd(makeConstant(25), makeConstant(11)), makeConstant(9)),
+			want: "<math><mrow><mrow><mo>(</mo><mn>25</mn><mo>+</mo><mn>11</mn><mo>)</mo></mrow><mo>&times;</mo><mn>9</mn></mrow></math>",
+		},
+		"frac": {
+			expr: makeDivide(makeAdd(makeConstant(25), makeNegate(makeConstant(4))), makeConstant(7)),
+			want: "<math><mrow><mfrac><mrow><mn>25</mn><mo>&minus;</mo><mn>4</mn></mrow><mrow><mn>7</mn></mrow></mfrac></mrow></math>",
+		},
+		"negate": {
+			expr: makeNegate(makeMultiply(makeConstant(9), makeConstant(7))),
+			want: "<math><mrow><mo>&minus;</mo><mrow><mo>(</mo><mn>9</mn><mo>&times;</mo><mn>7</mn><mo>)</mo></mrow></mrow></math>",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := formatMathML(tt.expr)
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("formatMathML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// formatMermaid writes e as a top-down Mermaid flowchart.
+// Operation nodes are annotated with their intermediate values and constants are drawn as circles.
+func formatMermaid(e expression, sym symbols) string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+
+	id := 0
+	var walk func(e expression) string
+	walk = func(e expression) string {
+		name := fmt.Sprintf("n%d", id)
+		id++
+		if e.Op == opNone {
+			fmt.Fprintf(&b, "    %s((\"%d\"))\n", name, e.Val)
+			return name
+		}
+		fmt.Fprintf(&b, "    %s[\"%s<br/>%d\"]\n", name, sym[e.Op], e.Val)
+		for _, c := range e.Children {
+			fmt.Fprintf(&b, "    %s --> %s\n", name, walk(*c))
+		}
+		return name
+	}
+	walk(e.withSubtraction())
+	return b.String()
+}
+
+// writeMermaid writes a Markdown document with a Mermaid diagram for each solution.
+func writeMermaid(w io.Writer, target int, solns []expression, p printer) error {
+	var b strings.Builder
+	for i, soln := range solns {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "### %d = %s\n\n", target, p.format(soln))
+		fmt.Fprintf(&b, "```mermaid\n%s```\n", formatMermaid(soln, p.symbols))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_formatMermaid(t *testing.T) {
+	tests := map[string]struct {
+		expr expression
+		want string
+	}{
+		"constant": {
+			expr: makeConstant(7),
+			want: "flowchart TD\n" +
+				"    n0((\"7\"))\n",
+		},
+		"nested": {
+			expr: makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeNegate(makeConstant(5))),
+			want: "flowchart TD\n" +
+				"    n0[\"-<br/>58\"]\n" +
+				"    n1[\"*<br/>63\"]\n" +
+				"    n2((\"9\"))\n" +
+				"    n1 --> n2\n" +
+				"    n3((\"7\"))\n" +
+				"    n1 --> n3\n" +
+				"    n0 --> n1\n" +
+				"    n4((\"5\"))\n" +
+				"    n0 --> n4\n",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := formatMermaid(tt.expr, opStrings)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("formatMermaid() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+	"sort"
+)
+
+// oracleSolve finds every solution for target the slow way: it tries every ordering of every group of the digits,
+// every way of bracketing them, and every operation between each pair, keeping those where each value worked out
+// is a positive whole number, as solve's are. It's far slower than solve, but simple enough to trust, so it's
+// the reference solve is checked against. It reports whether ctx was done before it finished.
+func (s solver) oracleSolve(target int, digits []int) ([]expression, bool) {
+	var solutions []expression
+	var seen expressionSet
+	for mask := 1; mask < 1<<len(digits); mask++ {
+		var group []int
+		for m := mask; m != 0; m &= m - 1 {
+			group = append(group, digits[bits.TrailingZeros(uint(m))])
+		}
+		for p := make([]int, len(group)); p[0] < len(p); nextPerm(p) {
+			if s.stopped() {
+				return solutions, true
+			}
+			for _, e := range oracleBracketings(getPerm(group, p), target) {
+				if s.filter.keep(e) && seen.add(e) {
+					solutions = append(solutions, e)
+				}
+			}
+		}
+	}
+	return solutions, false
+}
+
+// oracleBracketings returns every expression which uses nums in the order given, bracketed in any way, with
+// any operations between them. If target is positive, it only returns those which make it.
+func oracleBracketings(nums []int, target int) []expression {
+	if len(nums) == 1 {
+		if target > 0 && nums[0] != target {
+			return nil
+		}
+		return []expression{makeConstant(nums[0])}
+	}
+	var out []expression
+	for split := 1; split < len(nums); split++ {
+		for _, a := range oracleBracketings(nums[:split], 0) {
+			for _, b := range oracleBracketings(nums[split:], 0) {
+				for _, e := range oracleCombine(a, b) {
+					if target <= 0 || e.Val == target {
+						out = append(out, e)
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// oracleCombine returns a combined with b by each operation which makes a positive whole number,
+// normalized like solve's solutions.
+func oracleCombine(a, b expression) []expression {
+	out := []expression{makeAdd(a, b), makeMultiply(a, b)}
+	if a.Val > b.Val {
+		out = append(out, makeAdd(a, makeNegate(b)))
+	}
+	if a.Val%b.Val == 0 {
+		out = append(out, makeDivide(a, b))
+	}
+	for i, e := range out {
+		out[i] = e.fuse().canonicalize()
+	}
+	return out
+}
+
+// crosscheckReport compares the solutions found by a solver with those found by oracleSolve.
+type crosscheckReport struct {
+	Found, OracleFound int
+	// Missed are the solutions only the oracle found, and Extra those only the solver found.
+	Missed, Extra []expression
+}
+
+// ok reports whether the solver and the oracle found the same solutions.
+func (r crosscheckReport) ok() bool {
+	return len(r.Missed) == 0 && len(r.Extra) == 0
+}
+
+// crosscheck compares solns, found for target by some solver, with those oracleSolve finds.
+func crosscheck(solns, oracle []expression) crosscheckReport {
+	r := crosscheckReport{Found: len(solns), OracleFound: len(oracle)}
+	found := make(map[string]bool, len(solns))
+	for _, e := range solns {
+		found[formatSExpr(e, opStrings)] = true
+	}
+	fromOracle := make(map[string]bool, len(oracle))
+	for _, e := range oracle {
+		key := formatSExpr(e, opStrings)
+		fromOracle[key] = true
+		if !found[key] {
+			r.Missed = append(r.Missed, e)
+		}
+	}
+	for _, e := range solns {
+		if !fromOracle[formatSExpr(e, opStrings)] {
+			r.Extra = append(r.Extra, e)
+		}
+	}
+	return r
+}
+
+// write writes the report, with each of the solutions only one side found.
+func (r crosscheckReport) write(w io.Writer, p printer) error {
+	solvable := func(n int) string {
+		if n == 0 {
+			return "unsolvable"
+		}
+		return "solvable"
+	}
+	verdict := "agree"
+	if (r.Found == 0) != (r.OracleFound == 0) {
+		verdict = "DISAGREE"
+	}
+	if _, err := fmt.Fprintf(w, "Solver found %d solutions (%s), brute force found %d (%s): verdicts %s\n",
+		r.Found, solvable(r.Found), r.OracleFound, solvable(r.OracleFound), verdict); err != nil {
+		return err
+	}
+	for _, list := range []struct {
+		label string
+		solns []expression
+	}{{"Missed by the solver", r.Missed}, {"Not found by brute force", r.Extra}} {
+		if len(list.solns) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s: %d\n", list.label, len(list.solns)); err != nil {
+			return err
+		}
+		strs := make([]string, len(list.solns))
+		for i, e := range list.solns {
+			strs[i] = p.format(e)
+		}
+		sort.Strings(strs)
+		for _, s := range strs {
+			if _, err := fmt.Fprintf(w, "  %s\n", s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_solver_oracleSolve(t *testing.T) {
+	tests := map[string]struct {
+		digits []int
+		target int
+	}{
+		"four digits":     {digits: []int{1, 3, 4, 6}, target: 24},
+		"split forms":     {digits: []int{2, 3, 4, 5}, target: 45},
+		"repeated digits": {digits: []int{2, 2, 3, 3}, target: 12},
+		"five digits":     {digits: []int{2, 3, 7, 25, 50}, target: 371},
+		"no solution":     {digits: []int{2, 3}, target: 100},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			oracle, stopped := solver{}.oracleSolve(tt.target, tt.digits)
+			if stopped {
+				t.Fatalf("oracleSolve() stopped unexpectedly")
+			}
+			// The splits engine finds every solution, so should agree exactly.
+			splits, _ := solver{}.solveSplits(0, tt.target, tt.digits)
+			if r := crosscheck(splits, oracle); !r.ok() {
+				t.Errorf("crosscheck(splits) = %d missed, %d extra, want none: %v %v", len(r.Missed), len(r.Extra), r.Missed, r.Extra)
+			}
+			// The search engine misses some, but never finds any the oracle doesn't.
+			if r := crosscheck(solve(tt.target, tt.digits), oracle); len(r.Extra) > 0 {
+				t.Errorf("crosscheck(solve) = %v extra, want none", r.Extra)
+			}
+		})
+	}
+}
+
+func Test_crosscheckReport_write(t *testing.T) {
+	split := makeMultiply(makeAdd(makeConstant(4), makeConstant(5)), makeAdd(makeConstant(3), makeConstant(2)))
+	one := makeAdd(makeMultiply(makeConstant(5), makeConstant(4)), makeConstant(25))
+	tests := map[string]struct {
+		solns, oracle []expression
+		want          string
+	}{
+		"agree": {
+			solns:  []expression{one},
+			oracle: []expression{one},
+			want:   "Solver found 1 solutions (solvable), brute force found 1 (solvable): verdicts agree\n",
+		},
+		"missed": {
+			solns:  []expression{one},
+			oracle: []expression{split, one},
+			want: "Solver found 1 solutions (solvable), brute force found 2 (solvable): verdicts agree\n" +
+				"Missed by the solver: 1\n  (4 + 5)*(3 + 2)\n",
+		},
+		"disagree": {
+			oracle: []expression{split},
+			want: "Solver found 0 solutions (unsolvable), brute force found 1 (solvable): verdicts DISAGREE\n" +
+				"Missed by the solver: 1\n  (4 + 5)*(3 + 2)\n",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			var b strings.Builder
+			if err := crosscheck(tt.solns, tt.oracle).write(&b, newPrinter(notationInfix, false)); err != nil {
+				t.Fatalf("write() failed unexpectedly: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, b.String()); diff != "" {
+				t.Errorf("write() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// solutionOrder controls the order solutions are listed in.
+type solutionOrder int
+
+const (
+	// orderFound keeps the order the search found solutions in.
+	orderFound solutionOrder = iota
+	// orderLength puts the shortest written solutions first.
+	orderLength
+	// orderOps puts solutions needing the fewest operations first.
+	orderOps
+	// orderIntermediate puts solutions with the smallest intermediate results first, which are easiest to work out by hand.
+	orderIntermediate
+	// orderLex sorts solutions alphabetically by their written form.
+	orderLex
+	// orderEasiest puts the solutions with the lowest difficulty score first.
+	orderEasiest
+	// orderCleverest puts the solutions with the highest cleverness score first.
+	orderCleverest
+	// orderHuman puts the solutions passing through the roundest numbers first.
+	orderHuman
+)
+
+var solutionOrderNames = map[string]solutionOrder{
+	"found":        orderFound,
+	"length":       orderLength,
+	"ops":          orderOps,
+	"intermediate": orderIntermediate,
+	"lex":          orderLex,
+	"easiest":      orderEasiest,
+	"cleverest":    orderCleverest,
+	"human":        orderHuman,
+}
+
+func parseSolutionOrder(s string) (solutionOrder, error) {
+	o, ok := solutionOrderNames[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown order %q", s)
+	}
+	return o, nil
+}
+
+// maxIntermediate returns the largest value computed while evaluating e step by step.
+func (e expression) maxIntermediate() int {
+	largest := 0
+	for _, s := range e.steps() {
+		if abs(s.Result) > largest {
+			largest = abs(s.Result)
+		}
+	}
+	return largest
+}
+
+// sortSolutions returns solns in the given order. Ties keep the order they were found in.
+func sortSolutions(solns []expression, order solutionOrder, p printer) []expression {
+	if order == orderFound {
+		return solns
+	}
+
+	keys := make([]struct {
+		text string
+		n    int
+	}, len(solns))
+	for i, s := range solns {
+		switch order {
+		case orderLength:
+			keys[i].n = len(s.String())
+		case orderOps:
+			keys[i].n = len(s.steps())
+		case orderIntermediate:
+			keys[i].n = s.maxIntermediate()
+		case orderLex:
+			keys[i].text = p.format(s)
+		case orderEasiest:
+			keys[i].n = s.score().Difficulty
+		case orderCleverest:
+			keys[i].n = -s.score().Cleverness
+		case orderHuman:
+			keys[i].n = -s.score().Roundness
+		}
+	}
+
+	idx := make([]int, len(solns))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		a, b := keys[idx[i]], keys[idx[j]]
+		if a.n != b.n {
+			return a.n < b.n
+		}
+		return a.text < b.text
+	})
+
+	sorted := make([]expression, len(solns))
+	for i, j := range idx {
+		sorted[i] = solns[j]
+	}
+	return sorted
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_sortSolutions(t *testing.T) {
+	solns := []expression{
+		makeDivide(makeMultiply(makeConstant(25), makeConstant(4)), makeConstant(5)),
+		makeAdd(makeConstant(15), makeConstant(5)),
+		makeMultiply(makeConstant(4), makeConstant(5)),
+		makeAdd(makeAdd(makeConstant(9), makeConstant(6)), makeConstant(5)).fuse(),
+	}
+	p := newPrinter(notationInfix, false)
+
+	tests := map[solutionOrder][]string{
+		orderFound:        {"25*4/5", "15 + 5", "4*5", "9 + 6 + 5"},
+		orderLength:       {"4*5", "15 + 5", "9 + 6 + 5", "25*4/5"},
+		orderOps:          {"15 + 5", "4*5", "25*4/5", "9 + 6 + 5"},
+		orderIntermediate: {"15 + 5", "4*5", "9 + 6 + 5", "25*4/5"},
+		orderLex:          {"15 + 5", "25*4/5", "4*5", "9 + 6 + 5"},
+		orderEasiest:      {"15 + 5", "4*5", "9 + 6 + 5", "25*4/5"},
+		orderCleverest:    {"25*4/5", "4*5", "15 + 5", "9 + 6 + 5"},
+		orderHuman:        {"25*4/5", "15 + 5", "4*5", "9 + 6 + 5"},
+	}
+	for order, want := range tests {
+		var got []string
+		for _, s := range sortSolutions(solns, order, p) {
+			got = append(got, p.format(s))
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("sortSolutions(%d) mismatch (-want +got):\n%s", order, diff)
+		}
+	}
+}
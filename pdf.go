@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page size and margins in PDF points.
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 56
+)
+
+// pdfText is a line of text placed on a page, with (X, Y) measured from the bottom-left corner.
+type pdfText struct {
+	X, Y float64
+	Size float64
+	Bold bool
+	Text string
+}
+
+// pdfDocument is a minimal PDF writer which only supports text in the standard Helvetica fonts.
+// Those fonts are built into every PDF reader, so nothing needs embedding.
+type pdfDocument struct {
+	pages [][]pdfText
+}
+
+func (d *pdfDocument) addPage() {
+	d.pages = append(d.pages, nil)
+}
+
+func (d *pdfDocument) addText(t pdfText) {
+	if len(d.pages) == 0 {
+		d.addPage()
+	}
+	d.pages[len(d.pages)-1] = append(d.pages[len(d.pages)-1], t)
+}
+
+// pdfString encodes s as a PDF literal string in WinAnsiEncoding.
+func pdfString(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '−':
+			// WinAnsiEncoding has no minus sign.
+			b.WriteByte('-')
+		case r < 0x80:
+			b.WriteRune(r)
+		case r <= 0xff:
+			// Latin-1 (including × and ÷) matches WinAnsiEncoding above 0xa0.
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+func (d *pdfDocument) write(w io.Writer) error {
+	// Objects 1-4 are the catalog, page tree and fonts; each page then takes two objects
+	// (the page and its content stream).
+	var objects []string
+	objects = append(objects, "<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	objects = append(objects, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		var content strings.Builder
+		for _, t := range page {
+			font := "F1"
+			if t.Bold {
+				font = "F2"
+			}
+			fmt.Fprintf(&content, "BT /%s %g Tf %g %g Td %s Tj ET\n", font, t.Size, t.X, t.Y, pdfString(t.Text))
+		}
+		objects = append(objects, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, 6+2*i))
+		objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n", len(objects)+1)
+	b.WriteString("0000000000 65535 f \n")
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// pdfCursor lays out lines of text top-down, starting new pages as needed.
+type pdfCursor struct {
+	doc *pdfDocument
+	y   float64
+}
+
+func newPDFCursor(doc *pdfDocument) *pdfCursor {
+	c := &pdfCursor{doc: doc}
+	c.newPage()
+	return c
+}
+
+func (c *pdfCursor) newPage() {
+	c.doc.addPage()
+	c.y = pdfPageHeight - pdfMargin
+}
+
+// line writes text indented by indent points and advances by the given leading.
+func (c *pdfCursor) line(text string, indent, size, leading float64, bold bool) {
+	if c.y-leading < pdfMargin {
+		c.newPage()
+	}
+	c.y -= leading
+	c.doc.addText(pdfText{X: pdfMargin + indent, Y: c.y, Size: size, Bold: bold, Text: text})
+}
+
+// ensure starts a new page unless at least height points remain on the current one.
+func (c *pdfCursor) ensure(height float64) {
+	if c.y-height < pdfMargin {
+		c.newPage()
+	}
+}
+
+// workingSpace is the blank space left below each puzzle for working, in points.
+const workingSpace = 80
+
+// writeWorksheetPDF typesets items as a printable worksheet, optionally followed by an
+// answer key which works through each solution step by step.
+func writeWorksheetPDF(w io.Writer, items []solvedPuzzle, answerKey bool, p printer) error {
+	doc := &pdfDocument{}
+	c := newPDFCursor(doc)
+	c.line("Digits worksheet", 0, 20, 20, true)
+	c.y -= 10
+	for i, item := range items {
+		c.ensure(24 + workingSpace)
+		c.line(fmt.Sprintf("%d. Make %d", i+1, item.Puzzle.Target), 0, 14, 24, true)
+		c.line(fmt.Sprintf("using %s", strings.Join(strings.Split(formatDigits(item.Puzzle.Digits), ","), ", ")), 18, 12, 16, false)
+		c.y -= workingSpace
+	}
+
+	if answerKey {
+		c.newPage()
+		c.line("Answers", 0, 20, 20, true)
+		c.y -= 10
+		for i, item := range items {
+			steps := item.Solution.steps()
+			c.ensure(float64(24 + 16*len(steps)))
+			c.line(fmt.Sprintf("%d. %d = %s", i+1, item.Puzzle.Target, p.format(item.Solution)), 0, 12, 24, true)
+			for _, s := range steps {
+				c.line(s.format(p.symbols), 18, 11, 16, false)
+			}
+		}
+	}
+
+	return doc.write(w)
+}
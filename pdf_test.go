@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func Test_pdfString(t *testing.T) {
+	tests := map[string]string{
+		"plain":   "(9 + 7)",
+		"escaped": `\(a\) \\`,
+		"latin1":  "9 × 7",
+		"minus":   "9 − 7",
+		"unknown": "≠",
+	}
+	want := map[string]string{
+		"plain":   `(\(9 + 7\))`,
+		"escaped": `(\\\(a\\\) \\\\)`,
+		"latin1":  `(9 \327 7)`,
+		"minus":   `(9 - 7)`,
+		"unknown": `(?)`,
+	}
+	for tn, s := range tests {
+		t.Run(tn, func(t *testing.T) {
+			if got := pdfString(s); got != want[tn] {
+				t.Errorf("pdfString(%q) = %q, want %q", s, got, want[tn])
+			}
+		})
+	}
+}
+
+func Test_writeWorksheetPDF(t *testing.T) {
+	soln := makeAdd(makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25)), makeConstant(5)).fuse()
+	var items []solvedPuzzle
+	for i := 0; i < 12; i++ {
+		items = append(items, solvedPuzzle{Puzzle: puzzle{Digits: []int{5, 7, 9, 10, 15, 25}, Target: 93}, Solution: soln})
+	}
+
+	var buf bytes.Buffer
+	if err := writeWorksheetPDF(&buf, items, true, newPrinter(notationInfix, true)); err != nil {
+		t.Fatalf("writeWorksheetPDF() failed unexpectedly: %v", err)
+	}
+	got := buf.String()
+
+	if !strings.HasPrefix(got, "%PDF-1.4\n") {
+		t.Errorf("writeWorksheetPDF() output doesn't start with a PDF header")
+	}
+	for _, want := range []string{
+		"(12. Make 93)",
+		"(using 5, 7, 9, 10, 15, 25)",
+		"(Answers)",
+		`(1. 93 = 9\3277 + 25 + 5)`,
+		`(9 \327 7 = 63)`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("writeWorksheetPDF() output missing %q", want)
+		}
+	}
+
+	// The worksheet needs more than one page and the answers start on a new one.
+	if m := regexp.MustCompile(`/Count (\d+)`).FindStringSubmatch(got); m == nil || m[1] == "1" {
+		t.Errorf("writeWorksheetPDF() page count = %v, want more than 1", m)
+	}
+
+	// startxref must point at the cross-reference table.
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(got)
+	if m == nil {
+		t.Fatalf("writeWorksheetPDF() output has no startxref")
+	}
+	off, _ := strconv.Atoi(m[1])
+	if !strings.HasPrefix(got[off:], "xref\n") {
+		t.Errorf("startxref %d doesn't point at the xref table", off)
+	}
+}
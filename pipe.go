@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// solveCache caches the work of solving for many targets with the same digits.
+type solveCache struct {
+	digits []int
+	// reachable holds every value which can be made from digits, so unreachable targets are answered without searching.
+	reachable []int
+	isReached map[int]bool
+	solutions map[int][]expression
+	// search shares the sub-searches of every target.
+	search *searchCache
+	// disk, if set, keeps the reachable values and solutions in entry between runs.
+	disk  *diskCache
+	entry *cacheEntry
+}
+
+// newSolveCache returns a cache for digits, which also keeps its work in disk if it's set.
+func newSolveCache(digits []int, disk *diskCache) *solveCache {
+	c := &solveCache{
+		digits:    digits,
+		solutions: make(map[int][]expression),
+		search:    newSearchCache(digits),
+	}
+	if disk != nil && len(digits) > 0 {
+		entry, err := disk.load(digits, cacheOptions("search", solutionFilter{}, 0))
+		if err != nil {
+			slog.Warn("Not using the solution cache", "error", err)
+		} else {
+			c.disk, c.entry = disk, entry
+		}
+	}
+	if c.entry != nil && c.entry.Reachable != nil {
+		c.reachable = c.entry.Reachable
+	} else {
+		c.reachable = reachableValues(digits)
+		if c.entry != nil {
+			c.entry.Reachable = c.reachable
+			c.disk.changed(c.entry)
+		}
+	}
+	c.isReached = make(map[int]bool, len(c.reachable))
+	for _, v := range c.reachable {
+		c.isReached[v] = true
+	}
+	return c
+}
+
+func (c *solveCache) solve(target int) []expression {
+	if !c.isReached[target] {
+		return nil
+	}
+	solns, ok := c.solutions[target]
+	if ok {
+		return solns
+	}
+	if c.entry != nil {
+		cached, ok, err := c.entry.solutions(target)
+		if err != nil {
+			slog.Warn("Ignoring the solution cache's bad entry", "error", err)
+		}
+		if ok && err == nil {
+			c.solutions[target] = cached
+			return cached
+		}
+	}
+	solns = solver{cache: c.search}.solve(target, c.digits)
+	c.solutions[target] = solns
+	if c.entry != nil {
+		c.entry.setSolutions(target, solns)
+		c.disk.changed(c.entry)
+	}
+	return solns
+}
+
+// answerTargets reads a target per line from in, and writes a result for each to out as soon as it's answered.
+// Blank lines are skipped, and lines which aren't targets get a result with an error.
+func answerTargets(in io.Reader, out io.Writer, c *solveCache, p printer, asJSON bool) error {
+	w := bufio.NewWriter(out)
+	sc := bufio.NewScanner(in)
+	row := 0
+	for sc.Scan() {
+		row++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+
+		var r batchResult
+		target, err := strconv.Atoi(line)
+		switch {
+		case err != nil:
+			r = batchResult{Row: row, Error: fmt.Sprintf("invalid target %q", line)}
+		case target <= 0:
+			r = batchResult{Row: row, Error: fmt.Sprintf("target must be positive, got %d", target)}
+		default:
+			r = makeBatchResult(row, puzzle{Digits: c.digits, Target: target}, c.solve(target), p)
+		}
+		if err := writeBatchResult(w, r, asJSON); err != nil {
+			return err
+		}
+		// Flush each answer, as the program on the other end of the pipe is probably waiting for it.
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
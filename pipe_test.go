@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_answerTargets(t *testing.T) {
+	c := newSolveCache([]int{5, 7, 9, 25}, nil)
+	in := strings.NewReader("93\n\n1000\nabc\n93\n")
+	var out bytes.Buffer
+	if err := answerTargets(in, &out, c, newPrinter(notationInfix, false), false); err != nil {
+		t.Fatalf("answerTargets() failed unexpectedly: %v", err)
+	}
+
+	want := []string{
+		"make 93 from 5,7,9,25: 2 solutions, shortest 9*7 + 25 + 5",
+		"make 1000 from 5,7,9,25: no solution",
+		`row 4: error: invalid target "abc"`,
+		"make 93 from 5,7,9,25: 2 solutions, shortest 9*7 + 25 + 5",
+	}
+	if diff := cmp.Diff(want, strings.Split(strings.TrimSpace(out.String()), "\n")); diff != "" {
+		t.Errorf("answerTargets() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{93}, mapKeys(c.solutions)); diff != "" {
+		t.Errorf("cached solutions mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func mapKeys(m map[int][]expression) []int {
+	var out []int
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
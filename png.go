@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// pngStyle selects what is drawn by renderPNG.
+type pngStyle int
+
+const (
+	// pngText draws the solution as a single line of text.
+	pngText pngStyle = iota
+	// pngTree draws the solution's expression tree.
+	pngTree
+)
+
+var pngStyleNames = map[string]pngStyle{
+	"text": pngText,
+	"tree": pngTree,
+}
+
+func parsePNGStyle(s string) (pngStyle, error) {
+	st, ok := pngStyleNames[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown style %q", s)
+	}
+	return st, nil
+}
+
+// pngScale is the integer factor images are enlarged by.
+// The built-in bitmap font is small, so images are drawn at 1x and scaled up with nearest-neighbour sampling to keep them crisp.
+const pngScale = 2
+
+// renderPNG draws e (a solution for target) in the given style and returns the encoded PNG.
+func renderPNG(e expression, target int, p printer, style pngStyle, t theme) ([]byte, error) {
+	var img *image.RGBA
+	switch style {
+	case pngTree:
+		img = drawTree(e, p.symbols, t)
+	default:
+		img = drawText(fmt.Sprintf("%d = %s", target, p.format(e)), t)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, scaleImage(img, pngScale)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func newCanvas(width, height int, t theme) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(t.Background), image.Point{}, draw.Src)
+	return img
+}
+
+func drawText(s string, t theme) *image.RGBA {
+	const padding = 12
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, s).Ceil()
+	img := newCanvas(width+2*padding, face.Height+2*padding, t)
+	drawString(img, s, padding, padding+face.Ascent, t.Foreground)
+	return img
+}
+
+// drawString draws s with its left edge at x and its baseline at y.
+func drawString(img *image.RGBA, s string, x, y int, c color.Color) {
+	// The bitmap font covers Latin-1, which has × and ÷ but no minus sign.
+	s = strings.ReplaceAll(s, "−", "-")
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(s)
+}
+
+// drawCenteredString draws s centred on (x, y).
+func drawCenteredString(img *image.RGBA, s string, x, y int, c color.Color) {
+	s = strings.ReplaceAll(s, "−", "-")
+	face := basicfont.Face7x13
+	w := font.MeasureString(face, s).Ceil()
+	drawString(img, s, x-w/2, y+(face.Ascent-face.Descent)/2, c)
+}
+
+func drawTree(e expression, sym symbols, t theme) *image.RGBA {
+	root, width, height := layoutTree(e, sym)
+	img := newCanvas(int(width), int(height), t)
+
+	var drawEdges func(n *treeNode)
+	drawEdges = func(n *treeNode) {
+		for _, c := range n.Children {
+			drawLine(img, n.X, n.Y, c.X, c.Y, t.Foreground)
+			drawEdges(c)
+		}
+	}
+	drawEdges(root)
+
+	var drawNodes func(n *treeNode)
+	drawNodes = func(n *treeNode) {
+		fill := t.LeafFill
+		if len(n.Children) > 0 {
+			fill = t.OpFill
+		}
+		drawDisc(img, n.X, n.Y, treeNodeRadius, t.Foreground)
+		drawDisc(img, n.X, n.Y, treeNodeRadius-2, fill)
+		drawCenteredString(img, n.Label, int(n.X), int(n.Y), t.Foreground)
+		if n.Annotation != "" {
+			drawString(img, n.Annotation, int(n.X)+treeNodeRadius+2, int(n.Y)-treeNodeRadius, t.Value)
+		}
+		for _, c := range n.Children {
+			drawNodes(c)
+		}
+	}
+	drawNodes(root)
+	return img
+}
+
+func drawDisc(img *image.RGBA, cx, cy, r float64, c color.RGBA) {
+	for y := int(cy - r); y <= int(cy+r); y++ {
+		for x := int(cx - r); x <= int(cx+r); x++ {
+			dx, dy := float64(x)-cx, float64(y)-cy
+			if dx*dx+dy*dy <= r*r {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
+
+// drawLine draws a 2px wide line by stamping small discs along its length.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA) {
+	steps := int(math.Ceil(math.Hypot(x1-x0, y1-y0)))
+	if steps == 0 {
+		steps = 1
+	}
+	for i := 0; i <= steps; i++ {
+		f := float64(i) / float64(steps)
+		drawDisc(img, x0+f*(x1-x0), y0+f*(y1-y0), 1, c)
+	}
+}
+
+func scaleImage(src *image.RGBA, factor int) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx()*factor, b.Dy()*factor))
+	for y := 0; y < b.Dy()*factor; y++ {
+		for x := 0; x < b.Dx()*factor; x++ {
+			dst.SetRGBA(x, y, src.RGBAAt(b.Min.X+x/factor, b.Min.Y+y/factor))
+		}
+	}
+	return dst
+}
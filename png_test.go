@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func Test_renderPNG(t *testing.T) {
+	expr := makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25))
+
+	tests := map[string]struct {
+		style      pngStyle
+		theme      string
+		wantWidth  int
+		wantHeight int
+	}{
+		"tree light": {
+			style:      pngTree,
+			theme:      "light",
+			wantWidth:  400,
+			wantHeight: 480,
+		},
+		"tree dark": {
+			style:      pngTree,
+			theme:      "dark",
+			wantWidth:  400,
+			wantHeight: 480,
+		},
+		"text": {
+			style:      pngText,
+			theme:      "light",
+			wantWidth:  2 * (len("88 = 9*7 + 25")*7 + 24),
+			wantHeight: 2 * (13 + 24),
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			th, err := parseTheme(tt.theme)
+			if err != nil {
+				t.Fatalf("parseTheme(%q) failed unexpectedly: %v", tt.theme, err)
+			}
+			data, err := renderPNG(expr, 88, newPrinter(notationInfix, false), tt.style, th)
+			if err != nil {
+				t.Fatalf("renderPNG() failed unexpectedly: %v", err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("png.Decode() failed unexpectedly: %v", err)
+			}
+			if got := img.Bounds(); got.Dx() != tt.wantWidth || got.Dy() != tt.wantHeight {
+				t.Errorf("renderPNG() size = %dx%d, want %dx%d", got.Dx(), got.Dy(), tt.wantWidth, tt.wantHeight)
+			}
+			if got := img.At(0, 0); got != th.Background {
+				t.Errorf("renderPNG() background = %v, want %v", got, th.Background)
+			}
+		})
+	}
+}
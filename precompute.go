@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"slices"
+)
+
+// countdownSelections returns every different set of count numbers which can be drawn from the Countdown
+// pools with large of them from the large numbers, or any number of them if large is -1.
+// Each selection is in ascending order, and copies of a small number only count once.
+func countdownSelections(count, large int) [][]int {
+	var out [][]int
+	for l := 0; l <= min(count, len(largeNumbers)); l++ {
+		if large >= 0 && l != large {
+			continue
+		}
+		for _, big := range combinations(largeNumbers, l) {
+			for _, small := range combinations(smallNumbers, count-l) {
+				sel := append(slices.Clone(small), big...)
+				slices.Sort(sel)
+				out = append(out, sel)
+			}
+		}
+	}
+	return out
+}
+
+// combinations returns every way to choose k of numbers, which must be in ascending order.
+// Choices which only swap equal numbers are only returned once.
+func combinations(numbers []int, k int) [][]int {
+	if k == 0 {
+		return [][]int{nil}
+	}
+	var out [][]int
+	for i := 0; i+k <= len(numbers); i++ {
+		if i > 0 && numbers[i] == numbers[i-1] {
+			continue
+		}
+		for _, rest := range combinations(numbers[i+1:], k-1) {
+			out = append(out, append([]int{numbers[i]}, rest...))
+		}
+	}
+	return out
+}
+
+// precomputeTable solves every target from..to for digits on parallelism workers, and saves the solutions
+// in cache, where solve, pipe and repl look them up. Targets already in the cache aren't solved again,
+// so an interrupted run carries on where it left off. done is called as each target is finished,
+// and it reports false if ctx was done before every target was.
+func precomputeTable(ctx context.Context, cache *diskCache, digits []int, from, to, parallelism int, done func()) (bool, error) {
+	e, err := cache.load(digits, cacheOptions("search", solutionFilter{}, 0))
+	if err != nil {
+		return false, err
+	}
+	if e.Reachable == nil {
+		e.Reachable = reachableValues(digits)
+	}
+	first := from
+	for first <= to {
+		if _, ok := e.Solutions[first]; !ok {
+			break
+		}
+		done()
+		first++
+	}
+	if first > to {
+		return true, nil
+	}
+
+	s := solver{ctx: ctx, cache: newSearchCache(digits)}
+	solveTargets(first, to, parallelism, func(target int) (targetResult, bool) {
+		solns := s.solve(target, digits)
+		return targetResult{Solutions: solns}, ctx.Err() == nil
+	}, func(r targetResult) {
+		e.setSolutions(r.Target, r.Solutions)
+		done()
+	})
+	// What was finished is saved even if the run was interrupted.
+	return ctx.Err() == nil, cache.save(e)
+}
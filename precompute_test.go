@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_countdownSelections(t *testing.T) {
+	tests := map[string]struct {
+		count, large int
+		wantLen      int
+		wantFirst    []int
+	}{
+		"every selection": {count: 6, large: -1, wantLen: 13243, wantFirst: []int{1, 1, 2, 2, 3, 3}},
+		"two small":       {count: 2, large: 0, wantLen: 55, wantFirst: []int{1, 1}},
+		"two large":       {count: 2, large: 2, wantLen: 6, wantFirst: []int{25, 50}},
+		"all four large":  {count: 6, large: 4, wantLen: 55, wantFirst: []int{1, 1, 25, 50, 75, 100}},
+		"too many large":  {count: 2, large: 3, wantLen: 0},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := countdownSelections(tt.count, tt.large)
+			if len(got) != tt.wantLen {
+				t.Fatalf("countdownSelections() returned %d selections, want %d", len(got), tt.wantLen)
+			}
+			if len(got) == 0 {
+				return
+			}
+			if diff := cmp.Diff(tt.wantFirst, got[0]); diff != "" {
+				t.Errorf("countdownSelections() first selection mismatch (-want +got):\n%s", diff)
+			}
+			seen := make(map[string]bool)
+			for _, sel := range got {
+				if notes := poolRepeats(sel); len(notes) > 0 {
+					t.Errorf("countdownSelections() returned %v, which breaks the rules: %v", sel, notes)
+				}
+				if seen[fmt.Sprint(sel)] {
+					t.Errorf("countdownSelections() returned %v twice", sel)
+				}
+				seen[fmt.Sprint(sel)] = true
+			}
+		})
+	}
+}
+
+func Test_precomputeTable(t *testing.T) {
+	c, err := openDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("openDiskCache() failed unexpectedly: %v", err)
+	}
+	digits := []int{7, 2, 5, 3}
+	done := 0
+	complete, err := precomputeTable(context.Background(), c, digits, 1, 40, 2, func() { done++ })
+	if err != nil || !complete {
+		t.Fatalf("precomputeTable() = %t, %v; want complete", complete, err)
+	}
+	if done != 40 {
+		t.Errorf("precomputeTable() finished %d targets, want 40", done)
+	}
+	options := cacheOptions("search", solutionFilter{}, 0)
+	for target := 1; target <= 40; target++ {
+		got, ok := c.lookup(digits, options, target)
+		if !ok {
+			t.Fatalf("lookup(%d) found nothing after precomputing", target)
+		}
+		if diff := cmp.Diff(fmt.Sprint(solve(target, digits)), fmt.Sprint(got)); diff != "" {
+			t.Errorf("lookup(%d) mismatch (-want +got):\n%s", target, diff)
+		}
+	}
+
+	// Running again finds everything already done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	done = 0
+	if complete, err := precomputeTable(ctx, c, digits, 1, 40, 2, func() { done++ }); err != nil || !complete || done != 40 {
+		t.Errorf("precomputeTable() again = %t, %v with %d targets done; want complete with 40", complete, err, done)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// profileFlags are the --cpuprofile, --memprofile and --trace flags, registered on every command by newFlagSet.
+var profileFlags struct {
+	cpu, mem, trace string
+}
+
+// profiling holds the files being written by the profile flags, between startProfiling and stopProfiling.
+var profiling struct {
+	cpu, trace *os.File
+}
+
+func addProfileFlags(fs *flag.FlagSet) {
+	fs.StringVar(&profileFlags.cpu, "cpuprofile", "", "If set, write a CPU profile of the run to this path, for go tool pprof")
+	fs.StringVar(&profileFlags.mem, "memprofile", "", "If set, write a heap profile to this path at the end of the run, for go tool pprof")
+	fs.StringVar(&profileFlags.trace, "trace", "", "If set, write an execution trace of the run to this path, for go tool trace")
+}
+
+// startProfiling starts the CPU profile and execution trace asked for by the profile flags.
+func startProfiling() {
+	if profileFlags.cpu != "" {
+		f, err := os.Create(profileFlags.cpu)
+		if err != nil {
+			fatalf("Failed to create CPU profile: %v", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			fatalf("Failed to start CPU profile: %v", err)
+		}
+		profiling.cpu = f
+	}
+	if profileFlags.trace != "" {
+		f, err := os.Create(profileFlags.trace)
+		if err != nil {
+			fatalf("Failed to create trace: %v", err)
+		}
+		if err := trace.Start(f); err != nil {
+			fatalf("Failed to start trace: %v", err)
+		}
+		profiling.trace = f
+	}
+}
+
+// stopProfiling finishes the files asked for by the profile flags. It's called before exiting,
+// however the command ends, and does nothing if profiling wasn't started.
+// Failures are only warned about, so they don't change the exit status.
+func stopProfiling() {
+	if profiling.cpu != nil {
+		pprof.StopCPUProfile()
+		if err := profiling.cpu.Close(); err != nil {
+			slog.Warn("Failed to write CPU profile", "error", err)
+		}
+		profiling.cpu = nil
+	}
+	if profiling.trace != nil {
+		trace.Stop()
+		if err := profiling.trace.Close(); err != nil {
+			slog.Warn("Failed to write trace", "error", err)
+		}
+		profiling.trace = nil
+	}
+	if profileFlags.mem != "" {
+		if err := writeHeapProfile(profileFlags.mem); err != nil {
+			slog.Warn("Failed to write heap profile", "error", err)
+		}
+		profileFlags.mem = ""
+	}
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	// Collect garbage first, so the profile shows what's still in use.
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// exit stops profiling, then exits with code.
+func exit(code int) {
+	stopProfiling()
+	os.Exit(code)
+}
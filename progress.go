@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// progressWidth is the length of the bar drawn by formatProgress.
+const progressWidth = 30
+
+// progress periodically rewrites a status line on w (usually stderr) while a long run is going.
+type progress struct {
+	w     io.Writer
+	total int
+	start time.Time
+
+	// done counts the targets finished, and nodes the sub-problems searched.
+	done  atomic.Int64
+	nodes atomic.Int64
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// startProgress starts reporting progress every interval, for a run of total targets.
+func startProgress(w io.Writer, total int, interval time.Duration) *progress {
+	p := &progress{w: w, total: total, start: time.Now(), stop: make(chan struct{})}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				fmt.Fprintf(p.w, "\r%s", p.line())
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+func (p *progress) line() string {
+	return formatProgress(int(p.done.Load()), p.total, p.nodes.Load(), time.Since(p.start))
+}
+
+// finish stops reporting and writes the final status.
+func (p *progress) finish() {
+	close(p.stop)
+	p.wg.Wait()
+	fmt.Fprintf(p.w, "\r%s\n", p.line())
+}
+
+// formatProgress writes a status line like "[=======>      ] 50/200 targets, 1.2M nodes, 3.4s".
+func formatProgress(done, total int, nodes int64, elapsed time.Duration) string {
+	var b strings.Builder
+	if total > 0 {
+		filled := done * progressWidth / total
+		bar := strings.Repeat("=", filled)
+		if filled < progressWidth {
+			bar += ">" + strings.Repeat(" ", progressWidth-filled-1)
+		}
+		fmt.Fprintf(&b, "[%s] %d/%d targets, ", bar, done, total)
+	}
+	fmt.Fprintf(&b, "%s nodes, %v", formatCount(nodes), elapsed.Round(100*time.Millisecond))
+	return b.String()
+}
+
+// formatCount writes n with a metric suffix, e.g. 1.2M.
+func formatCount(n int64) string {
+	switch {
+	case n >= 1e9:
+		return fmt.Sprintf("%.1fG", float64(n)/1e9)
+	case n >= 1e6:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1e3:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	}
+	return fmt.Sprintf("%d", n)
+}
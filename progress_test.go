@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_formatProgress(t *testing.T) {
+	tests := map[string]struct {
+		done, total int
+		nodes       int64
+		elapsed     time.Duration
+		want        string
+	}{
+		"start": {
+			total:   200,
+			elapsed: 20 * time.Millisecond,
+			want:    "[>                             ] 0/200 targets, 0 nodes, 0s",
+		},
+		"half": {
+			done:    100,
+			total:   200,
+			nodes:   1234567,
+			elapsed: 3420 * time.Millisecond,
+			want:    "[===============>              ] 100/200 targets, 1.2M nodes, 3.4s",
+		},
+		"done": {
+			done:    200,
+			total:   200,
+			nodes:   5400,
+			elapsed: time.Minute,
+			want:    "[==============================] 200/200 targets, 5.4k nodes, 1m0s",
+		},
+		"single solve": {
+			nodes:   999,
+			elapsed: time.Second,
+			want:    "999 nodes, 1s",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := formatProgress(tt.done, tt.total, tt.nodes, tt.elapsed)
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("formatProgress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+	"time"
+)
+
+// quickMaxStates is how many states engineQuick expands before giving up on finding a solution.
+const quickMaxStates = 5000
+
+// quickOps are the operations engineQuick combines two numbers with. Subtraction and division take the smaller
+// number from, or divide it into, the larger.
+var quickOps = [...]operation{opAdd, opMultiply, opSubtract, opDivide}
+
+// quickState is a point in the game reached by engineQuick: the numbers left after some steps.
+// Only their values are kept, as most states are never part of a solution; the expressions are
+// rebuilt from the steps which led to a solution.
+type quickState struct {
+	// values holds the numbers in ascending order, followed by zeroes. Values are always positive,
+	// so it also identifies the state.
+	values [maxDigitCount]int
+	n      int
+	steps  int
+	// cost estimates how far the numbers are from making the target. seq breaks ties in the order the states were reached.
+	cost, seq int
+	// parent is the state this one was reached from, by combining its values at a and b, a's being no bigger, with op.
+	parent *quickState
+	a, b   int
+	op     operation
+}
+
+// quickQueue orders states by how promising they look, then by the fewest steps taken.
+type quickQueue []*quickState
+
+func (q quickQueue) Len() int { return len(q) }
+func (q quickQueue) Less(i, j int) bool {
+	if q[i].cost != q[j].cost {
+		return q[i].cost < q[j].cost
+	}
+	if q[i].steps != q[j].steps {
+		return q[i].steps < q[j].steps
+	}
+	return q[i].seq < q[j].seq
+}
+func (q quickQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *quickQueue) Push(x any)   { *q = append(*q, x.(*quickState)) }
+func (q *quickQueue) Pop() any {
+	old := *q
+	st := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return st
+}
+
+// quickCost estimates how far v is from making target: the distance between them, or if a small multiple
+// of v is nearer, the distance to that plus how big the number it still needs multiplying by is.
+func quickCost(target, v int) int {
+	cost := abs(target - v)
+	if v > 1 {
+		for k := max(target/v, 2); k <= target/v+1; k++ {
+			cost = min(cost, abs(target-k*v)+k-1)
+		}
+	}
+	return cost
+}
+
+// quickApply returns the value of op applied to a and b, where a is no bigger than b, and whether it's worth making.
+// Multiplying or dividing by 1 is left out, as it doesn't make anything new.
+func quickApply(op operation, a, b int) (int, bool) {
+	switch op {
+	case opAdd:
+		return a + b, true
+	case opMultiply:
+		return a * b, a > 1
+	case opSubtract:
+		return b - a, b > a
+	default:
+		return b / a, a > 1 && b%a == 0
+	}
+}
+
+// quickBuild returns the expression for op applied to a and b, like quickApply, in the same normalized form as solver's solutions.
+func quickBuild(op operation, a, b expression) expression {
+	switch op {
+	case opAdd:
+		return makeAdd(b, a).fuse().canonicalize()
+	case opMultiply:
+		return makeMultiply(b, a).fuse().canonicalize()
+	case opSubtract:
+		return makeAdd(b, makeNegate(a)).fuse().canonicalize()
+	default:
+		return makeDivide(b, a)
+	}
+}
+
+// next returns the state reached by combining st's values at a and b with op, making v.
+func (st *quickState) next(a, b int, op operation, v int) *quickState {
+	next := &quickState{steps: st.steps + 1, parent: st, a: a, b: b, op: op}
+	inserted := false
+	for i, x := range st.values[:st.n] {
+		if i == a || i == b {
+			continue
+		}
+		if !inserted && x >= v {
+			next.values[next.n] = v
+			next.n++
+			inserted = true
+		}
+		next.values[next.n] = x
+		next.n++
+	}
+	if !inserted {
+		next.values[next.n] = v
+		next.n++
+	}
+	return next
+}
+
+// expressions rebuilds the numbers of st as expressions, in the same order as its values.
+func (st *quickState) expressions() []expression {
+	if st.parent == nil {
+		numbers := make([]expression, st.n)
+		for i, v := range st.values[:st.n] {
+			numbers[i] = makeConstant(v)
+		}
+		return numbers
+	}
+	prev := st.parent.expressions()
+	e := quickBuild(st.op, prev[st.a], prev[st.b])
+	numbers := make([]expression, 0, st.n)
+	inserted := false
+	for i, x := range prev {
+		if i == st.a || i == st.b {
+			continue
+		}
+		if !inserted && x.Val >= e.Val {
+			numbers = append(numbers, e)
+			inserted = true
+		}
+		numbers = append(numbers, x)
+	}
+	if !inserted {
+		numbers = append(numbers, e)
+	}
+	return numbers
+}
+
+// solveQuick looks for one solution for target, playing the game a step at a time: it combines two of the numbers
+// left, trying the states whose numbers are closest to the target, or to a small multiple of one, first.
+// Values more than the target times the largest digit are dropped, as they're unlikely to help.
+// That usually finds a solution quickly if there is one, but it isn't always the shortest, and it gives up after
+// quickMaxStates states, so it can miss solutions. It reports whether it gave up or dropped any values,
+// as otherwise finding nothing means there's no solution.
+func (s solver) solveQuick(target int, digits []int) ([]expression, bool) {
+	if len(digits) == 0 {
+		return nil, false
+	}
+	start := &quickState{n: len(digits), cost: target}
+	copy(start.values[:], digits)
+	sort.Ints(start.values[:start.n])
+	for _, d := range digits {
+		if d == target && s.filter.keep(makeConstant(d)) {
+			return []expression{makeConstant(d)}, false
+		}
+		start.cost = min(start.cost, quickCost(target, d))
+	}
+	bound := target * start.values[start.n-1]
+
+	q := quickQueue{start}
+	seen := map[[maxDigitCount]int]bool{start.values: true}
+	seq := 0
+	pruned := false
+	for expanded := 0; len(q) > 0; expanded++ {
+		if expanded >= quickMaxStates {
+			s.debug("Gave up on a quick solution", "target", target, "states", expanded)
+			return nil, true
+		}
+		if s.stopped() {
+			return nil, true
+		}
+		if s.nodes != nil {
+			s.nodes.Add(1)
+		}
+		s.budget.spend()
+		st := heap.Pop(&q).(*quickState)
+		for a := 0; a < st.n; a++ {
+			for b := a + 1; b < st.n; b++ {
+				for _, op := range quickOps {
+					v, ok := quickApply(op, st.values[a], st.values[b])
+					if !ok {
+						continue
+					}
+					if v > bound {
+						pruned = true
+						continue
+					}
+					if v == target {
+						prev := st.expressions()
+						if e := quickBuild(op, prev[a], prev[b]); s.filter.keep(e) {
+							return []expression{e}, false
+						}
+					}
+					if st.n == 2 {
+						continue
+					}
+					next := st.next(a, b, op, v)
+					if seen[next.values] {
+						continue
+					}
+					seen[next.values] = true
+					next.cost = target
+					// A state which can make the target in one more step is as good as solved.
+					// v can only be combined with the other numbers, so its own place is skipped.
+					self := false
+					for _, x := range next.values[:next.n] {
+						next.cost = min(next.cost, quickCost(target, x))
+						if x == v && !self {
+							self = true
+						} else if quickMakes(target, v, x) {
+							next.cost = 0
+						}
+					}
+					seq++
+					next.seq = seq
+					heap.Push(&q, next)
+				}
+			}
+		}
+	}
+	return nil, pruned
+}
+
+// solveQuickWithin is solveQuick bounded by timeout, if it's positive, like solveWithin.
+// It reports the solution as incomplete if it timed out or gave up.
+func (s solver) solveQuickWithin(timeout time.Duration, target int, digits []int) ([]expression, bool) {
+	if timeout > 0 {
+		parent := s.ctx
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+		s.ctx = ctx
+	}
+	return s.solveQuick(target, digits)
+}
+
+// quickMakes reports whether one step combining x and y makes target.
+func quickMakes(target, x, y int) bool {
+	if x > y {
+		x, y = y, x
+	}
+	for _, op := range quickOps {
+		if v, ok := quickApply(op, x, y); ok && v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+)
+
+func Test_solver_solveQuick(t *testing.T) {
+	tests := map[string]struct {
+		digits         []int
+		target         int
+		filter         solutionFilter
+		wantSolution   bool
+		wantIncomplete bool
+	}{
+		"digit":          {digits: []int{5, 7, 9, 10, 15, 25}, target: 25, wantSolution: true},
+		"one step":       {digits: []int{5, 7, 9, 10, 15, 25}, target: 35, wantSolution: true},
+		"several steps":  {digits: []int{5, 7, 9, 10, 15, 25}, target: 93, wantSolution: true},
+		"big numbers":    {digits: []int{25, 50, 75, 100, 3, 6}, target: 952, wantSolution: true},
+		"filtered":       {digits: []int{2, 9}, target: 7, filter: solutionFilter{noNegation: true}},
+		"no solution":    {digits: []int{2, 3}, target: 100},
+		"values dropped": {digits: []int{7, 11}, target: 2, wantIncomplete: true},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got, incomplete := solver{filter: tt.filter}.solveQuick(tt.target, tt.digits)
+			if incomplete != tt.wantIncomplete {
+				t.Errorf("solveQuick() incomplete = %t, want %t", incomplete, tt.wantIncomplete)
+			}
+			if len(got) > 1 || (len(got) == 1) != tt.wantSolution {
+				t.Fatalf("solveQuick() = %v, want a solution: %t", got, tt.wantSolution)
+			}
+			for _, e := range got {
+				if val, ok := e.eval(); !ok || val != tt.target {
+					t.Errorf("solveQuick() returned %s, which evaluates to %d, %t", e, val, ok)
+				}
+				if err := checkDigits(e, tt.digits); err != nil {
+					t.Errorf("solveQuick() returned %s: %v", e, err)
+				}
+				if !tt.filter.keep(e) {
+					t.Errorf("solveQuick() returned %s, which the filter drops", e)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// rangeShow controls how much of each target's solutions a range run prints.
+type rangeShow int
+
+const (
+	// showCounts prints only the number of solutions.
+	showCounts rangeShow = iota
+	// showShortest adds the shortest solution.
+	showShortest
+	// showAll adds every solution.
+	showAll
+)
+
+var rangeShowNames = map[string]rangeShow{
+	"counts":   showCounts,
+	"shortest": showShortest,
+	"all":      showAll,
+}
+
+func parseRangeShow(s string) (rangeShow, error) {
+	show, ok := rangeShowNames[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown value %q", s)
+	}
+	return show, nil
+}
+
+// writeRangeResult writes the solution count for one target of a range run, with as much detail as show asks for.
+func writeRangeResult(w io.Writer, r targetResult, show rangeShow, p printer) error {
+	note := ""
+	switch {
+	case r.Exhausted:
+		note = " (node budget exhausted, may be incomplete)"
+	case r.Incomplete:
+		note = " (timed out, may be incomplete)"
+	}
+	if show == showCounts || len(r.Solutions) == 0 {
+		_, err := fmt.Fprintf(w, "%d: %d solutions found%s\n", r.Target, r.solutionCount(), note)
+		return err
+	}
+
+	shortest, err := shortest(r.Solutions)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%d: %d solutions found%s, shortest %s\n", r.Target, len(r.Solutions), note, p.format(shortest)); err != nil {
+		return err
+	}
+	if show == showAll {
+		for _, soln := range r.Solutions {
+			if _, err := fmt.Fprintf(w, "  %s\n", p.format(soln)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// formatRangeSummary reports how many targets in results were solvable, and lists those which weren't.
+func formatRangeSummary(results []targetResult) string {
+	var unsolvable []int
+	for _, r := range results {
+		if r.solutionCount() == 0 {
+			unsolvable = append(unsolvable, r.Target)
+		}
+	}
+
+	var b strings.Builder
+	solved := len(results) - len(unsolvable)
+	pct := 0.0
+	if len(results) > 0 {
+		pct = 100 * float64(solved) / float64(len(results))
+	}
+	fmt.Fprintf(&b, "Solved %d of %d targets (%.1f%%)\n", solved, len(results), pct)
+	if len(unsolvable) > 0 {
+		fmt.Fprintf(&b, "Unsolvable: %s\n", formatRuns(unsolvable))
+	}
+	return b.String()
+}
+
+// formatRuns writes ascending values compactly, collapsing consecutive runs: "3, 7-9, 12".
+func formatRuns(values []int) string {
+	var parts []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		if j == i {
+			parts = append(parts, fmt.Sprintf("%d", values[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", values[i], values[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
+
+// solveTargets solves the targets from..to on parallelism workers, passing each result to emit in target order.
+// solve reports false if it was interrupted, in which case that target and the ones after it aren't emitted.
+func solveTargets(from, to, parallelism int, solve func(target int) (targetResult, bool), emit func(targetResult)) {
+	type solved struct {
+		r  targetResult
+		ok bool
+	}
+	jobs := make(chan int)
+	done := make(chan struct{})
+	go func() {
+		defer close(jobs)
+		for t := from; t <= to; t++ {
+			select {
+			case jobs <- t:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	results := make(chan solved)
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range jobs {
+				r, ok := solve(t)
+				r.Target = t
+				results <- solved{r, ok}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Results arrive in whatever order they finish, so hold them until the ones before them are emitted.
+	pending := make(map[int]solved)
+	next := from
+	stopped := false
+	for res := range results {
+		if stopped {
+			continue
+		}
+		pending[res.r.Target] = res
+		for {
+			p, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			if !p.ok {
+				stopped = true
+				close(done)
+				break
+			}
+			emit(p.r)
+			next++
+		}
+	}
+}
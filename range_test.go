@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_writeRangeResult(t *testing.T) {
+	digits := []int{2, 3, 7}
+	p := newPrinter(notationInfix, false)
+
+	tests := map[string]struct {
+		target     int
+		show       rangeShow
+		incomplete bool
+		exhausted  bool
+		want       string
+	}{
+		"counts": {
+			target: 8,
+			show:   showCounts,
+			want:   "8: 2 solutions found\n",
+		},
+		"shortest": {
+			target: 8,
+			show:   showShortest,
+			want:   "8: 2 solutions found, shortest 7 + 3 - 2\n",
+		},
+		"all": {
+			target: 8,
+			show:   showAll,
+			want:   "8: 2 solutions found, shortest 7 + 3 - 2\n  7 + 3 - 2\n  (7 - 3)*2\n",
+		},
+		"incomplete": {
+			target:     8,
+			show:       showShortest,
+			incomplete: true,
+			want:       "8: 2 solutions found (timed out, may be incomplete), shortest 7 + 3 - 2\n",
+		},
+		"exhausted": {
+			target:     8,
+			show:       showCounts,
+			incomplete: true,
+			exhausted:  true,
+			want:       "8: 2 solutions found (node budget exhausted, may be incomplete)\n",
+		},
+		"unsolvable": {
+			target: 24,
+			show:   showAll,
+			want:   "24: 0 solutions found\n",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			var b bytes.Buffer
+			r := targetResult{Target: tt.target, Solutions: solve(tt.target, digits), Incomplete: tt.incomplete, Exhausted: tt.exhausted}
+			if err := writeRangeResult(&b, r, tt.show, p); err != nil {
+				t.Fatalf("writeRangeResult() failed unexpectedly: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, b.String()); diff != "" {
+				t.Errorf("writeRangeResult() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_formatRangeSummary(t *testing.T) {
+	solved := []expression{makeConstant(1)}
+	tests := map[string]struct {
+		results []targetResult
+		want    string
+	}{
+		"all solved": {
+			results: []targetResult{{Target: 1, Solutions: solved}, {Target: 2, Solutions: solved}},
+			want:    "Solved 2 of 2 targets (100.0%)\n",
+		},
+		"runs": {
+			results: []targetResult{
+				{Target: 1},
+				{Target: 2, Solutions: solved},
+				{Target: 3},
+				{Target: 4},
+				{Target: 5},
+				{Target: 6, Solutions: solved},
+				{Target: 7},
+			},
+			want: "Solved 2 of 7 targets (28.6%)\nUnsolvable: 1, 3-5, 7\n",
+		},
+		"empty": {
+			want: "Solved 0 of 0 targets (0.0%)\n",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := formatRangeSummary(tt.results)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("formatRangeSummary() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_solveTargets(t *testing.T) {
+	tests := map[string]struct {
+		parallelism int
+		// stopAt is the first target solve reports as interrupted, if positive.
+		stopAt int
+		want   []int
+	}{
+		"serial":      {parallelism: 1, want: []int{10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}},
+		"parallel":    {parallelism: 4, want: []int{10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}},
+		"interrupted": {parallelism: 4, stopAt: 15, want: []int{10, 11, 12, 13, 14}},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			solve := func(target int) (targetResult, bool) {
+				if tt.stopAt > 0 && target >= tt.stopAt {
+					return targetResult{}, false
+				}
+				// Solve later targets faster, so they finish out of order.
+				time.Sleep(time.Duration(20-target) * time.Millisecond)
+				return targetResult{Target: target}, true
+			}
+			var got []int
+			solveTargets(10, 20, tt.parallelism, solve, func(r targetResult) {
+				got = append(got, r.Target)
+			})
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("solveTargets() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
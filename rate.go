@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// errNoSolution is returned when rating a puzzle with no solution, as there's nothing to rate.
+var errNoSolution = errors.New("no solution")
+
+// puzzleRating rates how hard a puzzle is, from 1 for the easiest to 5 for the hardest, with the signals it's based on.
+type puzzleRating struct {
+	Rating int `json:"rating"`
+	// Solutions is how many solutions there are: the fewer, the harder one is to find.
+	Solutions int `json:"solutions"`
+	// MinOps is the fewest operations any solution needs.
+	MinOps int `json:"min_ops"`
+	// NeedsDivision reports whether every solution divides.
+	NeedsDivision bool `json:"needs_division"`
+	// LargestIntermediate is the largest value worked out along the way by the solution which keeps it smallest.
+	LargestIntermediate int `json:"largest_intermediate"`
+	// Nodes is how many sub-problems the search for the solutions with the fewest operations tried.
+	Nodes int64 `json:"nodes"`
+}
+
+// ratePuzzle solves p and rates how hard it is, stopping early with ctx's error if it's done,
+// or errBudgetExhausted if budget runs out. A nil budget is unlimited.
+func ratePuzzle(ctx context.Context, budget *nodeBudget, p puzzle) (puzzleRating, error) {
+	var nodes atomic.Int64
+	s := solver{ctx: ctx, nodes: &nodes, budget: budget}
+	fewest := s.solveFewest(p.Target, p.Digits, 1)
+	s.nodes = nil
+	solns := s.solve(p.Target, p.Digits)
+	if err := stopReason(ctx, budget); err != nil {
+		return puzzleRating{}, err
+	}
+	if len(fewest) == 0 || len(solns) == 0 {
+		return puzzleRating{}, errNoSolution
+	}
+	r := puzzleRating{
+		Solutions:           len(solns),
+		MinOps:              len(fewest[0].steps()),
+		NeedsDivision:       true,
+		LargestIntermediate: solns[0].maxIntermediate(),
+		Nodes:               nodes.Load(),
+	}
+	for _, e := range solns {
+		r.NeedsDivision = r.NeedsDivision && e.uses(opDivide)
+		r.LargestIntermediate = min(r.LargestIntermediate, e.maxIntermediate())
+	}
+	r.Rating = r.rate(p.Target)
+	return r, nil
+}
+
+// rate turns the signals into a rating: each adds points for making the puzzle harder, and every two points
+// beyond the first puts it up a level.
+func (r puzzleRating) rate(target int) int {
+	points := 0
+	switch {
+	case r.Solutions < 5:
+		points += 3
+	case r.Solutions < 20:
+		points += 2
+	case r.Solutions < 100:
+		points++
+	}
+	points += min(max(r.MinOps-1, 0), 4)
+	if r.NeedsDivision {
+		points += 2
+	}
+	// Working out something bigger than the target means overshooting it and coming back.
+	if r.LargestIntermediate > target {
+		points++
+	}
+	if r.Nodes > 2000 {
+		points++
+	}
+	return min(1+points/2, 5)
+}
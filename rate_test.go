@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_ratePuzzle(t *testing.T) {
+	tests := map[string]struct {
+		puzzle puzzle
+		want   puzzleRating
+	}{
+		"a digit": {
+			puzzle: puzzle{Digits: []int{3, 6, 25, 50, 75, 100}, Target: 100},
+			want:   puzzleRating{Rating: 1, Solutions: 267, MinOps: 0, Nodes: 6},
+		},
+		"many solutions": {
+			puzzle: puzzle{Digits: []int{5, 7, 9, 10, 15, 25}, Target: 93},
+			want:   puzzleRating{Rating: 2, Solutions: 55, MinOps: 3, LargestIntermediate: 93, Nodes: 617},
+		},
+		"few long solutions": {
+			puzzle: puzzle{Digits: []int{3, 6, 25, 50, 75, 100}, Target: 952},
+			want:   puzzleRating{Rating: 5, Solutions: 2, MinOps: 5, NeedsDivision: true, LargestIntermediate: 23850, Nodes: 3331},
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got, err := ratePuzzle(context.Background(), nil, tt.puzzle)
+			if err != nil {
+				t.Fatalf("ratePuzzle(%v) failed unexpectedly: %v", tt.puzzle, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ratePuzzle(%v) mismatch (-want +got):\n%s", tt.puzzle, diff)
+			}
+		})
+	}
+}
+
+func Test_ratePuzzle_noSolution(t *testing.T) {
+	p := puzzle{Digits: []int{2, 3}, Target: 100}
+	if _, err := ratePuzzle(context.Background(), nil, p); !errors.Is(err, errNoSolution) {
+		t.Errorf("ratePuzzle(%v) error = %v, want %v", p, err, errNoSolution)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// reachableValues returns every positive value solve can make from digits, in ascending order.
+// It mirrors solve's search, combining one digit at a time with a value made from the others,
+// but only tracks values so it's far cheaper than solving for each candidate target.
+func reachableValues(digits []int) []int {
+	n := len(digits)
+	vals := make([]map[int]bool, 1<<n)
+	all := make(map[int]bool)
+	for mask := 1; mask < 1<<n; mask++ {
+		vals[mask] = make(map[int]bool)
+		for i, a := range digits {
+			bit := 1 << i
+			if mask&bit == 0 {
+				continue
+			}
+			rest := mask &^ bit
+			if rest == 0 {
+				vals[mask][a] = true
+				continue
+			}
+			for s := range vals[rest] {
+				for _, v := range combineValues(a, s) {
+					vals[mask][v] = true
+				}
+			}
+		}
+		for v := range vals[mask] {
+			all[v] = true
+		}
+	}
+
+	out := make([]int, 0, len(all))
+	for v := range all {
+		out = append(out, v)
+	}
+	sort.Ints(out)
+	return out
+}
+
+// combineValues returns the positive values formed by combining a digit a with a value s.
+// A sum or product which would overflow is left out, as solve can't make it either.
+func combineValues(a, s int) []int {
+	var out []int
+	if a <= math.MaxInt-s {
+		out = append(out, a+s)
+	}
+	if p, ok := mulInts(a, s); ok {
+		out = append(out, p)
+	}
+	if a > s {
+		out = append(out, a-s)
+	}
+	if s > a {
+		out = append(out, s-a)
+	}
+	if a%s == 0 {
+		out = append(out, a/s)
+	}
+	if s%a == 0 {
+		out = append(out, s/a)
+	}
+	return out
+}
+
+// closestReachable returns the values either side of target in values, which must be sorted.
+// below or above is 0 if there's no value on that side, and both are target if it's in values.
+func closestReachable(values []int, target int) (below, above int) {
+	i := sort.SearchInts(values, target)
+	if i < len(values) && values[i] == target {
+		return target, target
+	}
+	if i > 0 {
+		below = values[i-1]
+	}
+	if i < len(values) {
+		above = values[i]
+	}
+	return below, above
+}
+
+// closestValue returns the value in values nearest to target, which must be sorted,
+// preferring the lower on a tie, or 0 if values is empty.
+func closestValue(values []int, target int) int {
+	below, above := closestReachable(values, target)
+	if below == 0 || (above != 0 && above-target < target-below) {
+		return above
+	}
+	return below
+}
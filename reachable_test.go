@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_reachableValues(t *testing.T) {
+	tests := map[string]struct {
+		digits []int
+		want   []int
+	}{
+		"single": {
+			digits: []int{7},
+			want:   []int{7},
+		},
+		"pair": {
+			digits: []int{2, 3},
+			want:   []int{1, 2, 3, 5, 6},
+		},
+		"repeated": {
+			digits: []int{2, 2},
+			want:   []int{1, 2, 4},
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := reachableValues(tt.digits)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("reachableValues(%v) mismatch (-want +got):\n%s", tt.digits, diff)
+			}
+		})
+	}
+}
+
+func Test_reachableValues_matchesSolve(t *testing.T) {
+	digits := []int{2, 3, 7, 10}
+	reachable := make(map[int]bool)
+	for _, v := range reachableValues(digits) {
+		reachable[v] = true
+	}
+	for target := 1; target <= 500; target++ {
+		solved := len(solve(target, digits)) > 0
+		if solved != reachable[target] {
+			t.Errorf("target %d: solve() found solutions = %t, reachableValues() includes it = %t", target, solved, reachable[target])
+		}
+	}
+}
+
+func Test_closestReachable(t *testing.T) {
+	values := []int{1, 2, 3, 5, 6}
+	tests := map[string]struct {
+		target    int
+		wantBelow int
+		wantAbove int
+	}{
+		"reachable": {target: 3, wantBelow: 3, wantAbove: 3},
+		"between":   {target: 4, wantBelow: 3, wantAbove: 5},
+		"too large": {target: 8, wantBelow: 6, wantAbove: 0},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			below, above := closestReachable(values, tt.target)
+			if below != tt.wantBelow || above != tt.wantAbove {
+				t.Errorf("closestReachable(%d) = %d, %d, want %d, %d", tt.target, below, above, tt.wantBelow, tt.wantAbove)
+			}
+		})
+	}
+}
+
+func Test_closestValue(t *testing.T) {
+	tests := map[string]struct {
+		values []int
+		target int
+		want   int
+	}{
+		"reachable": {values: []int{1, 2, 3, 5, 6}, target: 3, want: 3},
+		"tie":       {values: []int{1, 2, 3, 5, 6}, target: 4, want: 3},
+		"above":     {values: []int{1, 10, 12}, target: 9, want: 10},
+		"too large": {values: []int{1, 2, 3, 5, 6}, target: 8, want: 6},
+		"too small": {values: []int{5, 6}, target: 1, want: 5},
+		"no values": {target: 4, want: 0},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			if got := closestValue(tt.values, tt.target); got != tt.want {
+				t.Errorf("closestValue(%v, %d) = %d, want %d", tt.values, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_reachableValues_overflow(t *testing.T) {
+	tests := map[string][]int{
+		"nine 256s":   {256, 256, 256, 256, 256, 256, 256, 256, 256},
+		"five 65536s": {65536, 65536, 65536, 65536, 65536},
+	}
+	for tn, digits := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := reachableValues(digits)
+			if len(got) == 0 || got[0] != 1 {
+				t.Fatalf("reachableValues(%v) = %v, want values starting at 1", digits, got)
+			}
+			for _, v := range got {
+				if v <= 0 {
+					t.Errorf("reachableValues(%v) includes %d, want only positive values", digits, v)
+				}
+			}
+		})
+	}
+}
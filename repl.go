@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const replHelp = `Commands:
+  digits 5 7 9 10 15 25   use these digits
+  93                      solve for a target
+  all 93                  list every solution for a target
+  9*7 + 25 + 5            check an expression uses the digits, and show its value
+  reachable               summarize the values the digits can make
+  help                    show this help
+  quit                    exit
+`
+
+// repl answers queries about a set of digits, caching work between them.
+type repl struct {
+	out     io.Writer
+	printer printer
+	// solveCache caches work for the current digits.
+	*solveCache
+	// disk, if set, keeps the work for each set of digits between runs.
+	disk *diskCache
+}
+
+func newREPL(out io.Writer, p printer, digits []int, disk *diskCache) *repl {
+	r := &repl{out: out, printer: p, disk: disk}
+	r.setDigits(digits)
+	return r
+}
+
+func (r *repl) setDigits(digits []int) {
+	r.solveCache = newSolveCache(digits, r.disk)
+}
+
+// run reads queries from in until it's exhausted or the user quits.
+func (r *repl) run(in io.Reader) error {
+	sc := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(r.out, "> ")
+		if !sc.Scan() {
+			fmt.Fprintln(r.out)
+			return sc.Err()
+		}
+		quit, err := r.eval(sc.Text())
+		if err != nil {
+			fmt.Fprintf(r.out, "error: %v\n", err)
+		}
+		if quit {
+			return nil
+		}
+	}
+}
+
+// eval answers a single query, and reports whether the user asked to quit.
+func (r *repl) eval(line string) (bool, error) {
+	line = strings.TrimSpace(line)
+	cmd, rest, _ := strings.Cut(line, " ")
+	switch cmd {
+	case "":
+		return false, nil
+	case "quit", "exit":
+		return true, nil
+	case "help":
+		fmt.Fprint(r.out, replHelp)
+		return false, nil
+	case "digits":
+		digits, err := parseDigitArgs("", strings.Fields(rest))
+		if err != nil {
+			return false, err
+		}
+		r.setDigits(digits)
+		fmt.Fprintf(r.out, "Using %s\n", formatDigits(digits))
+		return false, nil
+	}
+
+	if len(r.digits) == 0 {
+		return false, fmt.Errorf("set the digits first, e.g. digits 5 7 9 10 15 25")
+	}
+
+	switch cmd {
+	case "reachable":
+		r.writeReachable()
+		return false, nil
+	case "all":
+		target, err := strconv.Atoi(strings.TrimSpace(rest))
+		if err != nil {
+			return false, fmt.Errorf("invalid target %q", strings.TrimSpace(rest))
+		}
+		solns := r.solve(target)
+		fmt.Fprintf(r.out, "%d: %d solutions\n", target, len(solns))
+		for _, s := range solns {
+			fmt.Fprintf(r.out, "  %s\n", r.printer.format(s))
+		}
+		return false, nil
+	}
+
+	if target, err := strconv.Atoi(line); err == nil {
+		solns := r.solve(target)
+		if len(solns) == 0 {
+			fmt.Fprintf(r.out, "%d: no solutions\n", target)
+			return false, nil
+		}
+		shortest, err := shortest(solns)
+		if err != nil {
+			return false, err
+		}
+		fmt.Fprintf(r.out, "%d: %d solutions, shortest %s\n", target, len(solns), r.printer.format(shortest))
+		return false, nil
+	}
+
+	e, err := parseInfix(line)
+	if err != nil {
+		return false, err
+	}
+	if err := checkDigits(e, r.digits); err != nil {
+		return false, err
+	}
+	val, ok := e.eval()
+	if !ok {
+		return false, fmt.Errorf("%s is not valid: every step must give a positive whole number", r.printer.format(e))
+	}
+	fmt.Fprintf(r.out, "%s = %d\n", r.printer.format(e), val)
+	return false, nil
+}
+
+func (r *repl) writeReachable() {
+	if len(r.reachable) == 0 {
+		fmt.Fprintln(r.out, "No values are reachable")
+		return
+	}
+	largest := r.reachable[len(r.reachable)-1]
+	fmt.Fprintf(r.out, "%d values reachable, up to %d\n", len(r.reachable), largest)
+	for v := 1; v <= largest; v++ {
+		if !r.isReached[v] {
+			fmt.Fprintf(r.out, "Smallest unreachable value: %d\n", v)
+			return
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_repl(t *testing.T) {
+	input := strings.Join([]string{
+		"93",
+		"24",
+		"9*7 + 25 + 5",
+		"9*9",
+		"7 - 9",
+		"digits 2 3 7",
+		"all 8",
+		"reachable",
+		"quit",
+		"93",
+	}, "\n")
+	want := strings.Join([]string{
+		"> 93: 55 solutions, shortest 9*7 + 25 + 5",
+		"> 24: 152 solutions, shortest 15 + 9",
+		"> 9*7 + 25 + 5 = 93",
+		"> error: digit 9 used twice",
+		"> error: 7 - 9 is not valid: every step must give a positive whole number",
+		"> Using 2,3,7",
+		"> 8: 2 solutions",
+		"  7 + 3 - 2",
+		"  (7 - 3)*2",
+		"> 23 values reachable, up to 42",
+		"Smallest unreachable value: 16",
+		"> ",
+	}, "\n")
+
+	var b bytes.Buffer
+	r := newREPL(&b, newPrinter(notationInfix, false), []int{5, 7, 9, 10, 15, 25}, nil)
+	if err := r.run(strings.NewReader(input)); err != nil {
+		t.Fatalf("run() failed unexpectedly: %v", err)
+	}
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("run() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// targetResult holds the solutions found for a single target.
+type targetResult struct {
+	Target    int
+	Solutions []expression
+	// Count is the number of solutions when they were only counted, with Solutions left empty.
+	Count int
+	// Incomplete reports whether the search timed out, so there may be more solutions.
+	Incomplete bool
+	// Exhausted reports whether it was incomplete because it ran out of node budget, rather than time.
+	Exhausted bool
+}
+
+// solutionCount returns the number of solutions, whether they were kept or only counted.
+func (r targetResult) solutionCount() int {
+	if len(r.Solutions) > 0 {
+		return len(r.Solutions)
+	}
+	return r.Count
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Solutions for {{.Digits}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; vertical-align: top; }
+th { background: #eee; }
+td.count { text-align: right; }
+tr.unsolved td { color: #999; }
+details ol { margin: 0.3em 0; }
+</style>
+</head>
+<body>
+<h1>Solutions for {{.Digits}}</h1>
+<p>Targets {{.Min}} to {{.Max}}: {{.Solved}} of {{len .Rows}} solvable.</p>
+<table>
+<tr><th>Target</th><th>Solutions</th><th>Shortest</th><th>All solutions</th></tr>
+{{- range .Rows}}
+<tr{{if not .Count}} class="unsolved"{{end}}>
+<td>{{.Target}}</td>
+<td class="count">{{.Count}}</td>
+<td>{{.Shortest}}</td>
+<td>{{if .All}}<details><summary>Show {{.Count}}</summary><ol>{{range .All}}<li>{{.}}</li>{{end}}</ol></details>{{end}}</td>
+</tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+type reportRow struct {
+	Target   int
+	Count    int
+	Shortest string
+	All      []string
+}
+
+// writeReport writes a self-contained HTML page summarising the results of a range run.
+func writeReport(w io.Writer, digits []int, results []targetResult, p printer) error {
+	data := struct {
+		Digits   string
+		Min, Max int
+		Solved   int
+		Rows     []reportRow
+	}{
+		Digits: formatDigits(digits),
+	}
+	if len(results) > 0 {
+		data.Min = results[0].Target
+		data.Max = results[len(results)-1].Target
+	}
+
+	for _, r := range results {
+		row := reportRow{Target: r.Target, Count: len(r.Solutions)}
+		if s, err := shortest(r.Solutions); err == nil {
+			data.Solved++
+			row.Shortest = p.format(s)
+		}
+		for _, soln := range r.Solutions {
+			row.All = append(row.All, p.format(soln))
+		}
+		data.Rows = append(data.Rows, row)
+	}
+	return reportTemplate.Execute(w, data)
+}
+
+// formatDigits writes digits as a comma-separated list, the same way they're passed to --digits.
+func formatDigits(digits []int) string {
+	parts := make([]string, len(digits))
+	for i, d := range digits {
+		parts[i] = strconv.Itoa(d)
+	}
+	return strings.Join(parts, ",")
+}
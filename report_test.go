@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_writeReport(t *testing.T) {
+	digits := []int{2, 3, 4}
+	results := []targetResult{
+		{Target: 5, Solutions: solve(5, digits)},
+		{Target: 23, Solutions: solve(23, digits)},
+	}
+
+	var b strings.Builder
+	if err := writeReport(&b, digits, results, newPrinter(notationInfix, false)); err != nil {
+		t.Fatalf("writeReport() failed unexpectedly: %v", err)
+	}
+	got := b.String()
+
+	for _, want := range []string{
+		"<title>Solutions for 2,3,4</title>",
+		"Targets 5 to 23: 1 of 2 solvable.",
+		"<td>5</td>",
+		`<tr class="unsolved">`,
+		"<li>3 &#43; 2</li>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("writeReport() output missing %q:\n%s", want, got)
+		}
+	}
+}
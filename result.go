@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/template"
+)
+
+// solution is a single solution with metadata derived from it, for structured output.
+type solution struct {
+	// Text is the solution written with the selected notation.
+	Text  string
+	Value int
+	// Ops is the number of binary operations needed to evaluate the solution.
+	Ops int
+	// Digits are the digits used by the solution, in ascending order.
+	Digits []int
+	Steps  []step
+	Score  solutionScore
+
+	expr    expression
+	printer printer
+}
+
+func makeSolution(e expression, p printer) solution {
+	steps := e.steps()
+	return solution{
+		Text:    p.format(e),
+		Value:   e.Val,
+		Ops:     len(steps),
+		Digits:  e.digits(),
+		Steps:   steps,
+		Score:   e.score(),
+		expr:    e,
+		printer: p,
+	}
+}
+
+func (s solution) String() string {
+	return s.Text
+}
+
+// Format writes the solution in the named notation, e.g. {{.Shortest.Format "rpn"}} in templates.
+func (s solution) Format(name string) (string, error) {
+	n, err := parseNotation(name)
+	if err != nil {
+		return "", err
+	}
+	p := s.printer
+	p.notation = n
+	return p.format(s.expr), nil
+}
+
+// Explain describes the solution in words.
+func (s solution) Explain() string {
+	return explain(s.expr)
+}
+
+// digits returns the constants used in e, in ascending order.
+func (e expression) digits() []int {
+	var out []int
+	var walk func(e expression)
+	walk = func(e expression) {
+		if e.Op == opNone {
+			out = append(out, e.Val)
+		}
+		for _, c := range e.Children {
+			walk(*c)
+		}
+	}
+	walk(e)
+	sort.Ints(out)
+	return out
+}
+
+// solveResult is the structured result of solving for a single target.
+type solveResult struct {
+	Target int
+	Digits []int
+	// Solved reports whether any solution was found.
+	Solved    bool
+	Count     int
+	Solutions []solution
+	// Shortest, Easiest and Cleverest are the zero solution if Solved is false.
+	Shortest, Easiest, Cleverest solution
+	// Incomplete reports whether the search timed out, so there may be more solutions.
+	Incomplete bool
+	// Delta is how far the solutions are from Target when there was no exact solution
+	// and solve --closest fell back to the closest reachable value, or 0 otherwise.
+	Delta int
+}
+
+func makeSolveResult(target int, digits []int, solns []expression, p printer) solveResult {
+	r := solveResult{
+		Target: target,
+		Digits: digits,
+		Solved: len(solns) > 0,
+		Count:  len(solns),
+	}
+	for _, s := range solns {
+		r.Solutions = append(r.Solutions, makeSolution(s, p))
+	}
+	if s, err := shortest(solns); err == nil {
+		r.Shortest = makeSolution(s, p)
+	}
+	if s, err := easiest(solns); err == nil {
+		r.Easiest = makeSolution(s, p)
+	}
+	if s, err := cleverest(solns); err == nil {
+		r.Cleverest = makeSolution(s, p)
+	}
+	return r
+}
+
+// parseOutputTemplate parses a user-supplied --template.
+func parseOutputTemplate(s string) (*template.Template, error) {
+	return template.New("output").Option("missingkey=error").Parse(s)
+}
+
+// writeTemplate writes r using t, followed by a newline.
+func writeTemplate(w io.Writer, t *template.Template, r solveResult) error {
+	if err := t.Execute(w, r); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
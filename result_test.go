@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_writeTemplate(t *testing.T) {
+	digits := []int{5, 7, 9, 10, 15, 25}
+	r := makeSolveResult(93, digits, solve(93, digits), newPrinter(notationInfix, false))
+	unsolved := makeSolveResult(1000, []int{2, 3}, nil, newPrinter(notationInfix, false))
+
+	tests := map[string]struct {
+		template string
+		result   solveResult
+		want     string
+	}{
+		"shortest": {
+			template: "{{.Target}} = {{.Shortest}}",
+			result:   r,
+			want:     "93 = 9*7 + 25 + 5\n",
+		},
+		"metadata": {
+			template: "{{.Count}} {{.Shortest.Ops}} {{.Shortest.Digits}} {{index .Shortest.Steps 0}}",
+			result:   r,
+			want:     "55 3 [5 7 9 25] 9 * 7 = 63\n",
+		},
+		"methods": {
+			template: `{{.Shortest.Format "rpn"}} / {{.Shortest.Explain}}`,
+			result:   r,
+			want:     "9 7 * 25 + 5 + / Multiply 9 by 7 to get 63, then add 25 to get 88, then add 5 to get 93.\n",
+		},
+		"range": {
+			template: "{{range $i, $s := .Solutions}}{{if lt $i 2}}{{$s}};{{end}}{{end}}",
+			result:   r,
+			want:     "(25*(15 + 10) - 9)/7 + 5;9*7 + 15 + 10 + 5;\n",
+		},
+		"unsolved": {
+			template: "{{.Target}}: {{if .Solved}}{{.Shortest}}{{else}}none{{end}}",
+			result:   unsolved,
+			want:     "1000: none\n",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			tmpl, err := parseOutputTemplate(tt.template)
+			if err != nil {
+				t.Fatalf("parseOutputTemplate(%q) failed unexpectedly: %v", tt.template, err)
+			}
+			var b strings.Builder
+			if err := writeTemplate(&b, tmpl, tt.result); err != nil {
+				t.Fatalf("writeTemplate() failed unexpectedly: %v", err)
+			}
+			if !cmp.Equal(b.String(), tt.want) {
+				t.Errorf("writeTemplate() = %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"math/bits"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSampleTries bounds how many samples are drawn per solution asked for, as some may be repeats.
+const maxSampleTries = 20
+
+// sample returns up to k different solutions for target, each chosen uniformly at random from the distinct
+// solutions c counted, without finding the rest. There are fewer if there aren't k solutions, or if
+// too many of the samples drawn were repeats.
+func (c *solutionCounts) sample(rng *rand.Rand, target, k int) []expression {
+	total := c.totals[target]
+	if c.incomplete || total == 0 {
+		return nil
+	}
+	s := sampler{c: c, rng: rng, termValues: make(map[int][]int), factorValues: make(map[int][]int)}
+	seen := make(map[string]bool)
+	var out []expression
+	for tries := 0; len(out) < min(k, total) && tries < k*maxSampleTries; tries++ {
+		e, key := s.distinctSolution(target)
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// sampleSolutions counts the solutions for digits, then samples up to k of them for target. Counting stops
+// early if ctx is done or timeout is positive and passes, which is reported as incomplete.
+func sampleSolutions(ctx context.Context, timeout time.Duration, target int, digits []int, k int, rng *rand.Rand) ([]expression, bool) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	c := countSolutions(ctx, digits)
+	return c.sample(rng, target, k), c.incomplete
+}
+
+// sampler draws solutions from solutionCounts' tables, choosing each part in proportion to how many solutions it's in.
+type sampler struct {
+	c   *solutionCounts
+	rng *rand.Rand
+	// termValues and factorValues cache the values in the terms and factors tables by mask, in ascending order,
+	// so the choices only depend on the seed.
+	termValues, factorValues map[int][]int
+}
+
+// sampledPart is a term of a sum or a factor of a product, and whether it's subtracted or divided.
+type sampledPart struct {
+	e        expression
+	key      string
+	inverted bool
+}
+
+// pick returns the index of the weight r falls in, so each index is chosen in proportion to its weight.
+func pick(r int, weights []int) int {
+	for i, w := range weights {
+		if r < w {
+			return i
+		}
+		r -= w
+	}
+	panic("sample weights don't add up")
+}
+
+func (s sampler) intn(n int) int {
+	return int(s.rng.Int63n(int64(n)))
+}
+
+// values returns the values in table[mask] in ascending order, caching them in cache.
+func (s sampler) values(cache map[int][]int, table []map[int]int, mask int) []int {
+	if values, ok := cache[mask]; ok {
+		return values
+	}
+	values := make([]int, 0, len(table[mask]))
+	for v := range table[mask] {
+		values = append(values, v)
+	}
+	sort.Ints(values)
+	cache[mask] = values
+	return values
+}
+
+// leaf returns 1 if mask is a single digit with value v, and 0 otherwise.
+func (s sampler) leaf(mask, v int) int {
+	if bits.OnesCount(uint(mask)) == 1 && s.c.digits[bits.TrailingZeros(uint(mask))] == v {
+		return 1
+	}
+	return 0
+}
+
+// distinctSolution draws one of the solutions for target, each with the same chance even if some digits are repeated.
+// solution draws from the tables, which tell the copies of a repeated digit apart, so a solution comes up once for
+// each way of choosing its copies. Keeping each draw with a chance of one in that many evens them out.
+func (s sampler) distinctSolution(target int) (expression, string) {
+	for {
+		e, key := s.solution(target)
+		if n := labelings(e, s.c.digits); n == 1 || s.intn(n) == 0 {
+			return e, key
+		}
+	}
+}
+
+// labelings returns how many ways the copies of digits' repeated values can be chosen to write e, which is in
+// the form sampler builds, with the sums and products flattened. Swapping identical parts of a sum or product
+// doesn't make a new way, so it's the number of ways to pick the copies divided by the number of ways to
+// rearrange e's identical parts.
+func labelings(e expression, digits []int) int {
+	available := make(map[int]int)
+	for _, d := range digits {
+		available[d]++
+	}
+	n := 1
+	for _, d := range e.digits() {
+		n *= available[d]
+		available[d]--
+	}
+	return n / symmetries(e)
+}
+
+// symmetries returns how many ways e's identical parts can be rearranged without changing it.
+func symmetries(e expression) int {
+	var parts []*expression
+	switch e.Op {
+	case opNone:
+		return 1
+	case opNegate:
+		return symmetries(*e.Children[0])
+	case opDivide:
+		// The multipliers and divisors are rearranged separately.
+		return symmetries(*e.Children[0]) * symmetries(*e.Children[1])
+	default:
+		parts = e.Children
+	}
+	n := 1
+	same := make(map[string]int)
+	for _, p := range parts {
+		key := formatSExpr(*p, opStrings)
+		same[key]++
+		// The kth identical part can go in any of k places.
+		n *= same[key] * symmetries(*p)
+	}
+	return n
+}
+
+// solution draws one of the solutions for target from the tables, with a key which is the same for the same solution.
+func (s sampler) solution(target int) (expression, string) {
+	c := s.c
+	weights := make([]int, len(c.terms))
+	for mask := 1; mask < len(c.terms); mask++ {
+		// Digits, sums and products: terms and factors both include the digits.
+		weights[mask] = c.terms[mask][target] + c.factors[mask][target] - s.leaf(mask, target)
+	}
+	mask := pick(s.intn(c.labeled[target]), weights)
+	leaf := s.leaf(mask, target)
+	switch pick(s.intn(weights[mask]), []int{leaf, c.factors[mask][target] - leaf, c.terms[mask][target] - leaf}) {
+	case 0:
+		return makeConstant(target), strconv.Itoa(target)
+	case 1:
+		return s.sum(s.sumParts(mask, target))
+	default:
+		return s.product(s.productParts(mask, fraction{num: target, den: 1, hasNum: true}))
+	}
+}
+
+// term draws an expression which isn't a sum with value v from the digits in mask.
+func (s sampler) term(mask, v int) (expression, string) {
+	leaf := s.leaf(mask, v)
+	if s.intn(s.c.terms[mask][v]) < leaf {
+		return makeConstant(v), strconv.Itoa(v)
+	}
+	return s.product(s.productParts(mask, fraction{num: v, den: 1, hasNum: true}))
+}
+
+// factor draws an expression which isn't a product with value v from the digits in mask.
+func (s sampler) factor(mask, v int) (expression, string) {
+	leaf := s.leaf(mask, v)
+	if s.intn(s.c.factors[mask][v]) < leaf {
+		return makeConstant(v), strconv.Itoa(v)
+	}
+	return s.sum(s.sumParts(mask, v))
+}
+
+// sumParts draws two or more terms adding up to v, which use every digit in mask.
+func (s sampler) sumParts(mask, v int) []sampledPart {
+	type choice struct {
+		part, w int
+		sign    int
+	}
+	var choices []choice
+	var weights []int
+	low := mask & -mask
+	for part := (mask - 1) & mask; part > 0; part = (part - 1) & mask {
+		if part&low == 0 {
+			continue
+		}
+		rest := mask ^ part
+		for _, w := range s.values(s.termValues, s.c.terms, part) {
+			for _, sign := range []int{1, -1} {
+				if n := s.c.sums[rest][v-sign*w]; n > 0 {
+					choices = append(choices, choice{part, w, sign})
+					weights = append(weights, s.c.terms[part][w]*n)
+				}
+			}
+		}
+	}
+	ch := choices[pick(s.intn(sum(weights)), weights)]
+	e, key := s.term(ch.part, ch.w)
+	return append(s.sumSet(mask^ch.part, v-ch.sign*ch.w), sampledPart{e: e, key: key, inverted: ch.sign < 0})
+}
+
+// sumSet draws one or more terms adding up to v, which may not be positive, using every digit in mask.
+func (s sampler) sumSet(mask, v int) []sampledPart {
+	single := s.c.terms[mask][abs(v)]
+	if v == 0 {
+		single = 0
+	}
+	if s.intn(s.c.sums[mask][v]) < single {
+		e, key := s.term(mask, abs(v))
+		return []sampledPart{{e: e, key: key, inverted: v < 0}}
+	}
+	return s.sumParts(mask, v)
+}
+
+// productParts draws two or more factors whose product is f, which use every digit in mask.
+func (s sampler) productParts(mask int, f fraction) []sampledPart {
+	type choice struct {
+		part, w int
+		rest    fraction
+		divides bool
+	}
+	var choices []choice
+	var weights []int
+	add := func(part, w int, rest fraction, divides bool) {
+		if n := s.c.products[mask^part][rest]; n > 0 {
+			choices = append(choices, choice{part, w, rest, divides})
+			weights = append(weights, s.c.factors[part][w]*n)
+		}
+	}
+	low := mask & -mask
+	for part := (mask - 1) & mask; part > 0; part = (part - 1) & mask {
+		if part&low == 0 {
+			continue
+		}
+		for _, w := range s.values(s.factorValues, s.c.factors, part) {
+			// Multiplying by w means the rest made f / w, with or without a factor which multiplies.
+			if f.hasNum {
+				if rest, ok := f.times(fraction{num: 1, den: w}); ok {
+					for _, hasNum := range []bool{true, false} {
+						rest.hasNum = hasNum
+						add(part, w, rest, false)
+					}
+				}
+			}
+			// Dividing by w means the rest made f * w.
+			if rest, ok := f.times(fraction{num: w, den: 1}); ok {
+				rest.hasNum = f.hasNum
+				add(part, w, rest, true)
+			}
+		}
+	}
+	ch := choices[pick(s.intn(sum(weights)), weights)]
+	e, key := s.factor(ch.part, ch.w)
+	return append(s.productSet(mask^ch.part, ch.rest), sampledPart{e: e, key: key, inverted: ch.divides})
+}
+
+// productSet draws one or more factors whose product is f, using every digit in mask.
+func (s sampler) productSet(mask int, f fraction) []sampledPart {
+	single := 0
+	switch {
+	case f.hasNum && f.den == 1:
+		single = s.c.factors[mask][f.num]
+	case !f.hasNum && f.num == 1:
+		single = s.c.factors[mask][f.den]
+	}
+	if s.intn(s.c.products[mask][f]) < single {
+		if f.hasNum {
+			e, key := s.factor(mask, f.num)
+			return []sampledPart{{e: e, key: key}}
+		}
+		e, key := s.factor(mask, f.den)
+		return []sampledPart{{e: e, key: key, inverted: true}}
+	}
+	return s.productParts(mask, f)
+}
+
+// sum builds the sum of parts, subtracting those which are inverted.
+func (s sampler) sum(parts []sampledPart) (expression, string) {
+	sort.Slice(parts, func(i, j int) bool { return parts[i].key < parts[j].key })
+	e := expression{Op: opAdd}
+	keys := make([]string, len(parts))
+	for i, p := range parts {
+		c, sign := p.e, "+"
+		if p.inverted {
+			c, sign = makeNegate(p.e), "-"
+		}
+		e.Val += c.Val
+		e.Children = append(e.Children, &c)
+		keys[i] = sign + p.key
+	}
+	sort.Strings(keys)
+	return e.canonicalize(), "sum(" + strings.Join(keys, " ") + ")"
+}
+
+// product builds the product of parts, dividing by those which are inverted.
+func (s sampler) product(parts []sampledPart) (expression, string) {
+	sort.Slice(parts, func(i, j int) bool { return parts[i].key < parts[j].key })
+	var nums, dens []expression
+	keys := make([]string, len(parts))
+	for i, p := range parts {
+		if p.inverted {
+			dens = append(dens, p.e)
+			keys[i] = "/" + p.key
+		} else {
+			nums = append(nums, p.e)
+			keys[i] = "*" + p.key
+		}
+	}
+	sort.Strings(keys)
+	key := "product(" + strings.Join(keys, " ") + ")"
+	if len(dens) == 0 {
+		return multiplyAll(nums), key
+	}
+	return makeDivide(multiplyAll(nums), multiplyAll(dens)), key
+}
+
+// multiplyAll returns the product of es, which is just the expression if there's only one.
+func multiplyAll(es []expression) expression {
+	if len(es) == 1 {
+		return es[0]
+	}
+	e := expression{Val: 1, Op: opMultiply}
+	for i := range es {
+		e.Val *= es[i].Val
+		e.Children = append(e.Children, &es[i])
+	}
+	return e.canonicalize()
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
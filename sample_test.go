@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func Test_solutionCounts_sample(t *testing.T) {
+	tests := map[string]struct {
+		digits []int
+		target int
+		k      int
+		want   int
+	}{
+		"some":        {digits: []int{5, 7, 9, 10, 15, 25}, target: 93, k: 10, want: 10},
+		"all":         {digits: []int{2, 3, 5, 7}, target: 24, k: 100, want: 4},
+		"no solution": {digits: []int{2, 3}, target: 100, k: 5, want: 0},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			c := countSolutions(context.Background(), tt.digits)
+			got := c.sample(rand.New(rand.NewSource(1)), tt.target, tt.k)
+			if len(got) != tt.want {
+				t.Errorf("sample() returned %d solutions, want %d", len(got), tt.want)
+			}
+			seen := make(map[string]bool)
+			for _, e := range got {
+				if val, ok := e.eval(); !ok || val != tt.target {
+					t.Errorf("sample() returned %s, which evaluates to %d, %t", e, val, ok)
+				}
+				if err := checkDigits(e, tt.digits); err != nil {
+					t.Errorf("sample() returned %s: %v", e, err)
+				}
+				key := normalForm(e)
+				if seen[key] {
+					t.Errorf("sample() returned %s twice", e)
+				}
+				seen[key] = true
+			}
+		})
+	}
+}
+
+func Test_sampler_uniform(t *testing.T) {
+	tests := map[string]struct {
+		digits        []int
+		target, draws int
+	}{
+		// There are 12 solutions, so each should come up about 1000 times.
+		"different digits": {digits: []int{2, 3, 5, 7}, target: 10, draws: 12000},
+		// There are 10 solutions, but 33 with the copies told apart.
+		"repeated digits": {digits: []int{2, 2, 3, 3}, target: 6, draws: 10000},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			c := countSolutions(context.Background(), tt.digits)
+			s := sampler{c: c, rng: rand.New(rand.NewSource(1)), termValues: make(map[int][]int), factorValues: make(map[int][]int)}
+			counts := make(map[string]int)
+			for i := 0; i < tt.draws; i++ {
+				e, _ := s.distinctSolution(tt.target)
+				counts[normalForm(e)]++
+			}
+			if len(counts) != c.totals[tt.target] {
+				t.Errorf("drew %d different solutions, want all %d", len(counts), c.totals[tt.target])
+			}
+			want := tt.draws / c.totals[tt.target]
+			for key, n := range counts {
+				if n < want*85/100 || n > want*115/100 {
+					t.Errorf("drew %s %d times, want about %d", key, n, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import "fmt"
+
+// solutionScore rates how a solution works out, so the easiest and cleverest can be picked from many.
+type solutionScore struct {
+	// Difficulty is higher the harder a solution is to work out by hand: each step costs more for a harder
+	// operation and a bigger result, and each digit used costs one more.
+	Difficulty int `json:"difficulty"`
+	// Cleverness is higher the more a solution gets from multiplying and dividing, using few steps and digits.
+	Cleverness int `json:"cleverness"`
+	// Roundness is higher the more a solution's intermediate values are round numbers, like multiples of 10, 25
+	// or 100, which is how people tend to work towards a target.
+	Roundness int `json:"roundness"`
+}
+
+// operationCost is how hard each operation is to do by hand, from adding at 1 to dividing at 4.
+func operationCost(op operation) int {
+	switch op {
+	case opAdd:
+		return 1
+	case opSubtract:
+		return 2
+	case opMultiply:
+		return 3
+	}
+	return 4
+}
+
+// decimalDigits returns how many digits v has when written out.
+func decimalDigits(v int) int {
+	n := 1
+	for v = abs(v); v >= 10; v /= 10 {
+		n++
+	}
+	return n
+}
+
+// score rates e by its number of operations, how hard they are, the size of their results and the digits it uses.
+func (e expression) score() solutionScore {
+	steps := e.steps()
+	used := len(e.digits())
+	s := solutionScore{Difficulty: used, Cleverness: -len(steps) - used}
+	for i, st := range steps {
+		s.Difficulty += operationCost(st.Op) + decimalDigits(st.Result)
+		s.Cleverness += 2 * (operationCost(st.Op) - 1)
+		// The last step makes the target, which is the same for every solution.
+		if i < len(steps)-1 {
+			s.Roundness += roundness(st.Result)
+		}
+	}
+	return s
+}
+
+// roundness rates how round v is: 3 for a multiple of 100, 2 of 25 and 1 of 10. Other values are -1,
+// so a solution doesn't gain from more steps unless they're round.
+func roundness(v int) int {
+	switch {
+	case v == 0:
+		return -1
+	case v%100 == 0:
+		return 3
+	case v%25 == 0:
+		return 2
+	case v%10 == 0:
+		return 1
+	}
+	return -1
+}
+
+// easiest returns the solution with the lowest difficulty, breaking ties like shortest.
+func easiest(solns []expression) (expression, error) {
+	return bestBy(solns, func(s solutionScore) int { return s.Difficulty })
+}
+
+// cleverest returns the solution with the highest cleverness, breaking ties like shortest.
+func cleverest(solns []expression) (expression, error) {
+	return bestBy(solns, func(s solutionScore) int { return -s.Cleverness })
+}
+
+// friendliest returns the solution with the highest roundness, breaking ties like shortest.
+func friendliest(solns []expression) (expression, error) {
+	return bestBy(solns, func(s solutionScore) int { return -s.Roundness })
+}
+
+// bestBy returns the solution whose score gives the lowest rank, breaking ties like shortest.
+func bestBy(solns []expression, rank func(solutionScore) int) (expression, error) {
+	var tied []expression
+	lowest := 0
+	for i, soln := range solns {
+		r := rank(soln.score())
+		switch {
+		case i == 0 || r < lowest:
+			lowest, tied = r, []expression{soln}
+		case r == lowest:
+			tied = append(tied, soln)
+		}
+	}
+	return shortest(tied)
+}
+
+// solutionRank chooses which solution is picked as the best.
+type solutionRank int
+
+const (
+	// rankShortest picks the solution with the fewest operations, like shortest.
+	rankShortest solutionRank = iota
+	// rankEasiest picks the solution with the lowest difficulty score.
+	rankEasiest
+	// rankCleverest picks the solution with the highest cleverness score.
+	rankCleverest
+	// rankHuman picks the solution passing through the roundest numbers, the way people tend to solve it.
+	rankHuman
+)
+
+var solutionRankNames = map[string]solutionRank{
+	"shortest":  rankShortest,
+	"easiest":   rankEasiest,
+	"cleverest": rankCleverest,
+	"human":     rankHuman,
+}
+
+func parseSolutionRank(s string) (solutionRank, error) {
+	r, ok := solutionRankNames[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown rank %q", s)
+	}
+	return r, nil
+}
+
+// best returns the best of solns by r.
+func (r solutionRank) best(solns []expression) (expression, error) {
+	switch r {
+	case rankEasiest:
+		return easiest(solns)
+	case rankCleverest:
+		return cleverest(solns)
+	case rankHuman:
+		return friendliest(solns)
+	}
+	return shortest(solns)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_expression_score(t *testing.T) {
+	tests := map[string]struct {
+		expr expression
+		want solutionScore
+	}{
+		"digit": {
+			expr: makeConstant(25),
+			want: solutionScore{Difficulty: 1, Cleverness: -1},
+		},
+		"addition": {
+			// One step costing 1, with a 2 digit result, and 2 digits used.
+			expr: makeAdd(makeConstant(15), makeConstant(5)),
+			want: solutionScore{Difficulty: 5, Cleverness: -3},
+		},
+		"division": {
+			// Multiplying costs 3 and makes 3 digits, dividing costs 4 and makes 2. 100 is as round as it gets.
+			expr: makeDivide(makeMultiply(makeConstant(25), makeConstant(4)), makeConstant(5)),
+			want: solutionScore{Difficulty: 15, Cleverness: 5, Roundness: 3},
+		},
+		"not round": {
+			expr: makeAdd(makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25)), makeConstant(5)).fuse(),
+			want: solutionScore{Difficulty: 15, Cleverness: -3, Roundness: -2},
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, tt.expr.score()); diff != "" {
+				t.Errorf("score(%s) mismatch (-want +got):\n%s", tt.expr, diff)
+			}
+		})
+	}
+}
+
+func Test_bestByScore(t *testing.T) {
+	solns := solve(93, []int{5, 7, 9, 10, 15, 25})
+	p := newPrinter(notationInfix, false)
+	e, err := easiest(solns)
+	if err != nil {
+		t.Fatalf("easiest() failed unexpectedly: %v", err)
+	}
+	if got, want := p.format(e), "9*7 + 25 + 5"; got != want {
+		t.Errorf("easiest() = %s, want %s", got, want)
+	}
+	c, err := cleverest(solns)
+	if err != nil {
+		t.Fatalf("cleverest() failed unexpectedly: %v", err)
+	}
+	if got, want := p.format(c), "10*(25*9/15 - 5) - 7"; got != want {
+		t.Errorf("cleverest() = %s, want %s", got, want)
+	}
+	h, err := solutionRank(rankHuman).best(solns)
+	if err != nil {
+		t.Fatalf("best() failed unexpectedly: %v", err)
+	}
+	if got, want := p.format(h), "(15 + 10)*5 - 25 - 7"; got != want {
+		t.Errorf("best() by human = %s, want %s", got, want)
+	}
+	if _, err := easiest(nil); err == nil {
+		t.Errorf("easiest(nil) succeeded unexpectedly")
+	}
+}
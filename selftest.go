@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+)
+
+// checkSolution reports an error if e isn't a legal way to make target from digits: it must evaluate to the value
+// it's labelled with, which must be the target, use only the digits given, work out a positive whole number at
+// each step, and read back the same when written out.
+func checkSolution(e expression, digits []int, target int) error {
+	if v, ok := e.eval(); !ok || v != e.Val {
+		return fmt.Errorf("%s is labelled %d, but evaluates to %d", e, e.Val, v)
+	}
+	_, err := verifyAnswer(formatInfix(e.withSubtraction(), opStrings), digits, target)
+	return err
+}
+
+// selfTestOptions controls the puzzles selfTest checks.
+type selfTestOptions struct {
+	// Puzzles is how many random puzzles to check.
+	Puzzles int
+	// MaxDigits is the most digits a puzzle has; each has between 1 and this many.
+	MaxDigits int
+	// OracleDigits is the most digits a puzzle can have for its solutions to be checked against oracleSolve,
+	// which is too slow for big puzzles.
+	OracleDigits int
+}
+
+// selfTestFailure is an invariant which didn't hold for a puzzle.
+type selfTestFailure struct {
+	Puzzle puzzle
+	// Check names the invariant, e.g. "search" for the search engine's solutions being legal.
+	Check string
+	Err   error
+}
+
+func (f selfTestFailure) String() string {
+	return fmt.Sprintf("%s: %s: %v", f.Puzzle, f.Check, f.Err)
+}
+
+// selfTest checks the solvers' invariants on random puzzles drawn from rng, calling done after each puzzle,
+// and returns the failures. It stops early, with what it's found so far, once s is stopped.
+func (s solver) selfTest(rng *rand.Rand, opts selfTestOptions, done func()) []selfTestFailure {
+	var failures []selfTestFailure
+	for i := 0; i < opts.Puzzles && !s.stopped(); i++ {
+		gen := generatorOptions{Count: 1 + rng.Intn(opts.MaxDigits), Large: -1, MinTarget: 1, MaxTarget: 999}
+		p := drawPuzzle(rng, gen)
+		for _, c := range s.selfTestChecks(p, len(p.Digits) <= opts.OracleDigits) {
+			if err := runCheck(c.run); err != nil {
+				failures = append(failures, selfTestFailure{Puzzle: p, Check: c.name, Err: err})
+			}
+		}
+		if done != nil {
+			done()
+		}
+	}
+	return failures
+}
+
+// selfTestCheck is one of the invariants selfTest checks.
+type selfTestCheck struct {
+	name string
+	run  func() error
+}
+
+// runCheck runs check, turning a panic into an error, as solve panics if it makes an invalid solution.
+func runCheck(check func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return check()
+}
+
+// checkSolutions reports an error for the first of solns which isn't legal, or is repeated.
+func checkSolutions(solns []expression, p puzzle) error {
+	var seen expressionSet
+	for _, e := range solns {
+		if err := checkSolution(e, p.Digits, p.Target); err != nil {
+			return err
+		}
+		if !seen.add(e) {
+			return fmt.Errorf("%s is repeated", e)
+		}
+	}
+	return nil
+}
+
+// selfTestChecks returns the checks for p. They share the solutions each engine finds, so each is only solved once.
+func (s solver) selfTestChecks(p puzzle, oracle bool) []selfTestCheck {
+	var search, splits []expression
+	checks := []selfTestCheck{
+		{"search", func() error {
+			search = s.solve(p.Target, p.Digits)
+			return checkSolutions(search, p)
+		}},
+		{"splits", func() error {
+			splits, _ = s.solveSplits(0, p.Target, p.Digits)
+			return checkSolutions(splits, p)
+		}},
+		{"search within splits", func() error {
+			if r := crosscheck(search, splits); len(r.Extra) > 0 {
+				return fmt.Errorf("search found %s, which splits didn't", r.Extra[0])
+			}
+			return nil
+		}},
+		{"count", func() error {
+			if n := s.count(p.Target, p.Digits); n != len(search) {
+				return fmt.Errorf("count() = %d, but solve() found %d", n, len(search))
+			}
+			return nil
+		}},
+		{"parallel", func() error {
+			par := s
+			par.parallelism = 4
+			if got, want := fmt.Sprint(par.solve(p.Target, p.Digits)), fmt.Sprint(search); got != want {
+				return fmt.Errorf("parallel solve() = %s, want %s", got, want)
+			}
+			return nil
+		}},
+		{"reachable", func() error {
+			if _, reached := slices.BinarySearch(reachableValues(p.Digits), p.Target); reached != (len(search) > 0) {
+				return fmt.Errorf("reachableValues() has the target %t, but solve() found %d solutions", reached, len(search))
+			}
+			return nil
+		}},
+		{"quick", func() error {
+			solns, incomplete := s.solveQuick(p.Target, p.Digits)
+			if len(solns) == 0 && !incomplete && len(splits) > 0 {
+				return fmt.Errorf("solveQuick() found nothing without giving up, but there are %d solutions", len(splits))
+			}
+			return checkSolutions(solns, p)
+		}},
+	}
+	if oracle {
+		checks = append(checks, selfTestCheck{"oracle", func() error {
+			all, _ := s.oracleSolve(p.Target, p.Digits)
+			if r := crosscheck(splits, all); !r.ok() {
+				return fmt.Errorf("splits missed %d and added %d of the %d solutions found by brute force", len(r.Missed), len(r.Extra), len(all))
+			}
+			return nil
+		}})
+	}
+	return checks
+}
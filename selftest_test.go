@@ -0,0 +1,62 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func Test_checkSolution(t *testing.T) {
+	digits := []int{5, 7, 9, 25, 95}
+	tests := map[string]struct {
+		expr    expression
+		wantErr bool
+	}{
+		"legal": {
+			expr: makeAdd(makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25)), makeConstant(5)).fuse(),
+		},
+		"wrong target": {
+			expr:    makeAdd(makeConstant(9), makeConstant(7)),
+			wantErr: true,
+		},
+		"mislabelled": {
+			expr:    expression{Val: 93, Op: opAdd, Children: []*expression{ptr(makeConstant(25)), ptr(makeConstant(9))}},
+			wantErr: true,
+		},
+		"digit not given": {
+			expr:    makeAdd(makeMultiply(makeConstant(9), makeConstant(10)), makeConstant(3)),
+			wantErr: true,
+		},
+		"negative step": {
+			expr:    makeAdd(makeAdd(makeConstant(5), makeNegate(makeConstant(7))), makeConstant(95)),
+			wantErr: true,
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			if err := checkSolution(tt.expr, digits, 93); (err != nil) != tt.wantErr {
+				t.Errorf("checkSolution(%s) = %v, want error %t", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func ptr(e expression) *expression {
+	return &e
+}
+
+func Test_solver_selfTest(t *testing.T) {
+	opts := selfTestOptions{Puzzles: 30, MaxDigits: 5, OracleDigits: 4}
+	done := 0
+	for _, f := range (solver{}).selfTest(rand.New(rand.NewSource(1)), opts, func() { done++ }) {
+		t.Errorf("selfTest() failed: %s", f)
+	}
+	if done != opts.Puzzles {
+		t.Errorf("selfTest() checked %d puzzles, want %d", done, opts.Puzzles)
+	}
+}
+
+func Test_runCheck(t *testing.T) {
+	if err := runCheck(func() error { panic("generated invalid solution") }); err == nil {
+		t.Errorf("runCheck() of a panic succeeded unexpectedly")
+	}
+}
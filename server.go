@@ -0,0 +1,394 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// maxRequestBytes bounds the size of a request body the server reads.
+const maxRequestBytes = 1 << 20
+
+// serverStreamMemory is how many recent solutions a streamed solve remembers to remove duplicates, like solve's --stream_memory.
+const serverStreamMemory = 100000
+
+// server answers the HTTP JSON API, solving each request on its own.
+type server struct {
+	// timeout and maxNodes, if positive, bound each search, so one request can't hold the server up.
+	timeout  time.Duration
+	maxNodes int64
+	// config holds the presets generate requests can name.
+	config config
+	log    *slog.Logger
+}
+
+// solveRequest is the body of POST /v1/solve. Only the digits and target are required, and the rest
+// default to solve's defaults.
+type solveRequest struct {
+	Digits []int `json:"digits"`
+	Target int   `json:"target"`
+	// Engine, Notation and Sort take the same names as solve's --engine, --notation and --sort.
+	Engine     string `json:"engine,omitempty"`
+	Notation   string `json:"notation,omitempty"`
+	Unicode    bool   `json:"unicode,omitempty"`
+	Sort       string `json:"sort,omitempty"`
+	MaxResults int    `json:"max_results,omitempty"`
+	// The filters are like solve's flags of the same names.
+	NoDivision    bool `json:"no_division,omitempty"`
+	NoNegation    bool `json:"no_negation,omitempty"`
+	NoTrivial     bool `json:"no_trivial,omitempty"`
+	MaxOps        int  `json:"max_ops,omitempty"`
+	MaxDigitsUsed int  `json:"max_digits_used,omitempty"`
+}
+
+// solveResponse is the reply to POST /v1/solve.
+type solveResponse struct {
+	Target int   `json:"target"`
+	Digits []int `json:"digits"`
+	Solved bool  `json:"solved"`
+	Count  int   `json:"count"`
+	// Incomplete reports whether the search stopped early, so there may be more solutions.
+	Incomplete bool           `json:"incomplete,omitempty"`
+	Solutions  []solutionJSON `json:"solutions"`
+	// Shortest, Easiest and Cleverest are left out if there's no solution.
+	Shortest  *solutionJSON `json:"shortest,omitempty"`
+	Easiest   *solutionJSON `json:"easiest,omitempty"`
+	Cleverest *solutionJSON `json:"cleverest,omitempty"`
+}
+
+// solutionJSON is a solution as the API writes it.
+type solutionJSON struct {
+	Text   string `json:"text"`
+	Value  int    `json:"value"`
+	Ops    int    `json:"ops"`
+	Digits []int  `json:"digits"`
+	// Steps are the operations in the order a person would do them, like "9 * 7 = 63".
+	Steps []string      `json:"steps"`
+	Score solutionScore `json:"score"`
+}
+
+func makeSolutionJSON(s solution, p printer) *solutionJSON {
+	j := &solutionJSON{Text: s.Text, Value: s.Value, Ops: s.Ops, Digits: s.Digits, Steps: make([]string, len(s.Steps)), Score: s.Score}
+	for i, st := range s.Steps {
+		j.Steps[i] = st.format(p.symbols)
+	}
+	return j
+}
+
+// generateRequest is the body of POST /v1/generate. Every field is optional: those left out are taken from
+// the preset, if there is one, and then default to generate's defaults.
+type generateRequest struct {
+	// Preset names a [presets.<name>] table in the server's config file, whose large, min_rating and max_rating apply.
+	Preset string `json:"preset,omitempty"`
+	// Difficulty, if given, asks for a puzzle with exactly this rating, from 1 to 5, instead of a range of ratings.
+	Difficulty int `json:"difficulty,omitempty"`
+	// Large, MinRating and MaxRating are like generate's flags of the same names.
+	Large     *int `json:"large,omitempty"`
+	MinRating *int `json:"min_rating,omitempty"`
+	MaxRating *int `json:"max_rating,omitempty"`
+	// Seed, if nonzero, makes the same puzzle every time.
+	Seed int64 `json:"seed,omitempty"`
+}
+
+// generateResponse is the reply to POST /v1/generate: a puzzle which has been checked to be solvable.
+type generateResponse struct {
+	Digits []int        `json:"digits"`
+	Target int          `json:"target"`
+	Rating puzzleRating `json:"rating"`
+	// Solution is the shortest solution, for revealing the answer, and Hints lead up to it, each giving away
+	// more than the last, like digits hint's.
+	Solution solutionJSON `json:"solution"`
+	Hints    []string     `json:"hints"`
+}
+
+// verifyRequest is the body of POST /v1/verify: a puzzle, and an answer to it written like "(9*7) + 25 + 5".
+type verifyRequest struct {
+	Digits []int  `json:"digits"`
+	Target int    `json:"target"`
+	Answer string `json:"answer"`
+}
+
+// verifyResponse is the reply to POST /v1/verify. An answer which can't be parsed isn't valid, with the
+// reason as its only violation.
+type verifyResponse struct {
+	Valid bool `json:"valid"`
+	// Value is what the answer works out to, which is left out if it can't be, like when it divides by zero.
+	Value *int `json:"value,omitempty"`
+	// Violations are the rules the answer breaks, like "digit 9 used twice", and are empty if it's valid.
+	Violations []string `json:"violations"`
+}
+
+// errorResponse is the reply to a request which failed.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (srv *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/solve", srv.handleSolve)
+	mux.HandleFunc("/v1/generate", srv.handleGenerate)
+	mux.HandleFunc("/v1/verify", srv.handleVerify)
+	mux.HandleFunc("/v1/solve/stream", srv.handleSolveStream)
+	mux.Handle("/", webHandler())
+	return mux
+}
+
+// writeJSON writes v as the response with status.
+func (srv *server) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		srv.log.Warn("Failed to write response", "error", err)
+	}
+}
+
+func (srv *server) writeError(w http.ResponseWriter, status int, format string, args ...any) {
+	srv.writeJSON(w, status, errorResponse{Error: fmt.Sprintf(format, args...)})
+}
+
+// readJSON decodes the body of r into v, writing an error response and returning false if it can't.
+func (srv *server) readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		srv.writeError(w, http.StatusMethodNotAllowed, "want POST, got %s", r.Method)
+		return false
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			srv.writeError(w, http.StatusRequestEntityTooLarge, "request is bigger than %d bytes", maxRequestBytes)
+			return false
+		}
+		srv.writeError(w, http.StatusBadRequest, "invalid request: %v", err)
+		return false
+	}
+	return true
+}
+
+func (srv *server) handleSolve(w http.ResponseWriter, r *http.Request) {
+	var req solveRequest
+	if !srv.readJSON(w, r, &req) {
+		return
+	}
+	resp, err := srv.solve(r, req)
+	if err != nil {
+		srv.writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	srv.writeJSON(w, http.StatusOK, resp)
+}
+
+// checkPuzzle returns the puzzle making target from digits, or an error if it's invalid or too big to solve.
+func checkPuzzle(digits []int, target int) (puzzle, error) {
+	pz := puzzle{Digits: digits, Target: target}
+	if err := pz.validate(); err != nil {
+		return puzzle{}, err
+	}
+	return pz, nil
+}
+
+// solve answers req, stopping early if the client goes away.
+func (srv *server) solve(r *http.Request, req solveRequest) (solveResponse, error) {
+	pz, err := checkPuzzle(req.Digits, req.Target)
+	if err != nil {
+		return solveResponse{}, err
+	}
+	if req.MaxResults < 0 {
+		return solveResponse{}, fmt.Errorf("max_results must not be negative, got %d", req.MaxResults)
+	}
+	filter := solutionFilter{noDivision: req.NoDivision, noNegation: req.NoNegation, noTrivial: req.NoTrivial, maxOps: req.MaxOps, maxDigits: req.MaxDigitsUsed}
+	if err := filter.validate(); err != nil {
+		return solveResponse{}, err
+	}
+	engine, err := parseEngine(withDefault(req.Engine, "search"))
+	if err != nil {
+		return solveResponse{}, err
+	}
+	n, err := parseNotation(withDefault(req.Notation, "infix"))
+	if err != nil {
+		return solveResponse{}, err
+	}
+	order, err := parseSolutionOrder(withDefault(req.Sort, "found"))
+	if err != nil {
+		return solveResponse{}, err
+	}
+	p := newPrinter(n, req.Unicode)
+
+	s := solver{limit: req.MaxResults, filter: filter, ctx: r.Context(), log: srv.log}
+	if s, err = s.withEngine(engine, srv.timeout, pz.Digits); err != nil {
+		return solveResponse{}, err
+	}
+	s.budget = newNodeBudget(srv.maxNodes)
+	solns, incomplete := s.solveWithin(srv.timeout, pz.Target, pz.Digits)
+	solns = sortSolutions(solns, order, p)
+
+	sr := makeSolveResult(pz.Target, pz.Digits, solns, p)
+	resp := solveResponse{
+		Target:     pz.Target,
+		Digits:     pz.Digits,
+		Solved:     sr.Solved,
+		Count:      sr.Count,
+		Incomplete: incomplete,
+		Solutions:  make([]solutionJSON, len(sr.Solutions)),
+	}
+	for i, soln := range sr.Solutions {
+		resp.Solutions[i] = *makeSolutionJSON(soln, p)
+	}
+	if sr.Solved {
+		resp.Shortest, resp.Easiest, resp.Cleverest = makeSolutionJSON(sr.Shortest, p), makeSolutionJSON(sr.Easiest, p), makeSolutionJSON(sr.Cleverest, p)
+	}
+	srv.log.Info("Solved", "digits", formatDigits(pz.Digits), "target", pz.Target, "solutions", len(solns), "incomplete", incomplete)
+	return resp, nil
+}
+
+func (srv *server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	var req generateRequest
+	if !srv.readJSON(w, r, &req) {
+		return
+	}
+	opts, err := srv.generatorOptions(req)
+	if err != nil {
+		srv.writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	resp, err := srv.generate(r.Context(), newRand(req.Seed), opts)
+	if err != nil {
+		srv.writeError(w, generateErrorStatus(err), "failed to generate a puzzle: %v", err)
+		return
+	}
+	srv.writeJSON(w, http.StatusOK, resp)
+}
+
+// generateErrorStatus returns the status for a failure to generate a puzzle: running out of nodes or
+// time means the server is too busy to find one, rather than that anything went wrong.
+func generateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errBudgetExhausted):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// generatorOptions returns the options req asks for, applying its preset's options first.
+func (srv *server) generatorOptions(req generateRequest) (generatorOptions, error) {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	opts := addGeneratorFlags(fs)
+	if req.Preset != "" {
+		if err := srv.config.applyPreset(fs, map[string]bool{}, req.Preset); err != nil {
+			return generatorOptions{}, err
+		}
+	}
+	if req.Large != nil {
+		opts.Large = *req.Large
+	}
+	if req.MinRating != nil {
+		opts.MinRating = *req.MinRating
+	}
+	if req.MaxRating != nil {
+		opts.MaxRating = *req.MaxRating
+	}
+	if req.Difficulty != 0 {
+		if req.MinRating != nil || req.MaxRating != nil {
+			return generatorOptions{}, fmt.Errorf("difficulty can't be given with min_rating or max_rating")
+		}
+		if req.Difficulty < 1 || req.Difficulty > 5 {
+			return generatorOptions{}, fmt.Errorf("difficulty must be from 1 to 5, got %d", req.Difficulty)
+		}
+		opts.MinRating, opts.MaxRating = req.Difficulty, req.Difficulty
+	}
+	return *opts, opts.validate()
+}
+
+// generate draws a puzzle with opts, and checks its shortest solution and rating before returning it,
+// so a client is never given a puzzle it can't solve.
+// Generating is bounded by the server's timeout and node budget, which are shared between the puzzles it draws.
+func (srv *server) generate(ctx context.Context, rng *rand.Rand, opts generatorOptions) (generateResponse, error) {
+	if srv.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, srv.timeout)
+		defer cancel()
+	}
+	budget := newNodeBudget(srv.maxNodes)
+	g, err := generatePuzzle(ctx, rng, opts, budget)
+	if err != nil {
+		return generateResponse{}, err
+	}
+	pz := g.Puzzle
+	soln, err := shortest(g.Solutions)
+	if err != nil {
+		return generateResponse{}, err
+	}
+	if err := checkSolution(soln, pz.Digits, pz.Target); err != nil {
+		return generateResponse{}, fmt.Errorf("%v: %v", pz, err)
+	}
+	// The puzzle was only rated if the options asked for a rating.
+	if g.Rating == nil {
+		r, err := ratePuzzle(ctx, budget, pz)
+		if err != nil {
+			return generateResponse{}, fmt.Errorf("%v: %w", pz, err)
+		}
+		g.Rating = &r
+	}
+	rating := *g.Rating
+	p := newPrinter(notationInfix, false)
+	srv.log.Info("Generated", "digits", formatDigits(pz.Digits), "target", pz.Target, "rating", rating.Rating)
+	return generateResponse{
+		Digits:   pz.Digits,
+		Target:   pz.Target,
+		Rating:   rating,
+		Solution: *makeSolutionJSON(makeSolution(soln, p), p),
+		Hints:    hints(soln, p.symbols),
+	}, nil
+}
+
+func (srv *server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	var req verifyRequest
+	if !srv.readJSON(w, r, &req) {
+		return
+	}
+	resp, err := srv.verify(req)
+	if err != nil {
+		srv.writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	srv.writeJSON(w, http.StatusOK, resp)
+}
+
+// verify checks req's answer, returning an error only if the puzzle itself is invalid.
+func (srv *server) verify(req verifyRequest) (verifyResponse, error) {
+	pz := puzzle{Digits: req.Digits, Target: req.Target}
+	if err := pz.validate(); err != nil {
+		return verifyResponse{}, err
+	}
+	e, err := parseInfix(req.Answer)
+	if err != nil {
+		return verifyResponse{Violations: []string{err.Error()}}, nil
+	}
+	resp := verifyResponse{Violations: answerViolations(e, pz.Digits, pz.Target)}
+	if v, ok := e.eval(); ok {
+		resp.Value = &v
+	}
+	if resp.Violations == nil {
+		resp.Valid, resp.Violations = true, []string{}
+	}
+	srv.log.Info("Verified", "digits", formatDigits(pz.Digits), "target", pz.Target, "valid", resp.Valid)
+	return resp, nil
+}
+
+// withDefault returns s, or def if s is empty.
+func withDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_server_solve(t *testing.T) {
+	srv := &server{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	ts := httptest.NewServer(srv.handler())
+	defer ts.Close()
+
+	tests := map[string]struct {
+		method, body string
+		wantStatus   int
+		// wantTexts are the solutions' texts, and wantError the error, whichever is expected.
+		wantTexts []string
+		wantError string
+	}{
+		"solved": {
+			method:     http.MethodPost,
+			body:       `{"digits": [2, 3, 7], "target": 13}`,
+			wantStatus: http.StatusOK,
+			wantTexts:  []string{"7 + 3*2"},
+		},
+		"options": {
+			method:     http.MethodPost,
+			body:       `{"digits": [2, 3, 7], "target": 1, "notation": "rpn", "no_division": true}`,
+			wantStatus: http.StatusOK,
+			wantTexts:  []string{"3 2 -", "7 3 2 * -"},
+		},
+		"no solution": {
+			method:     http.MethodPost,
+			body:       `{"digits": [2, 3], "target": 100}`,
+			wantStatus: http.StatusOK,
+			wantTexts:  []string{},
+		},
+		"invalid target": {
+			method:     http.MethodPost,
+			body:       `{"digits": [2, 3], "target": 0}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "target must be positive, got 0",
+		},
+		"too many digits": {
+			method:     http.MethodPost,
+			body:       `{"digits": [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11], "target": 5}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "too many digits: got 11, but at most 10 can be searched",
+		},
+		"unknown engine": {
+			method:     http.MethodPost,
+			body:       `{"digits": [2, 3], "target": 5, "engine": "magic"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  `unknown engine "magic"`,
+		},
+		"unknown field": {
+			method:     http.MethodPost,
+			body:       `{"digit": [2, 3], "target": 5}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  `invalid request: json: unknown field "digit"`,
+		},
+		"GET": {
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantError:  "want POST, got GET",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, ts.URL+"/v1/solve", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("NewRequest() failed unexpectedly: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Do() failed unexpectedly: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantError != "" {
+				var got errorResponse
+				if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+					t.Fatalf("Decode() failed unexpectedly: %v", err)
+				}
+				if diff := cmp.Diff(tt.wantError, got.Error); diff != "" {
+					t.Errorf("error mismatch (-want +got):\n%s", diff)
+				}
+				return
+			}
+			var got solveResponse
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("Decode() failed unexpectedly: %v", err)
+			}
+			texts := []string{}
+			for _, s := range got.Solutions {
+				texts = append(texts, s.Text)
+			}
+			if diff := cmp.Diff(tt.wantTexts, texts); diff != "" {
+				t.Errorf("solutions mismatch (-want +got):\n%s", diff)
+			}
+			if got.Solved != (len(tt.wantTexts) > 0) || got.Count != len(tt.wantTexts) || (got.Shortest == nil) == got.Solved {
+				t.Errorf("solved = %v, count = %d, shortest = %v, want them to agree with %d solutions", got.Solved, got.Count, got.Shortest, len(tt.wantTexts))
+			}
+		})
+	}
+}
+
+func Test_server_generate(t *testing.T) {
+	c, err := parseConfig("[presets.easy]\nlarge = 0\nmax_rating = 2\ncount = 3\n")
+	if err != nil {
+		t.Fatalf("parseConfig() failed unexpectedly: %v", err)
+	}
+	srv := &server{config: c, log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	ts := httptest.NewServer(srv.handler())
+	defer ts.Close()
+
+	tests := map[string]struct {
+		body       string
+		wantStatus int
+		// wantLarge and wantRatings bound the puzzle, or wantError is the error expected.
+		wantLarge            int
+		minRating, maxRating int
+		wantError            string
+	}{
+		"defaults": {
+			body:       `{"seed": 1}`,
+			wantStatus: http.StatusOK,
+			wantLarge:  -1,
+			minRating:  1,
+			maxRating:  5,
+		},
+		"difficulty": {
+			body:       `{"seed": 2, "difficulty": 3, "large": 1}`,
+			wantStatus: http.StatusOK,
+			wantLarge:  1,
+			minRating:  3,
+			maxRating:  3,
+		},
+		"preset": {
+			body:       `{"seed": 3, "preset": "easy"}`,
+			wantStatus: http.StatusOK,
+			wantLarge:  0,
+			minRating:  1,
+			maxRating:  2,
+		},
+		"preset overridden": {
+			body:       `{"seed": 4, "preset": "easy", "large": 2}`,
+			wantStatus: http.StatusOK,
+			wantLarge:  2,
+			minRating:  1,
+			maxRating:  2,
+		},
+		"unknown preset": {
+			body:       `{"preset": "nightmare"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  `unknown preset "nightmare"`,
+		},
+		"difficulty and rating": {
+			body:       `{"difficulty": 2, "max_rating": 3}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "difficulty can't be given with min_rating or max_rating",
+		},
+		"invalid difficulty": {
+			body:       `{"difficulty": 6}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "difficulty must be from 1 to 5, got 6",
+		},
+		"too many large": {
+			body:       `{"large": 5}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "can't draw 5 large numbers for 6 digits from [25 50 75 100]",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			resp, err := http.Post(ts.URL+"/v1/generate", "application/json", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("Post() failed unexpectedly: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantError != "" {
+				var got errorResponse
+				if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+					t.Fatalf("Decode() failed unexpectedly: %v", err)
+				}
+				if diff := cmp.Diff(tt.wantError, got.Error); diff != "" {
+					t.Errorf("error mismatch (-want +got):\n%s", diff)
+				}
+				return
+			}
+			var got generateResponse
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("Decode() failed unexpectedly: %v", err)
+			}
+			if len(got.Digits) != defaultGeneratorOptions.Count {
+				t.Errorf("digits = %v, want %d of them", got.Digits, defaultGeneratorOptions.Count)
+			}
+			if large := len(got.Digits) - len(poolSmall(got.Digits)); tt.wantLarge >= 0 && large != tt.wantLarge {
+				t.Errorf("digits = %v, want %d large numbers", got.Digits, tt.wantLarge)
+			}
+			if got.Rating.Rating < tt.minRating || got.Rating.Rating > tt.maxRating {
+				t.Errorf("rating = %d, want %d to %d", got.Rating.Rating, tt.minRating, tt.maxRating)
+			}
+			if got.Solution.Value != got.Target {
+				t.Errorf("solution %q = %d, want the target %d", got.Solution.Text, got.Solution.Value, got.Target)
+			}
+			if n := len(got.Hints); n == 0 || !strings.HasSuffix(got.Hints[n-1], got.Solution.Text) {
+				t.Errorf("hints = %q, want them to end with the solution %q", got.Hints, got.Solution.Text)
+			}
+		})
+	}
+}
+
+// poolSmall returns the digits which aren't large numbers.
+func poolSmall(digits []int) []int {
+	var small []int
+	for _, d := range digits {
+		if !slices.Contains(largeNumbers, d) {
+			small = append(small, d)
+		}
+	}
+	return small
+}
+
+func Test_server_verify(t *testing.T) {
+	srv := &server{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	ts := httptest.NewServer(srv.handler())
+	defer ts.Close()
+
+	value := func(v int) *int { return &v }
+	tests := map[string]struct {
+		body       string
+		wantStatus int
+		want       verifyResponse
+		wantError  string
+	}{
+		"valid": {
+			body:       `{"digits": [5, 7, 9, 25], "target": 93, "answer": "(9*7) + 25 + 5"}`,
+			wantStatus: http.StatusOK,
+			want:       verifyResponse{Valid: true, Value: value(93), Violations: []string{}},
+		},
+		"violations": {
+			body:       `{"digits": [5, 7, 9, 25], "target": 93, "answer": "9*9 + 7"}`,
+			wantStatus: http.StatusOK,
+			want:       verifyResponse{Value: value(88), Violations: []string{"digit 9 used twice", "9*9 + 7 = 88, not 93"}},
+		},
+		"no value": {
+			body:       `{"digits": [5, 7, 9, 25], "target": 93, "answer": "25/(5 - 5)"}`,
+			wantStatus: http.StatusOK,
+			want:       verifyResponse{Violations: []string{"digit 5 used twice", "non-positive result at step 1: 5 - 5 = 0"}},
+		},
+		"syntax error": {
+			body:       `{"digits": [5, 7, 9, 25], "target": 93, "answer": "9*7 +"}`,
+			wantStatus: http.StatusOK,
+			want:       verifyResponse{Violations: []string{"unexpected end of expression"}},
+		},
+		"invalid puzzle": {
+			body:       `{"digits": [], "target": 93, "answer": "93"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "no digits",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			resp, err := http.Post(ts.URL+"/v1/verify", "application/json", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("Post() failed unexpectedly: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantError != "" {
+				var got errorResponse
+				if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+					t.Fatalf("Decode() failed unexpectedly: %v", err)
+				}
+				if diff := cmp.Diff(tt.wantError, got.Error); diff != "" {
+					t.Errorf("error mismatch (-want +got):\n%s", diff)
+				}
+				return
+			}
+			var got verifyResponse
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("Decode() failed unexpectedly: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("response mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_server_generate_limits(t *testing.T) {
+	tests := map[string]struct {
+		srv        *server
+		wantStatus int
+	}{
+		"node budget": {
+			srv:        &server{maxNodes: 100},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		"timeout": {
+			srv:        &server{timeout: time.Nanosecond},
+			wantStatus: http.StatusGatewayTimeout,
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			tt.srv.log = slog.New(slog.NewTextHandler(io.Discard, nil))
+			ts := httptest.NewServer(tt.srv.handler())
+			defer ts.Close()
+			resp, err := http.Post(ts.URL+"/v1/generate", "application/json", strings.NewReader(`{"seed": 1, "difficulty": 5}`))
+			if err != nil {
+				t.Fatalf("Post() failed unexpectedly: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
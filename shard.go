@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// parseShard parses a shard like "2/5": the second of five.
+func parseShard(s string) (k, n int, err error) {
+	kStr, nStr, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("want K/N, got %q", s)
+	}
+	if k, err = strconv.Atoi(kStr); err != nil {
+		return 0, 0, fmt.Errorf("invalid shard number %q", kStr)
+	}
+	if n, err = strconv.Atoi(nStr); err != nil {
+		return 0, 0, fmt.Errorf("invalid shard count %q", nStr)
+	}
+	if n < 1 || k < 1 || k > n {
+		return 0, 0, fmt.Errorf("want 1 <= K <= N, got %d/%d", k, n)
+	}
+	return k, n, nil
+}
+
+// shardRange returns the targets of the kth of n shards of min..max. The shards are contiguous and as
+// close to the same size as they can be, so some may be empty, with to less than from, if n is bigger than the range.
+func shardRange(min, max, k, n int) (from, to int) {
+	size := max - min + 1
+	return min + (k-1)*size/n, min + k*size/n - 1
+}
+
+// mergeShards combines the results of every shard of a range run into one, in target order.
+// It reports an error if the shards aren't all from the same run, or any are missing or unfinished.
+func mergeShards(shards []rangeCheckpoint) (rangeCheckpoint, error) {
+	if len(shards) == 0 {
+		return rangeCheckpoint{}, fmt.Errorf("no shards")
+	}
+	first := shards[0]
+	if first.Shards == 0 {
+		return rangeCheckpoint{}, fmt.Errorf("results for %d-%d aren't from a sharded run", first.Min, first.Max)
+	}
+	byShard := make(map[int]rangeCheckpoint)
+	for _, c := range shards {
+		if err := c.matches(first); err != nil {
+			return rangeCheckpoint{}, fmt.Errorf("shard %d/%d isn't from the same run as shard %d/%d: %v", c.Shard, c.Shards, first.Shard, first.Shards, err)
+		}
+		if _, ok := byShard[c.Shard]; ok {
+			return rangeCheckpoint{}, fmt.Errorf("shard %d/%d given twice", c.Shard, c.Shards)
+		}
+		byShard[c.Shard] = c
+	}
+
+	merged := rangeCheckpoint{Digits: first.Digits, Min: first.Min, Max: first.Max, Options: first.Options, Results: []checkpointResult{}}
+	var missing []int
+	for k := 1; k <= first.Shards; k++ {
+		c, ok := byShard[k]
+		if !ok {
+			missing = append(missing, k)
+			continue
+		}
+		from, to := shardRange(first.Min, first.Max, k, first.Shards)
+		if len(c.Results) != max(to-from+1, 0) {
+			return rangeCheckpoint{}, fmt.Errorf("shard %d/%d has %d of its %d targets; resume it to finish", k, c.Shards, len(c.Results), to-from+1)
+		}
+		for i, r := range c.Results {
+			if r.Target != from+i {
+				return rangeCheckpoint{}, fmt.Errorf("shard %d/%d has target %d where %d should be", k, c.Shards, r.Target, from+i)
+			}
+		}
+		merged.Results = append(merged.Results, c.Results...)
+	}
+	if len(missing) > 0 {
+		sort.Ints(missing)
+		return rangeCheckpoint{}, fmt.Errorf("missing shards %s of %d", formatRuns(missing), first.Shards)
+	}
+	return merged, nil
+}
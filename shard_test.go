@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseShard(t *testing.T) {
+	tests := map[string]struct {
+		s       string
+		wantK   int
+		wantN   int
+		wantErr bool
+	}{
+		"first":      {s: "1/4", wantK: 1, wantN: 4},
+		"last":       {s: "4/4", wantK: 4, wantN: 4},
+		"only":       {s: "1/1", wantK: 1, wantN: 1},
+		"zero":       {s: "0/4", wantErr: true},
+		"past last":  {s: "5/4", wantErr: true},
+		"no slash":   {s: "4", wantErr: true},
+		"not number": {s: "a/4", wantErr: true},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			k, n, err := parseShard(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseShard(%q) error = %v, wantErr %t", tt.s, err, tt.wantErr)
+			}
+			if k != tt.wantK || n != tt.wantN {
+				t.Errorf("parseShard(%q) = %d, %d; want %d, %d", tt.s, k, n, tt.wantK, tt.wantN)
+			}
+		})
+	}
+}
+
+func Test_shardRange(t *testing.T) {
+	// Every target is in exactly one shard, whatever the number of shards.
+	for n := 1; n <= 12; n++ {
+		next := 100
+		for k := 1; k <= n; k++ {
+			from, to := shardRange(100, 109, k, n)
+			if from != next {
+				t.Errorf("shardRange(100, 109, %d, %d) starts at %d, want %d", k, n, from, next)
+			}
+			next = to + 1
+		}
+		if next != 110 {
+			t.Errorf("shards of %d end at %d, want 109", n, next-1)
+		}
+	}
+}
+
+func Test_mergeShards(t *testing.T) {
+	shard := func(k, n int) rangeCheckpoint {
+		c := rangeCheckpoint{Digits: []int{2, 3, 5}, Min: 1, Max: 10, Options: rangeOptions("search", solutionFilter{}, 0, 0, "found"), Shard: k, Shards: n}
+		from, to := shardRange(1, 10, k, n)
+		for t := from; t <= to; t++ {
+			c.Results = append(c.Results, checkpointResult{Target: t, Solutions: []string{}})
+		}
+		return c
+	}
+	unfinished := shard(2, 3)
+	unfinished.Results = unfinished.Results[:1]
+	otherDigits := shard(2, 3)
+	otherDigits.Digits = []int{2, 3, 7}
+	otherEngine := shard(2, 3)
+	otherEngine.Options = rangeOptions("subsets", solutionFilter{}, 0, 0, "found")
+
+	tests := map[string]struct {
+		shards  []rangeCheckpoint
+		wantErr bool
+	}{
+		"in order":     {shards: []rangeCheckpoint{shard(1, 3), shard(2, 3), shard(3, 3)}},
+		"out of order": {shards: []rangeCheckpoint{shard(3, 3), shard(1, 3), shard(2, 3)}},
+		"one shard":    {shards: []rangeCheckpoint{shard(1, 1)}},
+		"missing":      {shards: []rangeCheckpoint{shard(1, 3), shard(3, 3)}, wantErr: true},
+		"repeated":     {shards: []rangeCheckpoint{shard(1, 3), shard(1, 3), shard(2, 3), shard(3, 3)}, wantErr: true},
+		"unfinished":   {shards: []rangeCheckpoint{shard(1, 3), unfinished, shard(3, 3)}, wantErr: true},
+		"other digits": {shards: []rangeCheckpoint{shard(1, 3), otherDigits, shard(3, 3)}, wantErr: true},
+		"other engine": {shards: []rangeCheckpoint{shard(1, 3), otherEngine, shard(3, 3)}, wantErr: true},
+		"other counts": {shards: []rangeCheckpoint{shard(1, 2), shard(2, 3), shard(3, 3)}, wantErr: true},
+		"not sharded":  {shards: []rangeCheckpoint{{Digits: []int{2, 3, 5}, Min: 1, Max: 10}}, wantErr: true},
+		"none":         {wantErr: true},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got, err := mergeShards(tt.shards)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mergeShards() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var targets []int
+			for _, r := range got.Results {
+				targets = append(targets, r.Target)
+			}
+			if diff := cmp.Diff([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, targets); diff != "" {
+				t.Errorf("mergeShards() targets mismatch (-want +got):\n%s", diff)
+			}
+			if got.Shards != 0 || got.Min != 1 || got.Max != 10 {
+				t.Errorf("mergeShards() = shard %d/%d of %d-%d, want all of 1-10", got.Shard, got.Shards, got.Min, got.Max)
+			}
+		})
+	}
+}
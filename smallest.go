@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// solveSmallest finds the solutions for target which work out the smallest values along the way, which are the
+// easiest to do in your head. It searches with a bound on the values sub-searches may make, narrowing it down to
+// the smallest which leaves any solutions, and returns those.
+func (s solver) solveSmallest(target int, digits []int) []expression {
+	if len(digits) == 0 {
+		return nil
+	}
+	pool, _ := sortDigits(digits)
+	largest := largestValues(pool)
+	// Any solution is within the biggest value the digits make, and none can be within less than the target.
+	lo, hi := target, largest[len(largest)-1]
+	if hi < lo {
+		return nil
+	}
+	var best []expression
+	for lo <= hi {
+		if s.stopped() {
+			return best
+		}
+		bound := lo + (hi-lo)/2
+		s.maxIntermediate = bound
+		if solns := s.solve(target, digits); len(solns) > 0 {
+			best, hi = solns, bound-1
+		} else {
+			lo = bound + 1
+		}
+	}
+	s.debug("Found the smallest bound on intermediate values", "target", target, "bound", lo, "found", len(best))
+	return best
+}
+
+// solveSmallestWithin is solveSmallest bounded by timeout, if it's positive, like solveWithin.
+func (s solver) solveSmallestWithin(timeout time.Duration, target int, digits []int) ([]expression, bool) {
+	if timeout > 0 {
+		parent := s.ctx
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+		s.ctx = ctx
+	}
+	solns := s.solveSmallest(target, digits)
+	return solns, s.stopped()
+}
+
+// smallestIntermediate returns up to n of solns with the smallest largest intermediate value, smallest first,
+// breaking ties like shortest.
+func smallestIntermediate(solns []expression, n int) []expression {
+	sorted := sortSolutions(shortestN(solns, len(solns)), orderIntermediate, printer{})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+)
+
+func Test_solver_solveSmallest(t *testing.T) {
+	tests := map[string]struct {
+		digits []int
+		target int
+		want   int
+	}{
+		"many solutions":  {digits: []int{5, 7, 9, 10, 15, 25}, target: 93, want: 93},
+		"overshoot":       {digits: []int{3, 6, 25, 50, 75, 100}, target: 952, want: 23850},
+		"digit":           {digits: []int{3, 6, 25}, target: 25, want: 0},
+		"repeated digits": {digits: []int{2, 2, 3, 3}, target: 12, want: 12},
+		"no solution":     {digits: []int{2, 3}, target: 100},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := smallestIntermediate(solver{}.solveSmallest(tt.target, tt.digits), 1)
+			// Searching every solution should find none smaller.
+			all := smallestIntermediate(solve(tt.target, tt.digits), 1)
+			if len(got) != len(all) {
+				t.Fatalf("solveSmallest() found %v, want %v", got, all)
+			}
+			if len(got) == 0 {
+				return
+			}
+			if m := got[0].maxIntermediate(); m != tt.want || m != all[0].maxIntermediate() {
+				t.Errorf("solveSmallest() = %s, working out up to %d, want up to %d like %s", got[0], m, tt.want, all[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// splitSearch finds every solution by splitting the digits into two groups in every way, and joining
+// the values each group can make across the target equation: a + b = target means b = target - a, and so on.
+// Which values each group can make is looked up in a subsetSolutions table, so only the joins which
+// work are followed. This finds solutions which solver's search can't, like (a + b) * (c + d).
+type splitSearch struct {
+	s      solver
+	digits []int
+	table  *subsetSolutions
+	// memo caches the solutions for each group of digits and value, by mask and value.
+	memo map[[2]int][]expression
+}
+
+// solveSplits finds the solutions for target by joining splits of digits, within timeout if it's positive.
+// It also reports whether the search was cancelled or timed out, in which case the solutions may be incomplete.
+func (s solver) solveSplits(timeout time.Duration, target int, digits []int) ([]expression, bool) {
+	if timeout > 0 {
+		parent := s.ctx
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+		s.ctx = ctx
+	}
+	table := s.subsets
+	if table == nil {
+		table = solveSubsets(s.ctx, digits)
+	}
+	if table.incomplete {
+		return nil, true
+	}
+	ss := splitSearch{s: s, digits: digits, table: table, memo: make(map[[2]int][]expression)}
+
+	// Solutions can use any of the digits, so gather those for every group, with the fewest digits first.
+	var solutions []expression
+	var seen expressionSet
+	for _, mask := range table.masks {
+		if _, ok := table.values[mask][target]; !ok {
+			continue
+		}
+		for _, e := range ss.solve(mask, target) {
+			if !s.filter.keep(e) || !seen.add(e) {
+				continue
+			}
+			solutions = append(solutions, e)
+			if s.limit > 0 && len(solutions) >= s.limit {
+				return solutions, s.stopped()
+			}
+		}
+	}
+	return solutions, s.stopped()
+}
+
+// solve returns every solution for target which uses exactly the digits in mask.
+func (ss splitSearch) solve(mask, target int) []expression {
+	key := [2]int{mask, target}
+	if solns, ok := ss.memo[key]; ok {
+		return solns
+	}
+	if ss.s.stopped() {
+		return nil
+	}
+	if ss.s.nodes != nil {
+		ss.s.nodes.Add(1)
+	}
+	ss.s.budget.spend()
+
+	var solutions []expression
+	if bits.OnesCount(uint(mask)) == 1 {
+		if d := ss.digits[bits.TrailingZeros(uint(mask))]; d == target {
+			solutions = append(solutions, makeConstant(d))
+		}
+	}
+
+	// Visit each way of splitting mask into two groups once, and each value group a can make.
+	var seen expressionSet
+	for a := (mask - 1) & mask; a > 0 && !ss.s.stopped(); a = (a - 1) & mask {
+		b := mask ^ a
+		if a < b {
+			continue
+		}
+		for _, va := range ss.table.sorted[a] {
+			for _, j := range splitJoins(va, target) {
+				if _, ok := ss.table.values[b][j.vb]; !ok {
+					continue
+				}
+				for _, ea := range ss.solve(a, va) {
+					for _, eb := range ss.solve(b, j.vb) {
+						e := j.join(ea, eb)
+						if e.Val != target {
+							panic(fmt.Sprintf("generated invalid solution: %s = %d, want %d", e, e.Val, target))
+						}
+						e = e.fuse().canonicalize()
+						if !seen.add(e) {
+							continue
+						}
+						solutions = append(solutions, e)
+					}
+				}
+			}
+		}
+	}
+
+	if !ss.s.stopped() {
+		ss.memo[key] = solutions
+	}
+	return solutions
+}
+
+// splitJoin is a way of joining a value a from one group with a value vb from the other to make a target.
+type splitJoin struct {
+	vb   int
+	join func(a, b expression) expression
+}
+
+// splitJoins returns the values the other group must make to join with va to make target.
+func splitJoins(va, target int) []splitJoin {
+	// The other group can't make a value which overflows, so those joins are left out.
+	var joins []splitJoin
+	if target <= math.MaxInt-va {
+		joins = append(joins, splitJoin{target + va, func(a, b expression) expression { return makeAdd(b, makeNegate(a)) }})
+	}
+	if vb, ok := mulInts(target, va); ok {
+		joins = append(joins, splitJoin{vb, func(a, b expression) expression { return makeDivide(b, a) }})
+	}
+	if target > va {
+		joins = append(joins, splitJoin{target - va, func(a, b expression) expression { return makeAdd(a, b) }})
+	}
+	if va > target {
+		joins = append(joins, splitJoin{va - target, func(a, b expression) expression { return makeAdd(a, makeNegate(b)) }})
+	}
+	if target%va == 0 {
+		joins = append(joins, splitJoin{target / va, func(a, b expression) expression { return makeMultiply(a, b) }})
+	}
+	if va%target == 0 {
+		joins = append(joins, splitJoin{va / target, func(a, b expression) expression { return makeDivide(a, b) }})
+	}
+	return joins
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// sortedKey describes e with the operands of commutative operations sorted, so solutions which only
+// differ in the order of equal-valued operands, like 10 * (15 - 5), have the same key.
+func sortedKey(e expression) string {
+	if len(e.Children) == 0 {
+		return e.String()
+	}
+	keys := make([]string, len(e.Children))
+	for i, c := range e.Children {
+		keys[i] = sortedKey(*c)
+	}
+	if e.Op.commutative() {
+		slices.Sort(keys)
+	}
+	return opStrings[e.Op] + "(" + strings.Join(keys, ",") + ")"
+}
+
+func Test_solveSplits(t *testing.T) {
+	tests := map[string]struct {
+		digits []int
+		target int
+	}{
+		"countdown": {digits: []int{5, 7, 9, 10, 15, 25}, target: 93},
+		"small":     {digits: []int{1, 2, 3, 4, 5}, target: 47},
+		"repeats":   {digits: []int{2, 2, 3, 3}, target: 12},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			s, err := solver{}.withEngine(engineSplits, 0, tt.digits)
+			if err != nil {
+				t.Fatalf("withEngine() failed: %v", err)
+			}
+			got, incomplete := s.solveWithin(0, tt.target, tt.digits)
+			if incomplete {
+				t.Errorf("solveWithin() reported incomplete results")
+			}
+			found := make(map[string]bool)
+			for _, e := range got {
+				if val, ok := e.eval(); !ok || val != tt.target {
+					t.Errorf("solution %s evaluates to %d, %t", e, val, ok)
+				}
+				found[sortedKey(e)] = true
+			}
+			// Every solution the search finds is also found by joining splits.
+			for _, e := range (solver{}).solve(tt.target, tt.digits) {
+				if !found[sortedKey(e)] {
+					t.Errorf("solveWithin() is missing %s", e)
+				}
+			}
+		})
+	}
+}
+
+func Test_solveSplits_onlySplits(t *testing.T) {
+	digits := []int{3, 4, 6, 9}
+	s, err := solver{}.withEngine(engineSplits, 0, digits)
+	if err != nil {
+		t.Fatalf("withEngine() failed: %v", err)
+	}
+	got, _ := s.solveWithin(time.Minute, 105, digits)
+	var strs []string
+	for _, e := range got {
+		strs = append(strs, formatInfix(e.withSubtraction(), opStrings))
+	}
+	want := []string{"(9 + 6)*(4 + 3)"}
+	if diff := cmp.Diff(want, strs); diff != "" {
+		t.Errorf("solveWithin() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// solveStats describes a solve, for solve --stats.
+type solveStats struct {
+	Elapsed time.Duration
+	// Nodes is how many sub-problems were searched, and Generated how many expressions were built before
+	// duplicates were removed.
+	Nodes, Generated int64
+	Solutions        int
+	// Deepest is the solution with the longest chain of operations, each using the one before, and Depth its length.
+	Deepest expression
+	Depth   int
+	operationStats
+}
+
+// collectStats fills in what solveStats says about solns.
+func collectStats(solns []expression) solveStats {
+	st := solveStats{Solutions: len(solns)}
+	st.add(solns)
+	for _, e := range solns {
+		steps := e.steps()
+		depths := make([]int, len(steps))
+		for i, s := range steps {
+			depths[i] = 1
+			for _, j := range []int{s.AStep, s.BStep} {
+				if j >= 0 {
+					depths[i] = max(depths[i], depths[j]+1)
+				}
+			}
+		}
+		if n := len(steps); n > 0 && depths[n-1] > st.Depth {
+			st.Deepest, st.Depth = e, depths[n-1]
+		}
+	}
+	return st
+}
+
+// write describes st, with a bar for each operation's share of the solutions' operations.
+func (st solveStats) write(w io.Writer, p printer) error {
+	var b strings.Builder
+	b.WriteString("Stats:\n")
+	fmt.Fprintf(&b, "  time: %v\n", st.Elapsed.Round(time.Microsecond))
+	fmt.Fprintf(&b, "  sub-problems searched: %d\n", st.Nodes)
+	fmt.Fprintf(&b, "  %s\n", solutionCount(st.Solutions, st.Generated))
+	if st.Depth > 0 {
+		fmt.Fprintf(&b, "  deepest: %d operations deep, %s\n", st.Depth, p.format(st.Deepest))
+	}
+	st.writeOperations(&b, p)
+	if st.Targets > 0 {
+		needs := "no"
+		if st.NeedDivision > 0 {
+			needs = "yes"
+		}
+		fmt.Fprintf(&b, "  every solution divides: %s\n", needs)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// operationStats counts how often each operation is used across the solutions for some targets, and how many of
+// the targets need division.
+type operationStats struct {
+	// Operations counts each operation used by the solutions, written with subtraction.
+	Operations map[operation]int
+	// Targets is how many targets had solutions, and NeedDivision how many of them every solution divides for.
+	Targets, NeedDivision int
+}
+
+// add counts the operations of solns, the solutions for one target.
+func (st *operationStats) add(solns []expression) {
+	if st.Operations == nil {
+		st.Operations = make(map[operation]int)
+	}
+	if len(solns) == 0 {
+		return
+	}
+	st.Targets++
+	divides := true
+	for _, e := range solns {
+		for _, s := range e.steps() {
+			st.Operations[s.Op]++
+		}
+		divides = divides && e.uses(opDivide)
+	}
+	if divides {
+		st.NeedDivision++
+	}
+}
+
+// writeOperations draws a bar for each operation's share of them, if there are any.
+func (st operationStats) writeOperations(b *strings.Builder, p printer) {
+	most := 0
+	for _, n := range st.Operations {
+		most = max(most, n)
+	}
+	if most == 0 {
+		return
+	}
+	b.WriteString("  operations:\n")
+	for _, op := range []operation{opAdd, opSubtract, opMultiply, opDivide} {
+		n := st.Operations[op]
+		bar := (n*histogramWidth + most - 1) / most
+		fmt.Fprintf(b, "    %s | %s %d\n", p.symbols[op], strings.Repeat("#", bar), n)
+	}
+}
+
+// write describes the operations used across a range of targets.
+func (st operationStats) write(w io.Writer, p printer) error {
+	var b strings.Builder
+	b.WriteString("Operation stats:\n")
+	st.writeOperations(&b, p)
+	fmt.Fprintf(&b, "  targets needing division: %d of %d solvable\n", st.NeedDivision, st.Targets)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
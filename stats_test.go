@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func Test_collectStats(t *testing.T) {
+	var solns []expression
+	for _, s := range []string{"9*7 + 30", "(25*(15 + 10) - 9)/7 + 5", "25"} {
+		e, err := parseInfix(s)
+		if err != nil {
+			t.Fatalf("parseInfix(%q) failed unexpectedly: %v", s, err)
+		}
+		solns = append(solns, e)
+	}
+	got := collectStats(solns)
+	p := newPrinter(notationInfix, false)
+	if diff := cmp.Diff("(25*(15 + 10) - 9)/7 + 5", p.format(got.Deepest)); diff != "" {
+		t.Errorf("collectStats() deepest mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(5, got.Depth); diff != "" {
+		t.Errorf("collectStats() depth mismatch (-want +got):\n%s", diff)
+	}
+	want := map[operation]int{opAdd: 3, opSubtract: 1, opMultiply: 2, opDivide: 1}
+	if diff := cmp.Diff(want, got.Operations); diff != "" {
+		t.Errorf("collectStats() operations mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_solveStats_write(t *testing.T) {
+	st := solveStats{
+		Elapsed:   1500 * time.Microsecond,
+		Nodes:     42,
+		Generated: 8,
+		Solutions: 5,
+		Deepest:   makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(30)),
+		Depth:     2,
+		operationStats: operationStats{
+			Operations: map[operation]int{opAdd: 4, opMultiply: 2},
+			Targets:    1,
+		},
+	}
+	var b strings.Builder
+	if err := st.write(&b, newPrinter(notationInfix, false)); err != nil {
+		t.Fatalf("write() failed unexpectedly: %v", err)
+	}
+	want := "Stats:\n" +
+		"  time: 1.5ms\n" +
+		"  sub-problems searched: 42\n" +
+		"  5 solutions, from 8 expressions before removing duplicates\n" +
+		"  deepest: 2 operations deep, 9*7 + 30\n" +
+		"  operations:\n" +
+		"    + | " + strings.Repeat("#", 50) + " 4\n" +
+		"    - |  0\n" +
+		"    * | " + strings.Repeat("#", 25) + " 2\n" +
+		"    / |  0\n" +
+		"  every solution divides: no\n"
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("write() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_operationStats(t *testing.T) {
+	var st operationStats
+	for _, target := range []int{12, 2, 100} {
+		st.add(solve(target, []int{2, 3, 6}))
+	}
+	want := operationStats{Targets: 2, NeedDivision: 0}
+	if diff := cmp.Diff(want, st, cmpopts.IgnoreFields(operationStats{}, "Operations")); diff != "" {
+		t.Errorf("add() mismatch (-want +got):\n%s", diff)
+	}
+
+	st = operationStats{}
+	st.add(solve(2, []int{6, 3}))
+	st.add(solve(9, []int{6, 3}))
+	want = operationStats{Operations: map[operation]int{opAdd: 1, opDivide: 1}, Targets: 2, NeedDivision: 1}
+	if diff := cmp.Diff(want, st); diff != "" {
+		t.Errorf("add() mismatch (-want +got):\n%s", diff)
+	}
+	var b strings.Builder
+	if err := st.write(&b, newPrinter(notationInfix, false)); err != nil {
+		t.Fatalf("write() failed unexpectedly: %v", err)
+	}
+	if !strings.Contains(b.String(), "targets needing division: 1 of 2 solvable") {
+		t.Errorf("write() = %q, want it to count the targets needing division", b.String())
+	}
+}
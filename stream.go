@@ -0,0 +1,146 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// recentSet is a set of expressions which only remembers the last max added, so it uses bounded memory.
+// Past that an expression can be added again once it's been forgotten, so it only finds duplicates which
+// are close together.
+type recentSet struct {
+	max int
+	// order holds the expressions from least to most recently added, and buckets indexes them by hash.
+	order   *list.List
+	buckets map[uint64][]*list.Element
+	// forgot counts the expressions forgotten to make room.
+	forgot int
+}
+
+func newRecentSet(max int) *recentSet {
+	return &recentSet{max: max, order: list.New(), buckets: make(map[uint64][]*list.Element)}
+}
+
+// add adds e to the set, and reports whether it wasn't already there.
+// A repeat counts as recently added, so duplicates found often are remembered longest.
+func (s *recentSet) add(e expression) bool {
+	h := e.hash()
+	for _, el := range s.buckets[h] {
+		if e.equal(el.Value.(expression)) {
+			s.order.MoveToBack(el)
+			return false
+		}
+	}
+	if s.order.Len() >= s.max {
+		oldest := s.order.Front()
+		s.forget(oldest)
+		s.forgot++
+	}
+	s.buckets[h] = append(s.buckets[h], s.order.PushBack(e))
+	return true
+}
+
+func (s *recentSet) forget(el *list.Element) {
+	s.order.Remove(el)
+	h := el.Value.(expression).hash()
+	bucket := s.buckets[h]
+	for i, other := range bucket {
+		if other == el {
+			bucket = append(bucket[:i], bucket[i+1:]...)
+			break
+		}
+	}
+	if len(bucket) == 0 {
+		delete(s.buckets, h)
+	} else {
+		s.buckets[h] = bucket
+	}
+}
+
+// streamSolutions passes each solution for target from digits to emit as it's found, stopping if emit returns false.
+// It finds the same solutions as engineSearch, but never holds them all: sub-searches aren't memoized,
+// and duplicates are only removed while seen remembers them. That makes it much slower, but its memory
+// is bounded by seen's size. It reports whether it stopped early because s was stopped.
+func (s solver) streamSolutions(target int, digits []int, seen *recentSet, emit func(expression) bool) bool {
+	pool, _ := sortDigits(digits)
+	s.pool = pool
+	s.largest = largestValues(pool)
+	all := uint(1)<<len(pool) - 1
+	s.stream(target, all, func(e expression) bool {
+		if !s.filter.keep(e) || !seen.add(e) {
+			return true
+		}
+		return emit(e)
+	})
+	return s.stopped()
+}
+
+// stream passes each expression for target from the digits in mask to fn, in the forms search tries,
+// normalized like search's but without removing duplicates. It returns false if fn did or s was stopped.
+func (s solver) stream(target int, mask uint, fn func(expression) bool) bool {
+	if s.stopped() {
+		return false
+	}
+	if target > s.largest[mask] {
+		return true
+	}
+	if s.nodes != nil {
+		s.nodes.Add(1)
+	}
+	s.budget.spend()
+	for m := mask; m != 0; m &= m - 1 {
+		c := bits.TrailingZeros(m)
+		if s.repeated(mask, c) {
+			continue
+		}
+		a := s.pool[c]
+		aExp := makeConstant(a)
+		other := mask &^ (1 << c)
+		if a == target && !fn(aExp) {
+			return false
+		}
+		if other == 0 {
+			continue
+		}
+		// The rest would have to be bigger than any int, if the sum or product overflows.
+		product, productOK := mulInts(target, a)
+		forms := []struct {
+			ok    bool
+			value int
+			build func(rest expression) expression
+		}{
+			{target > a, target - a, func(rest expression) expression { return makeAdd(aExp, rest) }},
+			{a > target, a - target, func(rest expression) expression { return makeAdd(aExp, makeNegate(rest)) }},
+			{target <= math.MaxInt-a, target + a, func(rest expression) expression { return makeAdd(rest, makeNegate(aExp)) }},
+			{target%a == 0, target / a, func(rest expression) expression { return makeMultiply(aExp, rest) }},
+			{a%target == 0, a / target, func(rest expression) expression { return makeDivide(aExp, rest) }},
+			{productOK, product, func(rest expression) expression { return makeDivide(rest, aExp) }},
+		}
+		for _, f := range forms {
+			if !f.ok {
+				continue
+			}
+			if !s.stream(f.value, other, func(rest expression) bool { return fn(f.build(rest).fuse().canonicalize()) }) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// streamSolutionsWithin is streamSolutions bounded by timeout, if it's positive, like solveWithin.
+func (s solver) streamSolutionsWithin(timeout time.Duration, target int, digits []int, seen *recentSet, emit func(expression) bool) bool {
+	if timeout > 0 {
+		parent := s.ctx
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+		s.ctx = ctx
+	}
+	return s.streamSolutions(target, digits, seen, emit)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_recentSet(t *testing.T) {
+	s := newRecentSet(2)
+	a, b, c := makeConstant(1), makeConstant(2), makeConstant(3)
+	var got []bool
+	for _, e := range []expression{a, b, a, c, a, b} {
+		got = append(got, s.add(e))
+	}
+	// Adding a again keeps it, so c forgets b rather than a.
+	want := []bool{true, true, false, true, false, true}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("add() mismatch (-want +got):\n%s", diff)
+	}
+	if s.forgot != 2 {
+		t.Errorf("forgot = %d, want 2", s.forgot)
+	}
+}
+
+func Test_solver_streamSolutions(t *testing.T) {
+	tests := map[string]struct {
+		digits []int
+		target int
+		filter solutionFilter
+	}{
+		"simple":          {digits: []int{2, 3, 5}, target: 11},
+		"repeated digits": {digits: []int{2, 2, 3, 3}, target: 12},
+		"countdown":       {digits: []int{3, 6, 25, 50, 75, 100}, target: 952},
+		"filtered":        {digits: []int{5, 7, 9, 10, 15}, target: 93, filter: solutionFilter{noDivision: true}},
+		"no solution":     {digits: []int{2, 3}, target: 100},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			var got []string
+			stopped := solver{filter: tt.filter}.streamSolutions(tt.target, tt.digits, newRecentSet(1<<20), func(e expression) bool {
+				got = append(got, e.String())
+				return true
+			})
+			if stopped {
+				t.Errorf("streamSolutions() stopped unexpectedly")
+			}
+			var want []string
+			for _, e := range (solver{filter: tt.filter}).solve(tt.target, tt.digits) {
+				want = append(want, e.String())
+			}
+			sort.Strings(want)
+			sort.Strings(got)
+			if diff := cmp.Diff(fmt.Sprint(want), fmt.Sprint(got)); diff != "" {
+				t.Errorf("streamSolutions() mismatch with solve() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_solver_streamSolutions_stop(t *testing.T) {
+	n := 0
+	solver{}.streamSolutions(24, []int{1, 3, 4, 6, 8}, newRecentSet(10), func(e expression) bool {
+		n++
+		return n < 3
+	})
+	if n != 3 {
+		t.Errorf("streamSolutions() emitted %d solutions after being told to stop at 3", n)
+	}
+}
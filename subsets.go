@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+	"time"
+)
+
+// engine is an algorithm for finding solutions.
+type engine int
+
+const (
+	// engineSearch is solver's recursive search, which finds every solution.
+	engineSearch engine = iota
+	// engineSubsets works up from single digits to every subset of them, finding one solution per value.
+	engineSubsets
+	// engineSplits finds every solution, including those joining two groups of digits, using an engineSubsets table.
+	engineSplits
+	// engineQuick finds one solution fast by trying the most promising steps first, but can miss solutions.
+	engineQuick
+)
+
+var engineNames = map[string]engine{
+	"search":  engineSearch,
+	"subsets": engineSubsets,
+	"splits":  engineSplits,
+	"quick":   engineQuick,
+}
+
+func parseEngine(s string) (engine, error) {
+	e, ok := engineNames[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown engine %q", s)
+	}
+	return e, nil
+}
+
+// withEngine returns s set up to find solutions for digits with e.
+// For engineSubsets, the table of solutions is built straight away, within timeout if it's positive.
+func (s solver) withEngine(e engine, timeout time.Duration, digits []int) (solver, error) {
+	if e == engineSearch {
+		return s, nil
+	}
+	s.engine = e
+	if e == engineQuick {
+		return s, nil
+	}
+	if e == engineSubsets && s.filter != (solutionFilter{}) {
+		return s, fmt.Errorf("the subsets engine only finds one solution per target, so it can't be filtered")
+	}
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	s.subsets = solveSubsets(ctx, digits)
+	return s, nil
+}
+
+// subsetSolutions holds one way of making every value which can be made from each subset of some digits.
+// Unlike solver, subsets are combined with any split of the digits, e.g. (a + b) * (c + d),
+// and every target is answered from the same table, so it's much faster for ranges of targets.
+type subsetSolutions struct {
+	// values[mask] maps each value made from exactly the digits in mask to one way of making it.
+	values []map[int]expression
+	// sorted[mask] holds the keys of values[mask] in ascending order, so the table is built deterministically.
+	sorted [][]int
+	// masks holds every subset, those with the fewest digits first.
+	masks []int
+	// incomplete reports whether building the table was cancelled or timed out, so some values may be missing.
+	incomplete bool
+}
+
+// solveSubsets builds the table of solutions for digits, stopping early if ctx is done.
+func solveSubsets(ctx context.Context, digits []int) *subsetSolutions {
+	n := len(digits)
+	s := &subsetSolutions{
+		values: make([]map[int]expression, 1<<n),
+		sorted: make([][]int, 1<<n),
+	}
+	for mask := 1; mask < 1<<n; mask++ {
+		s.masks = append(s.masks, mask)
+	}
+	sort.SliceStable(s.masks, func(i, j int) bool {
+		return bits.OnesCount(uint(s.masks[i])) < bits.OnesCount(uint(s.masks[j]))
+	})
+
+	for _, mask := range s.masks {
+		if ctx != nil && ctx.Err() != nil {
+			s.incomplete = true
+			return s
+		}
+		vals := make(map[int]expression)
+		if bits.OnesCount(uint(mask)) == 1 {
+			d := digits[bits.TrailingZeros(uint(mask))]
+			vals[d] = makeConstant(d)
+		}
+		// Combine each split of mask into two non-empty parts, visiting each split once.
+		for a := (mask - 1) & mask; a > 0; a = (a - 1) & mask {
+			b := mask ^ a
+			if a < b {
+				continue
+			}
+			for _, va := range s.sorted[a] {
+				for _, vb := range s.sorted[b] {
+					combineSubsets(vals, s.values[a][va], s.values[b][vb])
+				}
+			}
+		}
+		s.values[mask] = vals
+		keys := make([]int, 0, len(vals))
+		for v := range vals {
+			keys = append(keys, v)
+		}
+		sort.Ints(keys)
+		s.sorted[mask] = keys
+	}
+	return s
+}
+
+// combineSubsets adds the values made by combining a and b to vals, if they're not already there.
+// Expressions are built in the same normalized form as solver's solutions.
+func combineSubsets(vals map[int]expression, a, b expression) {
+	if a.Val < b.Val {
+		a, b = b, a
+	}
+	add := func(v int, make func() expression) {
+		if _, ok := vals[v]; !ok {
+			vals[v] = make().fuse().canonicalize()
+		}
+	}
+	// A sum or product which overflows can't be a solution, and would wrap around to a bogus value.
+	if a.Val <= math.MaxInt-b.Val {
+		add(a.Val+b.Val, func() expression { return makeAdd(a, b) })
+	}
+	if p, ok := mulInts(a.Val, b.Val); ok {
+		add(p, func() expression { return makeMultiply(a, b) })
+	}
+	if a.Val > b.Val {
+		add(a.Val-b.Val, func() expression { return makeAdd(a, makeNegate(b)) })
+	}
+	if a.Val%b.Val == 0 {
+		add(a.Val/b.Val, func() expression { return makeDivide(a, b) })
+	}
+}
+
+// find returns a way of making target, using as few digits as possible.
+func (s *subsetSolutions) find(target int) (expression, bool) {
+	for _, mask := range s.masks {
+		if e, ok := s.values[mask][target]; ok {
+			return e, true
+		}
+	}
+	return expression{}, false
+}
+
+// solutions returns the solution for target as a list, like solver's results.
+func (s *subsetSolutions) solutions(target int) []expression {
+	if e, ok := s.find(target); ok {
+		return []expression{e}
+	}
+	return nil
+}
+
+// reachable returns every value which can be made, in ascending order.
+func (s *subsetSolutions) reachable() []int {
+	all := make(map[int]bool)
+	for _, keys := range s.sorted {
+		for _, v := range keys {
+			all[v] = true
+		}
+	}
+	out := make([]int, 0, len(all))
+	for v := range all {
+		out = append(out, v)
+	}
+	sort.Ints(out)
+	return out
+}
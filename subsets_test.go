@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_solveSubsets(t *testing.T) {
+	s := solveSubsets(context.Background(), []int{3, 4, 6, 9})
+	tests := map[string]struct {
+		target int
+		want   string
+	}{
+		"digit":         {target: 6, want: "6"},
+		"single step":   {target: 13, want: "9 + 4"},
+		"split":         {target: 105, want: "(9 + 6)*(4 + 3)"},
+		"subtraction":   {target: 34, want: "9*4 - 6/3"},
+		"fewest digits": {target: 22, want: "6*3 + 4"},
+		"no solution":   {target: 1000},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := ""
+			if e, ok := s.find(tt.target); ok {
+				got = formatInfix(e.withSubtraction(), opStrings)
+				if e.Val != tt.target {
+					t.Errorf("find(%d) = %s with value %d", tt.target, got, e.Val)
+				}
+				if val, ok := e.eval(); !ok || val != tt.target {
+					t.Errorf("find(%d) = %s, which evaluates to %d, %t", tt.target, got, val, ok)
+				}
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("find(%d) = %q, want %q", tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_subsetSolutions_reachable(t *testing.T) {
+	digits := []int{2, 3, 7, 10}
+	got := make(map[int]bool)
+	for _, v := range solveSubsets(context.Background(), digits).reachable() {
+		got[v] = true
+	}
+	// Every value the search can make can be made from some split of the digits.
+	for _, v := range reachableValues(digits) {
+		if !got[v] {
+			t.Errorf("reachable() is missing %d", v)
+		}
+	}
+}
+
+func Test_solveSubsets_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := solveSubsets(ctx, []int{3, 4, 6, 9})
+	if !s.incomplete {
+		t.Errorf("incomplete = false after cancellation, want true")
+	}
+	if _, ok := s.find(6); ok {
+		t.Errorf("find(6) succeeded after cancellation")
+	}
+}
+
+func Test_engines_overflow(t *testing.T) {
+	// Products of these overflow, and must be skipped rather than wrapping around.
+	digits := []int{65536, 65536, 65536, 65536, 65536}
+	subsets, err := solver{}.withEngine(engineSubsets, 0, digits)
+	if err != nil {
+		t.Fatalf("withEngine(subsets) failed: %v", err)
+	}
+	splits, err := solver{}.withEngine(engineSplits, 0, digits)
+	if err != nil {
+		t.Fatalf("withEngine(splits) failed: %v", err)
+	}
+	for _, target := range []int{1, 2, 3, 4, 5, 999, 65536, 131072, 1 << 32} {
+		fromSubsets, _ := subsets.solveWithin(0, target, digits)
+		fromSplits, _ := splits.solveWithin(0, target, digits)
+		for _, e := range append(fromSubsets, fromSplits...) {
+			if val, ok := e.eval(); !ok || val != target {
+				t.Errorf("target %d: solution %s evaluates to %d, %t", target, e, val, ok)
+			}
+		}
+		// The subsets engine finds a solution exactly when the splits engine does.
+		if (len(fromSubsets) > 0) != (len(fromSplits) > 0) {
+			t.Errorf("target %d: subsets found %d solutions, splits %d", target, len(fromSubsets), len(fromSplits))
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// renderSVG draws the expression tree for e as a standalone SVG document.
+func renderSVG(e expression, sym symbols, t theme) string {
+	root, width, height := layoutTree(e, sym)
+
+	var edges, nodes strings.Builder
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		for _, c := range n.Children {
+			fmt.Fprintf(&edges, "  <line x1=\"%g\" y1=\"%g\" x2=\"%g\" y2=\"%g\"/>\n", n.X, n.Y, c.X, c.Y)
+			walk(c)
+		}
+
+		class := "leaf"
+		if len(n.Children) > 0 {
+			class = "op"
+		}
+		fmt.Fprintf(&nodes, "  <g class=\"%s\">\n", class)
+		fmt.Fprintf(&nodes, "    <circle cx=\"%g\" cy=\"%g\" r=\"%d\"/>\n", n.X, n.Y, treeNodeRadius)
+		fmt.Fprintf(&nodes, "    <text x=\"%g\" y=\"%g\">%s</text>\n", n.X, n.Y, html.EscapeString(n.Label))
+		if n.Annotation != "" {
+			fmt.Fprintf(&nodes, "    <text class=\"value\" x=\"%g\" y=\"%g\">%s</text>\n", n.X+treeNodeRadius+4, n.Y-treeNodeRadius, html.EscapeString(n.Annotation))
+		}
+		nodes.WriteString("  </g>\n")
+	}
+	walk(root)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%g\" height=\"%g\" viewBox=\"0 0 %g %g\">\n", width, height, width, height)
+	b.WriteString("  <style>\n")
+	fmt.Fprintf(&b, "    svg { background: %s; }\n", hex(t.Background))
+	fmt.Fprintf(&b, "    line, circle { stroke: %s; stroke-width: 2; }\n", hex(t.Foreground))
+	fmt.Fprintf(&b, "    .op circle { fill: %s; }\n", hex(t.OpFill))
+	fmt.Fprintf(&b, "    .leaf circle { fill: %s; }\n", hex(t.LeafFill))
+	fmt.Fprintf(&b, "    text { font: 16px sans-serif; fill: %s; text-anchor: middle; dominant-baseline: central; }\n", hex(t.Foreground))
+	fmt.Fprintf(&b, "    text.value { font-size: 12px; fill: %s; text-anchor: start; }\n", hex(t.Value))
+	b.WriteString("  </style>\n")
+	b.WriteString(edges.String())
+	b.WriteString(nodes.String())
+	b.WriteString("</svg>\n")
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_renderSVG(t *testing.T) {
+	// 9*7 + 25, with a symbol which needs escaping, so the document is only well-formed if it's escaped.
+	expr := makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25))
+	sym := symbols{opAdd: "<+>", opMultiply: "×"}
+
+	type svgText struct {
+		Class string `xml:"class,attr"`
+		Text  string `xml:",chardata"`
+	}
+	type svgDoc struct {
+		Width  string     `xml:"width,attr"`
+		Height string     `xml:"height,attr"`
+		Style  string     `xml:"style"`
+		Lines  []struct{} `xml:"line"`
+		Groups []struct {
+			Class   string     `xml:"class,attr"`
+			Circles []struct{} `xml:"circle"`
+			Texts   []svgText  `xml:"text"`
+		} `xml:"g"`
+	}
+	var doc svgDoc
+	if err := xml.Unmarshal([]byte(renderSVG(expr, sym, themes["light"])), &doc); err != nil {
+		t.Fatalf("renderSVG() isn't valid XML: %v", err)
+	}
+
+	// Each node is a group of a circle and its label, and operations are annotated with their values.
+	type node struct {
+		Class  string
+		Labels []svgText
+	}
+	var got []node
+	for _, g := range doc.Groups {
+		if len(g.Circles) != 1 {
+			t.Errorf("node %v has %d circles, want 1", g.Texts, len(g.Circles))
+		}
+		got = append(got, node{g.Class, g.Texts})
+	}
+	want := []node{
+		{"leaf", []svgText{{Text: "9"}}},
+		{"leaf", []svgText{{Text: "7"}}},
+		{"op", []svgText{{Text: "×"}, {Class: "value", Text: "63"}}},
+		{"leaf", []svgText{{Text: "25"}}},
+		{"op", []svgText{{Text: "<+>"}, {Class: "value", Text: "88"}}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("renderSVG() nodes mismatch (-want +got):\n%s", diff)
+	}
+	if len(doc.Lines) != 4 {
+		t.Errorf("renderSVG() has %d edges, want 4", len(doc.Lines))
+	}
+	if doc.Width != "200" || doc.Height != "240" {
+		t.Errorf("renderSVG() size = %sx%s, want 200x240", doc.Width, doc.Height)
+	}
+	if !strings.Contains(doc.Style, "#aa3333") {
+		t.Errorf("renderSVG() style doesn't use the theme's value color:\n%s", doc.Style)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// theme is the colour scheme used when drawing solutions as images.
+type theme struct {
+	Background color.RGBA
+	Foreground color.RGBA
+	// OpFill and LeafFill fill operation and constant nodes of expression trees.
+	OpFill   color.RGBA
+	LeafFill color.RGBA
+	// Value is used for the intermediate values annotated on operation nodes.
+	Value color.RGBA
+}
+
+var themes = map[string]theme{
+	"light": {
+		Background: color.RGBA{0xff, 0xff, 0xff, 0xff},
+		Foreground: color.RGBA{0x33, 0x33, 0x33, 0xff},
+		OpFill:     color.RGBA{0xdd, 0xe8, 0xf6, 0xff},
+		LeafFill:   color.RGBA{0xff, 0xff, 0xff, 0xff},
+		Value:      color.RGBA{0xaa, 0x33, 0x33, 0xff},
+	},
+	"dark": {
+		Background: color.RGBA{0x1e, 0x1f, 0x22, 0xff},
+		Foreground: color.RGBA{0xdd, 0xdd, 0xdd, 0xff},
+		OpFill:     color.RGBA{0x2b, 0x45, 0x6b, 0xff},
+		LeafFill:   color.RGBA{0x2f, 0x31, 0x36, 0xff},
+		Value:      color.RGBA{0xf0, 0x90, 0x80, 0xff},
+	},
+}
+
+func parseTheme(s string) (theme, error) {
+	t, ok := themes[s]
+	if !ok {
+		return theme{}, fmt.Errorf("unknown theme %q", s)
+	}
+	return t, nil
+}
+
+// hex returns c as a CSS colour.
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// traceEvent is one line of a search trace, written as JSON.
+type traceEvent struct {
+	// Event is "search" for a sub-search finishing, "branch" for a form tried with a digit, and "prune" for one skipped.
+	Event string `json:"event"`
+	// Depth is how many digits the top-level search has taken before this one: 0 for the top level.
+	Depth  int `json:"depth"`
+	Target int `json:"target"`
+	// Digit and Form are the digit and the form it's combined with the rest in, like "a + rest", for branches and prunes.
+	Digit int    `json:"digit,omitempty"`
+	Form  string `json:"form,omitempty"`
+	// SubTarget is what a branch searches the rest of the digits for.
+	SubTarget int    `json:"sub_target,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	// Digits, Solutions, Memo and Nanos describe a search: how many digits it used, how many solutions it found,
+	// whether they came from the memo, and how long it took, including its sub-searches.
+	Digits    int   `json:"digits,omitempty"`
+	Solutions int   `json:"solutions,omitempty"`
+	Memo      bool  `json:"memo,omitempty"`
+	Nanos     int64 `json:"nanos,omitempty"`
+}
+
+// searchTracer writes the events of a search to a file, one JSON object per line, to see what it spent its time on.
+// It's safe for concurrent use, so a parallel search's goroutines can share it, though their events are interleaved.
+type searchTracer struct {
+	mu  sync.Mutex
+	f   *os.File
+	w   *bufio.Writer
+	enc *json.Encoder
+	err error
+}
+
+func createTrace(path string) (*searchTracer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+	return &searchTracer{f: f, w: w, enc: json.NewEncoder(w)}, nil
+}
+
+// write adds ev to the trace, keeping the first error to report on close.
+func (t *searchTracer) write(ev traceEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err == nil {
+		t.err = t.enc.Encode(ev)
+	}
+}
+
+// close finishes the trace, and returns the first error writing it.
+func (t *searchTracer) close() error {
+	if t.err == nil {
+		t.err = t.w.Flush()
+	}
+	if err := t.f.Close(); t.err == nil {
+		t.err = err
+	}
+	return t.err
+}
+
+// depth returns how many of the pool's digits have been taken before a search of mask.
+func (s solver) depth(mask uint) int {
+	return len(s.pool) - bits.OnesCount(mask)
+}
+
+// traceBranch records trying digit a with the rest of mask in form, searching the rest for sub, if the search is traced.
+func (s solver) traceBranch(target int, mask uint, a int, form string, sub int) {
+	if s.trace != nil {
+		s.trace.write(traceEvent{Event: "branch", Depth: s.depth(mask), Target: target, Digit: a, Form: form, SubTarget: sub})
+	}
+}
+
+// pruned records skipping digit a with the rest of mask in form, for reason, in the debug log and the trace.
+func (s solver) pruned(target int, mask uint, a int, form, reason string) {
+	if s.log != nil {
+		s.debug("Pruned", "target", target, "digit", a, "form", form, "reason", reason)
+	}
+	if s.trace != nil {
+		s.trace.write(traceEvent{Event: "prune", Depth: s.depth(mask), Target: target, Digit: a, Form: form, Reason: reason})
+	}
+}
+
+// traceSearch records a search of mask for target which found n solutions, if the search is traced.
+func (s solver) traceSearch(target int, mask uint, n int, memo bool, start time.Time) {
+	if s.trace != nil {
+		s.trace.write(traceEvent{Event: "search", Depth: s.depth(mask), Target: target, Digits: bits.OnesCount(mask), Solutions: n, Memo: memo, Nanos: time.Since(start).Nanoseconds()})
+	}
+}
+
+// traceSummary is what a search trace says about where the search went.
+type traceSummary struct {
+	Events, Branches, Prunes, Searches, MemoHits int
+	// ByDepth holds the searches at each depth, and how long they took between them.
+	ByDepth []depthSummary
+	// Reasons counts the prunes for each reason, and Forms the branches tried in each form.
+	Reasons, Forms map[string]int
+	// Slowest holds the slowest searches below the top level, slowest first.
+	Slowest []traceEvent
+}
+
+type depthSummary struct {
+	Searches int
+	Time     time.Duration
+}
+
+// summarizeTrace reads a search trace from r, keeping the n slowest searches.
+func summarizeTrace(r io.Reader, n int) (traceSummary, error) {
+	sum := traceSummary{Reasons: make(map[string]int), Forms: make(map[string]int)}
+	dec := json.NewDecoder(r)
+	for {
+		var ev traceEvent
+		err := dec.Decode(&ev)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return traceSummary{}, fmt.Errorf("event %d: %v", sum.Events+1, err)
+		}
+		sum.Events++
+		switch ev.Event {
+		case "branch":
+			sum.Branches++
+			sum.Forms[ev.Form]++
+		case "prune":
+			sum.Prunes++
+			sum.Reasons[ev.Reason]++
+		case "search":
+			sum.Searches++
+			if ev.Memo {
+				sum.MemoHits++
+			}
+			for len(sum.ByDepth) <= ev.Depth {
+				sum.ByDepth = append(sum.ByDepth, depthSummary{})
+			}
+			sum.ByDepth[ev.Depth].Searches++
+			sum.ByDepth[ev.Depth].Time += time.Duration(ev.Nanos)
+			if ev.Depth > 0 && !ev.Memo {
+				sum.Slowest = append(sum.Slowest, ev)
+				sort.SliceStable(sum.Slowest, func(i, j int) bool { return sum.Slowest[i].Nanos > sum.Slowest[j].Nanos })
+				if len(sum.Slowest) > n {
+					sum.Slowest = sum.Slowest[:n]
+				}
+			}
+		default:
+			return traceSummary{}, fmt.Errorf("event %d: unknown event %q", sum.Events, ev.Event)
+		}
+	}
+	return sum, nil
+}
+
+// write describes the summary, with each count of reasons and forms most common first.
+func (sum traceSummary) write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%d events: %d searches (%d answered from the memo), %d branches tried, %d pruned\n",
+		sum.Events, sum.Searches, sum.MemoHits, sum.Branches, sum.Prunes)
+	fmt.Fprintln(bw, "Time by depth, including the searches below:")
+	for d, s := range sum.ByDepth {
+		fmt.Fprintf(bw, "  %d: %d searches, %v\n", d, s.Searches, s.Time)
+	}
+	for _, c := range []struct {
+		title  string
+		counts map[string]int
+	}{{"Branches by form:", sum.Forms}, {"Prunes by reason:", sum.Reasons}} {
+		fmt.Fprintln(bw, c.title)
+		keys := make([]string, 0, len(c.counts))
+		for k := range c.counts {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if c.counts[keys[i]] != c.counts[keys[j]] {
+				return c.counts[keys[i]] > c.counts[keys[j]]
+			}
+			return keys[i] < keys[j]
+		})
+		for _, k := range keys {
+			fmt.Fprintf(bw, "  %s: %d\n", k, c.counts[k])
+		}
+	}
+	fmt.Fprintln(bw, "Slowest searches:")
+	for _, ev := range sum.Slowest {
+		fmt.Fprintf(bw, "  %d from %d digits at depth %d: %v, %d solutions\n", ev.Target, ev.Digits, ev.Depth, time.Duration(ev.Nanos), ev.Solutions)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_searchTracer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.jsonl")
+	tr, err := createTrace(path)
+	if err != nil {
+		t.Fatalf("createTrace() failed unexpectedly: %v", err)
+	}
+	solns := solver{trace: tr}.solve(12, []int{2, 3, 4})
+	if err := tr.close(); err != nil {
+		t.Fatalf("close() failed unexpectedly: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed unexpectedly: %v", err)
+	}
+	defer f.Close()
+	sum, err := summarizeTrace(f, 3)
+	if err != nil {
+		t.Fatalf("summarizeTrace() failed unexpectedly: %v", err)
+	}
+	if sum.Events != sum.Searches+sum.Branches+sum.Prunes {
+		t.Errorf("summarizeTrace() counted %d events, want %d searches + %d branches + %d prunes", sum.Events, sum.Searches, sum.Branches, sum.Prunes)
+	}
+	if len(sum.ByDepth) != 3 || sum.ByDepth[0].Searches != 1 {
+		t.Errorf("summarizeTrace() searches by depth = %+v, want one top-level search and two depths below", sum.ByDepth)
+	}
+	if sum.Branches == 0 || sum.Prunes == 0 {
+		t.Errorf("summarizeTrace() found %d branches and %d prunes, want some of each", sum.Branches, sum.Prunes)
+	}
+	if len(sum.Slowest) > 3 {
+		t.Errorf("summarizeTrace() kept %d slowest searches, want at most 3", len(sum.Slowest))
+	}
+	// Tracing shouldn't change what's found.
+	if diff := cmp.Diff(len(solve(12, []int{2, 3, 4})), len(solns)); diff != "" {
+		t.Errorf("solve() with a trace found a different number of solutions (-want +got):\n%s", diff)
+	}
+}
+
+func Test_summarizeTrace(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		want    traceSummary
+		wantErr string
+	}{
+		"events": {
+			input: `{"event":"branch","depth":0,"target":12,"digit":3,"form":"a * rest","sub_target":4}
+{"event":"prune","depth":1,"target":4,"digit":4,"form":"a + rest","reason":"digit is at least the target"}
+{"event":"search","depth":1,"target":4,"digits":1,"solutions":1,"nanos":1000}
+{"event":"search","depth":1,"target":5,"digits":2,"memo":true,"nanos":10}
+{"event":"search","depth":0,"target":12,"digits":2,"solutions":1,"nanos":5000}
+`,
+			want: traceSummary{
+				Events: 5, Branches: 1, Prunes: 1, Searches: 3, MemoHits: 1,
+				ByDepth: []depthSummary{{Searches: 1, Time: 5 * time.Microsecond}, {Searches: 2, Time: 1010 * time.Nanosecond}},
+				Reasons: map[string]int{"digit is at least the target": 1},
+				Forms:   map[string]int{"a * rest": 1},
+				Slowest: []traceEvent{{Event: "search", Depth: 1, Target: 4, Digits: 1, Solutions: 1, Nanos: 1000}},
+			},
+		},
+		"unknown event": {
+			input:   `{"event":"jump"}`,
+			wantErr: `event 1: unknown event "jump"`,
+		},
+		"not JSON": {
+			input:   `{"event":`,
+			wantErr: "event 1:",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got, err := summarizeTrace(strings.NewReader(tt.input), 10)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("summarizeTrace() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("summarizeTrace() failed unexpectedly: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("summarizeTrace() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import "fmt"
+
+// Spacing used when laying out expression trees, in pixels.
+const (
+	treeNodeRadius = 20
+	treeHSpacing   = 60
+	treeVSpacing   = 80
+	treeMargin     = 40
+)
+
+// treeNode is a positioned node of an expression tree, ready for drawing.
+type treeNode struct {
+	// Label is drawn inside the node: the operator, or the value for constants.
+	Label string
+	// Annotation is drawn beside internal nodes to show their intermediate value.
+	Annotation string
+	X, Y       float64
+	Children   []*treeNode
+}
+
+// layoutTree positions the nodes of e top-down, with leaves evenly spaced along the
+// x-axis in their printed order and each parent centred over its children.
+// It returns the root and the total width and height of the drawing.
+func layoutTree(e expression, sym symbols) (*treeNode, float64, float64) {
+	nextLeaf := 0
+	maxDepth := 0
+
+	var walk func(e expression, depth int) *treeNode
+	walk = func(e expression, depth int) *treeNode {
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+		n := &treeNode{Y: treeMargin + float64(depth)*treeVSpacing}
+		if e.Op == opNone {
+			n.Label = fmt.Sprintf("%d", e.Val)
+			n.X = treeMargin + float64(nextLeaf)*treeHSpacing
+			nextLeaf++
+			return n
+		}
+
+		n.Label = sym[e.Op]
+		n.Annotation = fmt.Sprintf("%d", e.Val)
+		for _, c := range e.Children {
+			n.Children = append(n.Children, walk(*c, depth+1))
+		}
+		n.X = (n.Children[0].X + n.Children[len(n.Children)-1].X) / 2
+		return n
+	}
+
+	root := walk(e.withSubtraction(), 0)
+	width := 2*treeMargin + float64(nextLeaf-1)*treeHSpacing
+	height := 2*treeMargin + float64(maxDepth)*treeVSpacing
+	return root, width, height
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_layoutTree(t *testing.T) {
+	// 9*7 + 25: the product sits over its two leaves, the sum over the product and 25.
+	expr := makeAdd(makeMultiply(makeConstant(9), makeConstant(7)), makeConstant(25))
+
+	root, width, height := layoutTree(expr, opStrings)
+
+	type pos struct {
+		Label, Annotation string
+		X, Y              float64
+	}
+	var got []pos
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		got = append(got, pos{n.Label, n.Annotation, n.X, n.Y})
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	walk(root)
+
+	want := []pos{
+		{"+", "88", 115, 40},
+		{"*", "63", 70, 120},
+		{"9", "", 40, 200},
+		{"7", "", 100, 200},
+		{"25", "", 160, 120},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("layoutTree() mismatch (-want +got):\n%s", diff)
+	}
+	if width != 200 || height != 240 {
+		t.Errorf("layoutTree() size = %gx%g, want 200x240", width, height)
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// playModel is the bubbletea model for playing a game in the terminal.
+// A move is made by selecting a number, choosing an operator and then selecting a second number.
+type playModel struct {
+	game *game
+	sym  symbols
+	// cursor is the index of the highlighted number.
+	cursor int
+	// selected is the index of the first number of the move being made, or -1.
+	selected int
+	// op is the operator of the move being made, or opNone.
+	op      operation
+	message string
+
+	// deadline is when a timed game ends, or zero for an untimed game.
+	deadline time.Time
+	// now is the time of the last clock tick.
+	now time.Time
+	// result is set once the game is over.
+	result *playResult
+}
+
+// playResult is the outcome of a game.
+type playResult struct {
+	answer expression
+	score  int
+	timeUp bool
+	best   []expression
+}
+
+// playTickMsg updates a timed game's clock.
+type playTickMsg time.Time
+
+var playKeyOps = map[string]operation{
+	"+": opAdd,
+	"-": opSubtract,
+	"*": opMultiply,
+	"x": opMultiply,
+	"/": opDivide,
+}
+
+const playHelp = "←/→ move  enter select  + - * / operator  u undo  h hint  d declare  esc cancel  q quit"
+
+// maxRevealed is how many of the best solutions are shown once a game is over.
+const maxRevealed = 3
+
+// newPlayModel returns a model for playing g, against a countdown clock if timeLimit is positive.
+func newPlayModel(g *game, sym symbols, timeLimit time.Duration) playModel {
+	m := playModel{game: g, sym: sym, selected: -1, now: time.Now()}
+	if timeLimit > 0 {
+		m.deadline = m.now.Add(timeLimit)
+	}
+	return m
+}
+
+func playTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return playTickMsg(t) })
+}
+
+func (m playModel) Init() tea.Cmd {
+	if m.deadline.IsZero() {
+		return nil
+	}
+	return playTick()
+}
+
+func (m playModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if tick, ok := msg.(playTickMsg); ok {
+		if m.result != nil {
+			return m, nil
+		}
+		m.now = time.Time(tick)
+		if !m.now.Before(m.deadline) {
+			return m.finish(m.game.closest(), true), nil
+		}
+		return m, playTick()
+	}
+
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	k := key.String()
+	if k == "q" || k == "ctrl+c" {
+		return m, tea.Quit
+	}
+	if m.result != nil {
+		return m, nil
+	}
+	m.message = ""
+
+	if op, ok := playKeyOps[k]; ok {
+		if m.selected < 0 {
+			m.selected = m.cursor
+		}
+		m.op = op
+		return m, nil
+	}
+	switch k {
+	case "left":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "right":
+		if m.cursor < len(m.game.numbers)-1 {
+			m.cursor++
+		}
+	case "enter", " ":
+		m = m.choose()
+	case "esc":
+		m.selected, m.op = -1, opNone
+	case "u", "backspace":
+		if !m.game.undo() {
+			m.message = "Nothing to undo"
+		}
+		m.selected, m.op = -1, opNone
+		m.cursor = min(m.cursor, len(m.game.numbers)-1)
+	case "h":
+		m.message = m.game.hint(m.sym)
+	case "d":
+		m = m.finish(m.game.numbers[m.cursor], false)
+	}
+	return m, nil
+}
+
+// choose selects the number under the cursor, making the move once it has two numbers and an operator.
+func (m playModel) choose() playModel {
+	switch {
+	case m.selected < 0:
+		m.selected = m.cursor
+		return m
+	case m.op == opNone:
+		// Choosing another number before an operator starts again from that one.
+		m.selected = m.cursor
+		return m
+	}
+
+	if err := m.game.combine(m.selected, m.cursor, m.op); err != nil {
+		m.message = err.Error()
+		return m
+	}
+	// combine puts the result where the first number was, so move the cursor onto it.
+	if m.cursor < m.selected {
+		m.cursor = m.selected - 1
+	} else {
+		m.cursor = m.selected
+	}
+	m.selected, m.op = -1, opNone
+	if soln, ok := m.game.solution(); ok {
+		return m.finish(soln, false)
+	}
+	return m
+}
+
+// finish ends the game, scoring answer and finding the best solutions to reveal.
+func (m playModel) finish(answer expression, timeUp bool) playModel {
+	m.selected, m.op = -1, opNone
+	m.result = &playResult{
+		answer: answer,
+		score:  score(m.game.puzzle.Target, answer.Val),
+		timeUp: timeUp,
+		best:   bestSolutions(m.game.puzzle, maxRevealed),
+	}
+	return m
+}
+
+// format writes e as it's shown to the player, e.g. "9×7 + 25 + 5".
+func (m playModel) format(e expression) string {
+	return formatInfix(e.withSubtraction(), m.sym)
+}
+
+func (m playModel) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Make %d from %s\n", m.game.puzzle.Target, formatDigits(m.game.puzzle.Digits))
+	if !m.deadline.IsZero() && m.result == nil {
+		fmt.Fprintf(&b, "Time left: %s\n", m.deadline.Sub(m.now).Round(time.Second))
+	}
+	b.WriteString("\n")
+
+	for i, n := range m.game.numbers {
+		s := fmt.Sprintf(" %d ", n.Val)
+		switch {
+		case m.result != nil:
+		case i == m.cursor:
+			s = fmt.Sprintf("[%d]", n.Val)
+		case i == m.selected:
+			s = fmt.Sprintf("<%d>", n.Val)
+		}
+		b.WriteString(s)
+		b.WriteString(" ")
+	}
+	b.WriteString("\n")
+	if m.selected >= 0 {
+		op := "?"
+		if m.op != opNone {
+			op = m.sym[m.op]
+		}
+		fmt.Fprintf(&b, "\n%d %s ?\n", m.game.numbers[m.selected].Val, op)
+	}
+
+	if len(m.game.steps) > 0 {
+		b.WriteString("\n")
+		for _, s := range m.game.steps {
+			fmt.Fprintf(&b, "  %s\n", s.format(m.sym))
+		}
+	}
+	if m.message != "" {
+		fmt.Fprintf(&b, "\n%s\n", m.message)
+	}
+
+	if r := m.result; r != nil {
+		b.WriteString("\n")
+		if r.timeUp {
+			b.WriteString("Time's up!\n")
+		}
+		if d := abs(r.answer.Val - m.game.puzzle.Target); d == 0 {
+			fmt.Fprintf(&b, "Solved! %d = %s, for %d points\n", r.answer.Val, m.format(r.answer), r.score)
+		} else {
+			fmt.Fprintf(&b, "Your answer %d = %s is %d away, for %d points\n", r.answer.Val, m.format(r.answer), d, r.score)
+		}
+		if len(r.best) > 0 {
+			b.WriteString("\nBest solutions:\n")
+			for _, e := range r.best {
+				fmt.Fprintf(&b, "  %d = %s\n", e.Val, m.format(e))
+			}
+		}
+		b.WriteString("\nPress q to quit.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\n%s\n", playHelp)
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/go-cmp/cmp"
+)
+
+func pressKeys(m playModel, keys ...string) playModel {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "left":
+			msg = tea.KeyMsg{Type: tea.KeyLeft}
+		case "right":
+			msg = tea.KeyMsg{Type: tea.KeyRight}
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		next, _ := m.Update(msg)
+		m = next.(playModel)
+	}
+	return m
+}
+
+func Test_playModel(t *testing.T) {
+	m := newPlayModel(newGame(puzzle{Digits: []int{5, 7, 9, 25}, Target: 93}), opStrings, 0)
+
+	// 9 * 7, with the numbers chosen right to left.
+	m = pressKeys(m, "right", "right", "*", "left", "enter")
+	if diff := cmp.Diff([]int{5, 63, 25}, gameValues(m.game)); diff != "" {
+		t.Errorf("numbers after 9 * 7 mismatch (-want +got):\n%s", diff)
+	}
+	if m.cursor != 1 {
+		t.Errorf("cursor = %d, want 1 (on 63)", m.cursor)
+	}
+
+	m = pressKeys(m, "enter", "-", "right", "enter")
+	if !strings.Contains(m.View(), "63 - 25 = 38") {
+		t.Errorf("View() doesn't show the step 63 - 25 = 38:\n%s", m.View())
+	}
+	m = pressKeys(m, "u")
+	if diff := cmp.Diff([]int{5, 63, 25}, gameValues(m.game)); diff != "" {
+		t.Errorf("numbers after undo mismatch (-want +got):\n%s", diff)
+	}
+
+	m = pressKeys(m, "enter", "+", "right", "enter", "+", "left", "enter")
+	if m.result == nil {
+		t.Fatalf("result = nil after making the target")
+	}
+	if !strings.Contains(m.View(), "Solved! 93 = 9*7 + 25 + 5, for 10 points") {
+		t.Errorf("View() doesn't show the solution:\n%s", m.View())
+	}
+}
+
+func Test_playModel_timed(t *testing.T) {
+	m := newPlayModel(newGame(puzzle{Digits: []int{5, 7, 9, 25}, Target: 93}), opStrings, 30*time.Second)
+	if m.Init() == nil {
+		t.Fatalf("Init() = nil, want a clock tick")
+	}
+
+	// 63 + 25 = 88, 5 away from the target.
+	m = pressKeys(m, "right", "enter", "*", "right", "enter", "enter", "+", "right", "enter")
+	next, cmd := m.Update(playTickMsg(m.now.Add(10 * time.Second)))
+	m = next.(playModel)
+	if m.result != nil || cmd == nil {
+		t.Fatalf("game ended before the deadline")
+	}
+	if !strings.Contains(m.View(), "Time left: 20s") {
+		t.Errorf("View() doesn't show the time left:\n%s", m.View())
+	}
+
+	next, _ = m.Update(playTickMsg(m.deadline))
+	m = next.(playModel)
+	if m.result == nil {
+		t.Fatalf("result = nil after the deadline")
+	}
+	for _, want := range []string{"Time's up!", "Your answer 88 = 7*9 + 25 is 5 away, for 7 points", "93 = 9*7 + 25 + 5"} {
+		if !strings.Contains(m.View(), want) {
+			t.Errorf("View() doesn't contain %q:\n%s", want, m.View())
+		}
+	}
+}
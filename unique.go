@@ -0,0 +1,28 @@
+package main
+
+import (
+	"math/rand"
+)
+
+// uniqueTarget is a target with only one distinct solution, and that solution.
+type uniqueTarget struct {
+	Target   int
+	Solution expression
+}
+
+// uniqueTargets returns the targets from from to to with exactly one distinct solution, as c counts them,
+// so solutions which only reorder or regroup sums and products, or swap copies of a repeated digit, are the same.
+func (c *solutionCounts) uniqueTargets(from, to int) []uniqueTarget {
+	// Drawing from a single solution always gives it, so the seed doesn't matter.
+	rng := rand.New(rand.NewSource(1))
+	var out []uniqueTarget
+	for t := from; t <= to; t++ {
+		if c.totals[t] != 1 {
+			continue
+		}
+		if solns := c.sample(rng, t, 1); len(solns) == 1 {
+			out = append(out, uniqueTarget{Target: t, Solution: solns[0]})
+		}
+	}
+	return out
+}
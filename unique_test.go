@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_solutionCounts_uniqueTargets(t *testing.T) {
+	digits := []int{2, 3, 7}
+	p := newPrinter(notationInfix, false)
+	var got []string
+	for _, u := range countSolutions(nil, digits).uniqueTargets(10, 16) {
+		got = append(got, p.format(u.Solution))
+		// solve lists each distinct solution once, so it should find just this one.
+		if solns := solve(u.Target, digits); len(solns) != 1 {
+			t.Errorf("uniqueTargets() included %d, which has %d solutions", u.Target, len(solns))
+		}
+	}
+	want := []string{"7 + 3", "7*2 - 3", "7 + 3 + 2", "7 + 3*2", "7*2", "(7 - 2)*3"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("uniqueTargets() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import "fmt"
+
+// checkDigits reports an error if e uses a number which isn't one of digits,
+// or uses a digit more often than it appears in digits.
+func checkDigits(e expression, digits []int) error {
+	if errs := digitErrors(e, digits); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// digitErrors returns an error for each number e uses which isn't one of digits, or is used more often than
+// it appears in digits, in the order they're first used.
+func digitErrors(e expression, digits []int) []error {
+	available := make(map[int]int)
+	for _, d := range digits {
+		available[d]++
+	}
+	used := make(map[int]int)
+	for _, d := range e.digits() {
+		used[d]++
+	}
+
+	var errs []error
+	reported := make(map[int]bool)
+	for _, d := range e.digits() {
+		if reported[d] {
+			continue
+		}
+		reported[d] = true
+		switch {
+		case available[d] == 0:
+			errs = append(errs, fmt.Errorf("%d is not one of the digits", d))
+		case used[d] > available[d] && available[d] == 1:
+			errs = append(errs, fmt.Errorf("digit %d used %s", d, times(used[d])))
+		case used[d] > available[d]:
+			errs = append(errs, fmt.Errorf("digit %d used %s but only given %s", d, times(used[d]), times(available[d])))
+		}
+	}
+	return errs
+}
+
+// times writes n as "once", "twice" or "n times".
+func times(n int) string {
+	switch n {
+	case 1:
+		return "once"
+	case 2:
+		return "twice"
+	}
+	return fmt.Sprintf("%d times", n)
+}
+
+// checkSteps reports an error for the first step of e which doesn't give a positive whole number,
+// numbering the steps in the order they'd be worked out.
+func checkSteps(e expression) error {
+	n := 0
+	var walk func(e expression) (int, error)
+	walk = func(e expression) (int, error) {
+		if e.Op == opNone {
+			return e.Val, nil
+		}
+		if e.Op == opNegate {
+			v, err := walk(*e.Children[0])
+			if err != nil {
+				return 0, err
+			}
+			n++
+			return 0, fmt.Errorf("negation at step %d: only subtraction is allowed, so -%d can't be made", n, v)
+		}
+
+		acc, err := walk(*e.Children[0])
+		if err != nil {
+			return 0, err
+		}
+		for _, c := range e.Children[1:] {
+			v, err := walk(*c)
+			if err != nil {
+				return 0, err
+			}
+			n++
+			switch {
+			case e.Op == opDivide && v == 0:
+				return 0, fmt.Errorf("division by zero at step %d: %d / %d", n, acc, v)
+			case e.Op == opDivide && acc%v != 0:
+				return 0, fmt.Errorf("non-integer division at step %d: %d / %d", n, acc, v)
+			case e.Op == opSubtract && acc <= v:
+				return 0, fmt.Errorf("non-positive result at step %d: %d - %d = %d", n, acc, v, acc-v)
+			}
+			acc, _ = e.Op.evalBinary(acc, v)
+		}
+		return acc, nil
+	}
+	_, err := walk(e)
+	return err
+}
+
+// verifyAnswer parses an answer written like "(9*7) + 25 + 5" and reports an error if it isn't
+// a legal way to make target from digits.
+func verifyAnswer(s string, digits []int, target int) (expression, error) {
+	e, err := parseInfix(s)
+	if err != nil {
+		return expression{}, err
+	}
+	if err := checkDigits(e, digits); err != nil {
+		return expression{}, err
+	}
+	if err := checkSteps(e); err != nil {
+		return expression{}, err
+	}
+	if e.Val != target {
+		return e, fmt.Errorf("%s = %d, not %d", formatInfix(e, opStrings), e.Val, target)
+	}
+	return e, nil
+}
+
+// answerViolations returns every rule e breaks as an answer making target from digits, which is none if it's
+// correct: each digit it misuses, its first step which doesn't give a positive whole number, and a wrong value.
+func answerViolations(e expression, digits []int, target int) []string {
+	var violations []string
+	for _, err := range digitErrors(e, digits) {
+		violations = append(violations, err.Error())
+	}
+	if err := checkSteps(e); err != nil {
+		violations = append(violations, err.Error())
+	}
+	if v, ok := e.eval(); ok && v != target {
+		violations = append(violations, fmt.Sprintf("%s = %d, not %d", formatInfix(e, opStrings), v, target))
+	}
+	return violations
+}
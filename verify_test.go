@@ -0,0 +1,176 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_checkDigits(t *testing.T) {
+	digits := []int{5, 7, 9, 10, 15, 25, 5}
+	tests := map[string]struct {
+		input string
+		want  string
+	}{
+		"valid": {
+			input: "9*7 + 25 + 5",
+		},
+		"repeated digit given twice": {
+			input: "5*5 + 10",
+		},
+		"not a digit": {
+			input: "9*8",
+			want:  "8 is not one of the digits",
+		},
+		"used twice": {
+			input: "9*9 + 7",
+			want:  "digit 9 used twice",
+		},
+		"used more than given": {
+			input: "5*5*5",
+			want:  "digit 5 used 3 times but only given twice",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			e, err := parseInfix(tt.input)
+			if err != nil {
+				t.Fatalf("parseInfix(%q) failed unexpectedly: %v", tt.input, err)
+			}
+			got := ""
+			if err := checkDigits(e, digits); err != nil {
+				got = err.Error()
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("checkDigits(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_checkSteps(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		want  string
+	}{
+		"valid": {
+			input: "(9*7) + 25 + 5",
+		},
+		"exact division": {
+			input: "(25 + 10)/7",
+		},
+		"non-integer division": {
+			input: "9*7 + 25/10",
+			want:  "non-integer division at step 2: 25 / 10",
+		},
+		"zero result": {
+			input: "9/(5 - 5)",
+			want:  "non-positive result at step 1: 5 - 5 = 0",
+		},
+		"negative subtraction": {
+			input: "25 + (5 - 9)",
+			want:  "non-positive result at step 1: 5 - 9 = -4",
+		},
+		"negation": {
+			input: "25 + -9",
+			want:  "negation at step 1: only subtraction is allowed, so -9 can't be made",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			e, err := parseInfix(tt.input)
+			if err != nil {
+				t.Fatalf("parseInfix(%q) failed unexpectedly: %v", tt.input, err)
+			}
+			got := ""
+			if err := checkSteps(e); err != nil {
+				got = err.Error()
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("checkSteps(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_verifyAnswer(t *testing.T) {
+	digits := []int{5, 7, 9, 25}
+	tests := map[string]struct {
+		input string
+		want  string
+	}{
+		"correct": {
+			input: "(9*7)+25+5",
+		},
+		"wrong value": {
+			input: "9*7 + 25",
+			want:  "9*7 + 25 = 88, not 93",
+		},
+		"digit reused": {
+			input: "9*9 + 7 + 5",
+			want:  "digit 9 used twice",
+		},
+		"illegal step": {
+			input: "(25 + 5)/7 + 9",
+			want:  "non-integer division at step 2: 30 / 7",
+		},
+		"syntax error": {
+			input: "9*7 +",
+			want:  "unexpected end of expression",
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			got := ""
+			if _, err := verifyAnswer(tt.input, digits, 93); err != nil {
+				got = err.Error()
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("verifyAnswer(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_answerViolations(t *testing.T) {
+	digits := []int{5, 7, 9, 25}
+	tests := map[string]struct {
+		input string
+		want  []string
+	}{
+		"correct": {
+			input: "(9*7)+25+5",
+		},
+		"wrong value": {
+			input: "9*7 + 25",
+			want:  []string{"9*7 + 25 = 88, not 93"},
+		},
+		"several": {
+			input: "9*9 + 8 + (5 - 7)",
+			want: []string{
+				"8 is not one of the digits",
+				"digit 9 used twice",
+				"non-positive result at step 3: 5 - 7 = -2",
+			},
+		},
+		"division by zero": {
+			input: "25/(7 - 7)",
+			want: []string{
+				"digit 7 used twice",
+				"non-positive result at step 1: 7 - 7 = 0",
+			},
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			e, err := parseInfix(tt.input)
+			if err != nil {
+				t.Fatalf("parseInfix(%q) failed unexpectedly: %v", tt.input, err)
+			}
+			got := answerViolations(e, digits, 93)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("answerViolations(%q) mismatch (-want +got):\n%s", tt.input, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// webFiles is the web UI serve offers at /, a page for solving puzzles and playing them with hints,
+// which uses the HTTP API.
+//
+//go:embed web
+var webFiles embed.FS
+
+func webHandler() http.Handler {
+	root, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		panic(err)
+	}
+	return http.FileServer(http.FS(root))
+}
@@ -0,0 +1,146 @@
+"use strict";
+
+// The page talks to the server's HTTP API: /v1/generate and /v1/verify for playing, and /v1/solve for solving.
+
+const $ = (id) => document.getElementById(id);
+
+async function post(path, body) {
+  const resp = await fetch(path, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(body),
+  });
+  const data = await resp.json();
+  if (!resp.ok) {
+    throw new Error(data.error || resp.statusText);
+  }
+  return data;
+}
+
+function showError(err) {
+  const el = $("error");
+  el.textContent = err ? err.message : "";
+  el.hidden = !err;
+}
+
+function item(text, steps) {
+  const li = document.createElement("li");
+  li.textContent = text;
+  if (steps && steps.length > 0) {
+    const p = document.createElement("p");
+    p.className = "steps";
+    p.textContent = steps.join("; ");
+    li.appendChild(p);
+  }
+  return li;
+}
+
+// Tabs.
+
+for (const tab of document.querySelectorAll(".tab")) {
+  tab.addEventListener("click", () => {
+    for (const other of document.querySelectorAll(".tab")) {
+      const selected = other === tab;
+      other.setAttribute("aria-selected", selected);
+      $(other.dataset.tab).hidden = !selected;
+    }
+    showError(null);
+  });
+}
+
+// Playing.
+
+let puzzle = null;
+let hintsShown = 0;
+
+$("new-puzzle").addEventListener("submit", async (ev) => {
+  ev.preventDefault();
+  showError(null);
+  const difficulty = Number(ev.target.difficulty.value);
+  try {
+    puzzle = await post("/v1/generate", difficulty > 0 ? { difficulty } : {});
+  } catch (err) {
+    showError(err);
+    return;
+  }
+  hintsShown = 0;
+  $("puzzle-target").textContent = puzzle.target;
+  $("puzzle-digits").replaceChildren(
+    ...puzzle.digits.map((d) => {
+      const span = document.createElement("span");
+      span.textContent = d;
+      return span;
+    }),
+  );
+  $("hints").replaceChildren();
+  $("verdict").textContent = "";
+  $("answer").answer.value = "";
+  $("puzzle").hidden = false;
+  $("answer").answer.focus();
+});
+
+$("answer").addEventListener("submit", async (ev) => {
+  ev.preventDefault();
+  showError(null);
+  const verdict = $("verdict");
+  try {
+    const result = await post("/v1/verify", {
+      digits: puzzle.digits,
+      target: puzzle.target,
+      answer: ev.target.answer.value,
+    });
+    verdict.className = result.valid ? "correct" : "wrong";
+    verdict.textContent = result.valid ? "Correct!" : result.violations.join("; ");
+  } catch (err) {
+    showError(err);
+  }
+});
+
+$("hint").addEventListener("click", () => {
+  if (puzzle && hintsShown < puzzle.hints.length) {
+    $("hints").appendChild(item(puzzle.hints[hintsShown++]));
+  }
+});
+
+$("reveal").addEventListener("click", () => {
+  if (puzzle) {
+    const verdict = $("verdict");
+    verdict.className = "";
+    verdict.textContent = `${puzzle.target} = ${puzzle.solution.text}`;
+  }
+});
+
+// Solving.
+
+// maxShown is the most solutions listed, as there can be thousands.
+const maxShown = 100;
+
+$("solve-form").addEventListener("submit", async (ev) => {
+  ev.preventDefault();
+  showError(null);
+  const form = ev.target;
+  const status = $("solve-status");
+  const list = $("solutions");
+  list.replaceChildren();
+  status.textContent = "Solving…";
+  try {
+    const result = await post("/v1/solve", {
+      digits: form.digits.value.split(/[\s,]+/).filter((s) => s !== "").map(Number),
+      target: Number(form.target.value),
+      sort: "ops",
+      no_division: form.no_division.checked,
+    });
+    let summary = result.solved ? `${result.count} solutions, fewest operations first` : "No solutions";
+    if (result.count > maxShown) {
+      summary += `, showing the first ${maxShown}`;
+    }
+    if (result.incomplete) {
+      summary += " (the search stopped early, so there may be more)";
+    }
+    status.textContent = summary;
+    list.replaceChildren(...result.solutions.slice(0, maxShown).map((s) => item(s.text, s.steps)));
+  } catch (err) {
+    status.textContent = "";
+    showError(err);
+  }
+});
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_webHandler(t *testing.T) {
+	srv := &server{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	ts := httptest.NewServer(srv.handler())
+	defer ts.Close()
+
+	tests := map[string]struct {
+		path        string
+		wantStatus  int
+		wantType    string
+		wantContent string
+	}{
+		"page": {
+			path:        "/",
+			wantStatus:  http.StatusOK,
+			wantType:    "text/html",
+			wantContent: "<title>digits</title>",
+		},
+		"script": {
+			path:        "/app.js",
+			wantStatus:  http.StatusOK,
+			wantType:    "text/javascript",
+			wantContent: `post("/v1/generate"`,
+		},
+		"style": {
+			path:       "/style.css",
+			wantStatus: http.StatusOK,
+			wantType:   "text/css",
+		},
+		"missing": {
+			path:       "/nope.html",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			resp, err := http.Get(ts.URL + tt.path)
+			if err != nil {
+				t.Fatalf("Get() failed unexpectedly: %v", err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("ReadAll() failed unexpectedly: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, tt.wantType) {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if !strings.Contains(string(body), tt.wantContent) {
+				t.Errorf("body doesn't contain %q", tt.wantContent)
+			}
+		})
+	}
+}
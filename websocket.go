@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// streamProgressInterval is how often a live solve reports its progress.
+const streamProgressInterval = 250 * time.Millisecond
+
+// streamRequest is the first message a client sends on /v1/solve/stream. It's like solveRequest, without the
+// engine and sort, as the solutions are sent in the order the search finds them.
+type streamRequest struct {
+	Digits     []int  `json:"digits"`
+	Target     int    `json:"target"`
+	Notation   string `json:"notation,omitempty"`
+	Unicode    bool   `json:"unicode,omitempty"`
+	MaxResults int    `json:"max_results,omitempty"`
+	// The filters are like solve's flags of the same names.
+	NoDivision    bool `json:"no_division,omitempty"`
+	NoNegation    bool `json:"no_negation,omitempty"`
+	NoTrivial     bool `json:"no_trivial,omitempty"`
+	MaxOps        int  `json:"max_ops,omitempty"`
+	MaxDigitsUsed int  `json:"max_digits_used,omitempty"`
+}
+
+// streamCommand is a message a client sends during a live solve. The only action is "cancel", which stops
+// the search; closing the connection does too.
+type streamCommand struct {
+	Action string `json:"action"`
+}
+
+// streamEvent is a message the server sends during a live solve. A solve sends any number of solution and
+// progress events, then one done event, or an error event if the request was invalid.
+type streamEvent struct {
+	// Event is "solution", "progress", "done" or "error".
+	Event    string        `json:"event"`
+	Solution *solutionJSON `json:"solution,omitempty"`
+	// Solutions, Nodes and ElapsedMS say how far the search has got, for progress and done events.
+	Solutions int   `json:"solutions"`
+	Nodes     int64 `json:"nodes"`
+	ElapsedMS int64 `json:"elapsed_ms"`
+	// Incomplete reports whether the search stopped early, for done events, and Reason why: "cancelled",
+	// "timeout" or "max_nodes".
+	Incomplete bool   `json:"incomplete,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+var upgrader = websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 4096}
+
+// handleSolveStream solves a puzzle over a WebSocket, sending each solution as it's found, so a long search
+// neither times out nor keeps a client waiting for the lot.
+func (srv *server) handleSolveStream(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already replied with the error.
+		srv.log.Warn("Failed to start a live solve", "error", err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(maxRequestBytes)
+
+	var req streamRequest
+	if err := readStreamRequest(conn, &req); err != nil {
+		srv.writeEvent(conn, streamEvent{Event: "error", Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+	s, p, pz, err := srv.streamSolver(req)
+	if err != nil {
+		srv.writeEvent(conn, streamEvent{Event: "error", Error: err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		// Any message which isn't a command, or the connection closing, cancels the search too.
+		defer cancel()
+		for {
+			var cmd streamCommand
+			if err := conn.ReadJSON(&cmd); err != nil || cmd.Action == "cancel" {
+				return
+			}
+			srv.log.Warn("Ignoring unknown live solve action", "action", cmd.Action)
+		}
+	}()
+
+	var nodes atomic.Int64
+	s.ctx, s.nodes = ctx, &nodes
+	solns := make(chan expression)
+	done := make(chan bool)
+	go func() {
+		done <- s.streamSolutionsWithin(srv.timeout, pz.Target, pz.Digits, newRecentSet(serverStreamMemory), func(e expression) bool {
+			select {
+			case solns <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	start := time.Now()
+	ticker := time.NewTicker(streamProgressInterval)
+	defer ticker.Stop()
+	progress := func(event string) streamEvent {
+		return streamEvent{Event: event, Nodes: nodes.Load(), ElapsedMS: time.Since(start).Milliseconds()}
+	}
+	sent, full := 0, false
+	// ok is cleared when a write fails, after which the search is cancelled, and its solutions drained.
+	ok := true
+	for {
+		select {
+		case e := <-solns:
+			if !ok || full {
+				continue
+			}
+			sent++
+			ev := progress("solution")
+			ev.Solution, ev.Solutions = makeSolutionJSON(makeSolution(e, p), p), sent
+			if ok = srv.writeEvent(conn, ev); !ok {
+				cancel()
+			}
+			if req.MaxResults > 0 && sent >= req.MaxResults {
+				full = true
+				cancel()
+			}
+		case <-ticker.C:
+			ev := progress("progress")
+			ev.Solutions = sent
+			if ok && !srv.writeEvent(conn, ev) {
+				ok = false
+				cancel()
+			}
+		case stopped := <-done:
+			ev := progress("done")
+			ev.Solutions = sent
+			switch {
+			case full:
+			case ctx.Err() != nil:
+				ev.Incomplete, ev.Reason = true, "cancelled"
+			case s.budget.exhausted():
+				ev.Incomplete, ev.Reason = true, "max_nodes"
+			case stopped:
+				ev.Incomplete, ev.Reason = true, "timeout"
+			}
+			if ok {
+				srv.writeEvent(conn, ev)
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			}
+			srv.log.Info("Solved live", "digits", formatDigits(pz.Digits), "target", pz.Target, "solutions", sent, "reason", ev.Reason)
+			return
+		}
+	}
+}
+
+// readStreamRequest reads the first message of a live solve into req, rejecting unknown fields like readJSON.
+func readStreamRequest(conn *websocket.Conn, req *streamRequest) error {
+	_, r, err := conn.NextReader()
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	return dec.Decode(req)
+}
+
+// streamSolver checks req, and returns a solver for it with the server's limits, and the printer for its solutions.
+func (srv *server) streamSolver(req streamRequest) (solver, printer, puzzle, error) {
+	pz, err := checkPuzzle(req.Digits, req.Target)
+	if err != nil {
+		return solver{}, printer{}, puzzle{}, err
+	}
+	if req.MaxResults < 0 {
+		return solver{}, printer{}, puzzle{}, fmt.Errorf("max_results must not be negative, got %d", req.MaxResults)
+	}
+	filter := solutionFilter{noDivision: req.NoDivision, noNegation: req.NoNegation, noTrivial: req.NoTrivial, maxOps: req.MaxOps, maxDigits: req.MaxDigitsUsed}
+	if err := filter.validate(); err != nil {
+		return solver{}, printer{}, puzzle{}, err
+	}
+	n, err := parseNotation(withDefault(req.Notation, "infix"))
+	if err != nil {
+		return solver{}, printer{}, puzzle{}, err
+	}
+	s := solver{filter: filter, log: srv.log, budget: newNodeBudget(srv.maxNodes)}
+	return s, newPrinter(n, req.Unicode), pz, nil
+}
+
+// writeEvent sends ev, and reports whether it could.
+func (srv *server) writeEvent(conn *websocket.Conn, ev streamEvent) bool {
+	if err := conn.WriteJSON(ev); err != nil {
+		srv.log.Warn("Failed to send a live solve event", "event", ev.Event, "error", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/websocket"
+)
+
+// dialSolveStream connects to a live solve on srv, and sends req.
+func dialSolveStream(t *testing.T, srv *server, req string) *websocket.Conn {
+	ts := httptest.NewServer(srv.handler())
+	t.Cleanup(ts.Close)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/v1/solve/stream", nil)
+	if err != nil {
+		t.Fatalf("Dial() failed unexpectedly: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(req)); err != nil {
+		t.Fatalf("WriteMessage() failed unexpectedly: %v", err)
+	}
+	return conn
+}
+
+// readEvents reads events until the done or error event, calling fn with each.
+func readEvents(t *testing.T, conn *websocket.Conn, fn func(streamEvent)) streamEvent {
+	for {
+		var ev streamEvent
+		if err := conn.ReadJSON(&ev); err != nil {
+			t.Fatalf("ReadJSON() failed unexpectedly: %v", err)
+		}
+		if ev.Event == "done" || ev.Event == "error" {
+			return ev
+		}
+		fn(ev)
+	}
+}
+
+func Test_server_solveStream(t *testing.T) {
+	srv := &server{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	tests := map[string]struct {
+		req       string
+		wantTexts []string
+		want      streamEvent
+	}{
+		"solved": {
+			req:       `{"digits": [2, 3, 7], "target": 1, "notation": "rpn", "no_division": true}`,
+			wantTexts: []string{"3 2 -", "7 3 2 * -"},
+			want:      streamEvent{Event: "done", Solutions: 2},
+		},
+		"max results": {
+			req:       `{"digits": [2, 3, 7], "target": 1, "no_division": true, "max_results": 1}`,
+			wantTexts: []string{"3 - 2"},
+			want:      streamEvent{Event: "done", Solutions: 1},
+		},
+		"invalid target": {
+			req:  `{"digits": [2, 3], "target": 0}`,
+			want: streamEvent{Event: "error", Error: "target must be positive, got 0"},
+		},
+		"unknown field": {
+			req:  `{"digit": [2, 3], "target": 5}`,
+			want: streamEvent{Event: "error", Error: `invalid request: json: unknown field "digit"`},
+		},
+	}
+	for tn, tt := range tests {
+		t.Run(tn, func(t *testing.T) {
+			conn := dialSolveStream(t, srv, tt.req)
+			var texts []string
+			got := readEvents(t, conn, func(ev streamEvent) {
+				if ev.Event == "solution" {
+					texts = append(texts, ev.Solution.Text)
+				}
+			})
+			if diff := cmp.Diff(tt.wantTexts, texts); diff != "" {
+				t.Errorf("solutions mismatch (-want +got):\n%s", diff)
+			}
+			got.Nodes, got.ElapsedMS = 0, 0
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("last event mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_server_solveStream_cancel(t *testing.T) {
+	srv := &server{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	conn := dialSolveStream(t, srv, `{"digits": [1, 2, 3, 4, 5, 6, 7, 8, 9, 10], "target": 999}`)
+	cancelled := false
+	got := readEvents(t, conn, func(ev streamEvent) {
+		if !cancelled {
+			cancelled = true
+			if err := conn.WriteJSON(streamCommand{Action: "cancel"}); err != nil {
+				t.Fatalf("WriteJSON() failed unexpectedly: %v", err)
+			}
+		}
+	})
+	if !cancelled || !got.Incomplete || got.Reason != "cancelled" {
+		t.Errorf("last event = %+v, want one saying the search was cancelled", got)
+	}
+}
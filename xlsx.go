@@ -0,0 +1,185 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// digitSetResults holds the results for several targets solved with the same digits.
+type digitSetResults struct {
+	Digits  []int
+	Results []targetResult
+}
+
+// digitSetGrouper collects results into a digitSetResults for each set of digits, in the order the sets are
+// first seen. Digits in any order are the same set, named by the order they were first given in.
+type digitSetGrouper struct {
+	sets  []digitSetResults
+	index map[string]int
+}
+
+func (g *digitSetGrouper) add(digits []int, r targetResult) {
+	sorted := slices.Clone(digits)
+	sort.Ints(sorted)
+	key := formatDigits(sorted)
+	i, ok := g.index[key]
+	if !ok {
+		if g.index == nil {
+			g.index = make(map[string]int)
+		}
+		i = len(g.sets)
+		g.index[key] = i
+		g.sets = append(g.sets, digitSetResults{Digits: digits})
+	}
+	g.sets[i].Results = append(g.sets[i].Results, r)
+}
+
+// maxSheetNameLen is the longest worksheet name Excel accepts.
+const maxSheetNameLen = 31
+
+// writeXLSX writes an Excel workbook with one worksheet per digit set and columns for
+// the target, the number of solutions and the shortest solution.
+// Only the handful of parts required by the format are written, with strings stored inline.
+func writeXLSX(w io.Writer, sets []digitSetResults, p printer) error {
+	names := sheetNames(sets)
+
+	type part struct {
+		name    string
+		content string
+	}
+	parts := []part{
+		{"[Content_Types].xml", xlsxContentTypes(len(sets))},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook(names)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels(len(sets))},
+	}
+	for i, set := range sets {
+		parts = append(parts, part{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), xlsxSheet(set, p)})
+	}
+
+	zw := zip.NewWriter(w)
+	for _, f := range parts {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// saveXLSX writes the workbook for sets to path, replacing it atomically.
+func saveXLSX(path string, sets []digitSetResults, p printer) error {
+	var b bytes.Buffer
+	if err := writeXLSX(&b, sets, p); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, b.Bytes())
+}
+
+// sheetNames names each worksheet after its digits, keeping names unique and within Excel's limits.
+func sheetNames(sets []digitSetResults) []string {
+	seen := make(map[string]bool)
+	names := make([]string, len(sets))
+	for i, set := range sets {
+		base := formatDigits(set.Digits)
+		if len(base) > maxSheetNameLen {
+			base = base[:maxSheetNameLen]
+		}
+		name := base
+		for n := 2; seen[name]; n++ {
+			suffix := fmt.Sprintf(" (%d)", n)
+			name = base
+			if len(name)+len(suffix) > maxSheetNameLen {
+				name = name[:maxSheetNameLen-len(suffix)]
+			}
+			name += suffix
+		}
+		seen[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func xlsxContentTypes(sheets int) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+func xlsxWorkbook(names []string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, name := range names {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+func xlsxWorkbookRels(sheets int) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+func xlsxSheet(set digitSetResults, p printer) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	b.WriteString(`<row r="1">`)
+	b.WriteString(xlsxStringCell("A1", "Target"))
+	b.WriteString(xlsxStringCell("B1", "Solutions"))
+	b.WriteString(xlsxStringCell("C1", "Shortest solution"))
+	b.WriteString(`</row>`)
+
+	for i, r := range set.Results {
+		row := i + 2
+		fmt.Fprintf(&b, `<row r="%d">`, row)
+		fmt.Fprintf(&b, `<c r="A%d"><v>%d</v></c>`, row, r.Target)
+		fmt.Fprintf(&b, `<c r="B%d"><v>%d</v></c>`, row, len(r.Solutions))
+		if s, err := shortest(r.Solutions); err == nil {
+			b.WriteString(xlsxStringCell(fmt.Sprintf("C%d", row), p.format(s)))
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+func xlsxStringCell(ref, s string) string {
+	return fmt.Sprintf(`<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, xmlEscape(s))
+}
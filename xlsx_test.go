@@ -0,0 +1,132 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_writeXLSX(t *testing.T) {
+	digits := []int{2, 3, 4}
+	sets := []digitSetResults{
+		{Digits: digits, Results: []targetResult{{Target: 5, Solutions: solve(5, digits)}, {Target: 23}}},
+		{Digits: digits, Results: []targetResult{{Target: 24, Solutions: solve(24, digits)}}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeXLSX(&buf, sets, newPrinter(notationInfix, false)); err != nil {
+		t.Fatalf("writeXLSX() failed unexpectedly: %v", err)
+	}
+
+	names, files := readZipFiles(t, buf.Bytes())
+	wantNames := []string{
+		"[Content_Types].xml",
+		"_rels/.rels",
+		"xl/workbook.xml",
+		"xl/_rels/workbook.xml.rels",
+		"xl/worksheets/sheet1.xml",
+		"xl/worksheets/sheet2.xml",
+	}
+	if diff := cmp.Diff(wantNames, names); diff != "" {
+		t.Errorf("writeXLSX() files mismatch (-want +got):\n%s", diff)
+	}
+
+	for file, want := range map[string]string{
+		"xl/workbook.xml":          `<sheet name="2,3,4 (2)" sheetId="2" r:id="rId2"/>`,
+		"xl/worksheets/sheet1.xml": `<row r="2"><c r="A2"><v>5</v></c><c r="B2"><v>4</v></c><c r="C2" t="inlineStr"><is><t>3 + 2</t></is></c></row>`,
+	} {
+		if !strings.Contains(files[file], want) {
+			t.Errorf("%s missing %q:\n%s", file, want, files[file])
+		}
+	}
+}
+
+func Test_digitSetGrouper(t *testing.T) {
+	// Rows like those of a batch run, with the digits of the first set given in two orders.
+	rows := []struct {
+		digits []int
+		target int
+	}{
+		{[]int{2, 3, 4}, 5},
+		{[]int{5, 7}, 12},
+		{[]int{4, 3, 2}, 24},
+		{[]int{5, 7}, 35},
+	}
+	var g digitSetGrouper
+	for _, r := range rows {
+		g.add(r.digits, targetResult{Target: r.target, Solutions: solve(r.target, r.digits)})
+	}
+	var buf bytes.Buffer
+	if err := writeXLSX(&buf, g.sets, newPrinter(notationInfix, false)); err != nil {
+		t.Fatalf("writeXLSX() failed unexpectedly: %v", err)
+	}
+
+	// Reopen the workbook, and read each sheet's name and targets back.
+	_, files := readZipFiles(t, buf.Bytes())
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal([]byte(files["xl/workbook.xml"]), &workbook); err != nil {
+		t.Fatalf("xl/workbook.xml isn't valid XML: %v", err)
+	}
+	got := make(map[string][]string)
+	for i, sheet := range workbook.Sheets {
+		var ws struct {
+			Rows []struct {
+				Cells []struct {
+					Value string `xml:"v"`
+				} `xml:"c"`
+			} `xml:"sheetData>row"`
+		}
+		path := fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1)
+		if err := xml.Unmarshal([]byte(files[path]), &ws); err != nil {
+			t.Fatalf("%s isn't valid XML: %v", path, err)
+		}
+		targets := []string{}
+		// The first row is the header.
+		for _, row := range ws.Rows[1:] {
+			targets = append(targets, row.Cells[0].Value)
+		}
+		got[sheet.Name] = targets
+	}
+	want := map[string][]string{
+		"2,3,4": {"5", "24"},
+		"5,7":   {"12", "35"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("workbook sheets mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// readZipFiles returns the names of the files in the zip archive data, in order, and their contents by name.
+func readZipFiles(t *testing.T, data []byte) ([]string, map[string]string) {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader() failed unexpectedly: %v", err)
+	}
+	var names []string
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Open(%q) failed unexpectedly: %v", f.Name, err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("ReadAll(%q) failed unexpectedly: %v", f.Name, err)
+		}
+		names = append(names, f.Name)
+		files[f.Name] = string(b)
+	}
+	return names, files
+}